@@ -0,0 +1,743 @@
+// Package file provides a broker persisting topics to local disk
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/logger"
+)
+
+var (
+	// DefaultDir is the directory the topics are stored in
+	DefaultDir = filepath.Join(os.TempDir(), "micro", "broker")
+	// DefaultSegmentSize is the number of messages in each segment of a topic
+	DefaultSegmentSize = 10000
+	// DefaultAckWait is how long a message is waited on to be acked before it's delivered again
+	DefaultAckWait = time.Second * 30
+)
+
+// fileBroker stores each topic as an append only log on disk. Queue groups
+// keep the offset they've consumed up to so messages published while their
+// subscribers are down are delivered once they're back. Subscribers without
+// a queue get the messages published while they're subscribed.
+type fileBroker struct {
+	opts broker.Options
+
+	dir       string
+	size      uint64
+	retention time.Duration
+
+	sync.RWMutex
+	connected bool
+	topics    map[string]*topic
+	exit      chan bool
+}
+
+// topic is a log and the groups consuming it
+type topic struct {
+	name string
+	log  *topicLog
+
+	sync.Mutex
+	groups map[string]*group
+}
+
+// group is a queue group or a lone subscriber. The messages are
+// shared between the members and redelivered if not acked in time.
+type group struct {
+	name    string
+	durable bool
+	topic   *topic
+	ackWait time.Duration
+
+	sync.Mutex
+	// the next offset to deliver
+	next uint64
+	// every message up to the offset has been acked
+	committed uint64
+	// messages after the committed offset which have been acked
+	acked map[uint64]bool
+	// messages waiting to be acked and when they're delivered again
+	pending map[uint64]time.Time
+	members map[string]*fileSubscriber
+
+	deliveries chan *fileEvent
+	notify     chan bool
+	exit       chan bool
+}
+
+type fileSubscriber struct {
+	id      string
+	topic   string
+	group   *group
+	handler broker.Handler
+	opts    broker.SubscribeOptions
+	broker  *fileBroker
+	exit    chan bool
+	once    sync.Once
+}
+
+type fileEvent struct {
+	topic   string
+	message *broker.Message
+	offset  uint64
+	group   *group
+	err     error
+}
+
+// directory returns the directory the topics are stored in
+func directory(opts broker.Options) string {
+	if opts.Context != nil {
+		if dir, ok := opts.Context.Value(dirKey{}).(string); ok && len(dir) > 0 {
+			return dir
+		}
+	}
+	// the broker address is the directory
+	if len(opts.Addrs) > 0 && len(opts.Addrs[0]) > 0 {
+		return opts.Addrs[0]
+	}
+	return DefaultDir
+}
+
+func (f *fileBroker) configure() {
+	f.dir = directory(f.opts)
+	f.size = uint64(DefaultSegmentSize)
+
+	if f.opts.Context == nil {
+		return
+	}
+	if n, ok := f.opts.Context.Value(segmentSizeKey{}).(int); ok && n > 0 {
+		f.size = uint64(n)
+	}
+	if d, ok := f.opts.Context.Value(retentionKey{}).(time.Duration); ok {
+		f.retention = d
+	}
+}
+
+func (f *fileBroker) Init(opts ...broker.Option) error {
+	f.Lock()
+	defer f.Unlock()
+
+	options := f.opts
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the open logs stay where they are
+	if f.connected && directory(options) != f.dir {
+		return errors.New("can't change the directory while connected")
+	}
+
+	f.opts = options
+	f.configure()
+
+	return nil
+}
+
+func (f *fileBroker) Options() broker.Options {
+	return f.opts
+}
+
+func (f *fileBroker) Address() string {
+	return f.dir
+}
+
+func (f *fileBroker) Connect() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.connected {
+		return nil
+	}
+
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return err
+	}
+
+	f.connected = true
+	f.exit = make(chan bool)
+
+	go f.expire(f.retention, f.exit)
+
+	return nil
+}
+
+func (f *fileBroker) Disconnect() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if !f.connected {
+		return nil
+	}
+
+	for _, t := range f.topics {
+		t.Lock()
+		for _, g := range t.groups {
+			g.stop()
+		}
+		t.groups = make(map[string]*group)
+		t.Unlock()
+		t.log.Close()
+	}
+
+	f.topics = make(map[string]*topic)
+	f.connected = false
+	close(f.exit)
+
+	return nil
+}
+
+// expire drops the messages past the retention from the open topics
+// until the broker is disconnected, e.g when nothing is published
+func (f *fileBroker) expire(retention time.Duration, exit chan bool) {
+	if retention <= 0 {
+		return
+	}
+
+	// check a few times per retention period
+	interval := retention / 4
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	} else if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-exit:
+			return
+		}
+
+		// the logs aren't closed while they're expired
+		f.RLock()
+		for _, t := range f.topics {
+			if err := t.log.Expire(); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("[file]: failed to expire %s: %v", t.name, err)
+			}
+		}
+		f.RUnlock()
+	}
+}
+
+// topic returns the topic opening its log if needed
+func (f *fileBroker) topic(name string) (*topic, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if !f.connected {
+		return nil, errors.New("not connected")
+	}
+
+	if t, ok := f.topics[name]; ok {
+		return t, nil
+	}
+
+	l, err := openLog(f.dir, name, f.size, f.retention)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop what expired while the topic wasn't open
+	if err := l.Expire(); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	t := &topic{
+		name:   name,
+		log:    l,
+		groups: make(map[string]*group),
+	}
+	f.topics[name] = t
+
+	return t, nil
+}
+
+func (f *fileBroker) Publish(name string, msg *broker.Message, opts ...broker.PublishOption) error {
+	t, err := f.topic(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.log.Append(msg); err != nil {
+		return err
+	}
+
+	// wake up the groups
+	t.Lock()
+	for _, g := range t.groups {
+		select {
+		case g.notify <- true:
+		default:
+		}
+	}
+	t.Unlock()
+
+	return nil
+}
+
+func (f *fileBroker) Subscribe(name string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	t, err := f.topic(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := broker.NewSubscribeOptions(opts...)
+
+	sub := &fileSubscriber{
+		id:      uuid.New().String(),
+		topic:   name,
+		handler: handler,
+		opts:    options,
+		broker:  f,
+		exit:    make(chan bool),
+	}
+
+	// lone subscribers get a group of their own which isn't kept
+	queue := options.Queue
+	if len(queue) == 0 {
+		queue = sub.id
+	}
+
+	ackWait := DefaultAckWait
+	if options.Context != nil {
+		if d, ok := options.Context.Value(ackWaitKey{}).(time.Duration); ok && d > 0 {
+			ackWait = d
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	g, ok := t.groups[queue]
+	if !ok {
+		if g, err = newGroup(t, queue, len(options.Queue) > 0, ackWait); err != nil {
+			return nil, err
+		}
+		t.groups[queue] = g
+		go g.run()
+	}
+
+	// move the group to where the topic is replayed from
+	if err := g.start(options); err != nil {
+		return nil, err
+	}
+
+	sub.group = g
+	g.Lock()
+	g.members[sub.id] = sub
+	g.Unlock()
+
+	go sub.run()
+
+	return sub, nil
+}
+
+func (f *fileBroker) String() string {
+	return "file"
+}
+
+func newGroup(t *topic, name string, durable bool, ackWait time.Duration) (*group, error) {
+	last, err := t.log.Last()
+	if err != nil {
+		return nil, err
+	}
+
+	// new groups start with the messages published from now on
+	committed := last
+	if durable {
+		offset, ok, err := t.log.Committed(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			committed = offset
+		} else if err := t.log.Commit(name, committed); err != nil {
+			return nil, err
+		}
+	}
+
+	return &group{
+		name:       name,
+		durable:    durable,
+		topic:      t,
+		ackWait:    ackWait,
+		next:       committed + 1,
+		committed:  committed,
+		acked:      make(map[uint64]bool),
+		pending:    make(map[uint64]time.Time),
+		members:    make(map[string]*fileSubscriber),
+		deliveries: make(chan *fileEvent),
+		notify:     make(chan bool, 1),
+		exit:       make(chan bool),
+	}, nil
+}
+
+// start moves the group to the offset or time to replay the topic from
+func (g *group) start(opts broker.SubscribeOptions) error {
+	if opts.Context == nil {
+		return nil
+	}
+
+	var offset uint64
+	if o, ok := opts.Context.Value(startOffsetKey{}).(uint64); ok {
+		offset = o
+	} else if t, ok := opts.Context.Value(startTimeKey{}).(time.Time); ok {
+		o, err := g.topic.log.Seek(t)
+		if err != nil {
+			return err
+		}
+		offset = o
+	} else {
+		return nil
+	}
+
+	// don't go back past the oldest message kept
+	first, err := g.topic.log.First()
+	if err != nil {
+		return err
+	}
+	if offset < first {
+		offset = first
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.next = offset
+	g.committed = offset - 1
+	g.acked = make(map[uint64]bool)
+	g.pending = make(map[uint64]time.Time)
+
+	if g.durable {
+		if err := g.topic.log.Commit(g.name, g.committed); err != nil {
+			return err
+		}
+	}
+
+	// wake up the group
+	select {
+	case g.notify <- true:
+	default:
+	}
+
+	return nil
+}
+
+// run delivers the messages of the topic to the members of the group
+func (g *group) run() {
+	// check for messages to deliver again a few times per ack wait
+	interval := g.ackWait / 4
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	} else if interval > time.Second {
+		interval = time.Second
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		for _, offset := range g.expired() {
+			if !g.deliver(offset) {
+				return
+			}
+		}
+
+		for {
+			offset, ok := g.advance()
+			if !ok {
+				break
+			}
+			if !g.deliver(offset) {
+				return
+			}
+		}
+
+		select {
+		case <-g.notify:
+		case <-t.C:
+		case <-g.exit:
+			return
+		}
+	}
+}
+
+// advance returns the next offset to deliver if there's one
+func (g *group) advance() (uint64, bool) {
+	last, err := g.topic.log.Last()
+	if err != nil {
+		return 0, false
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.next > last {
+		return 0, false
+	}
+	offset := g.next
+	g.next++
+	return offset, true
+}
+
+// expired returns the offsets of the messages which weren't acked in time
+func (g *group) expired() []uint64 {
+	g.Lock()
+	defer g.Unlock()
+
+	var offsets []uint64
+	now := time.Now()
+
+	for offset, deadline := range g.pending {
+		if deadline.After(now) {
+			continue
+		}
+		offsets = append(offsets, offset)
+		g.pending[offset] = now.Add(g.ackWait)
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
+}
+
+// deliver hands the message at the offset to a member of the group.
+// It returns false if the group was stopped.
+func (g *group) deliver(offset uint64) bool {
+	e, err := g.topic.log.Read(offset)
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("[file]: failed to read %s offset %d: %v", g.topic.name, offset, err)
+		}
+		// try again once the ack wait is over
+		g.Lock()
+		g.pending[offset] = time.Now().Add(g.ackWait)
+		g.Unlock()
+		return true
+	}
+
+	// dropped by the retention
+	if e == nil {
+		g.ack(offset)
+		return true
+	}
+
+	g.Lock()
+	if offset <= g.committed || g.acked[offset] {
+		g.Unlock()
+		return true
+	}
+	g.pending[offset] = time.Now().Add(g.ackWait)
+	g.Unlock()
+
+	ev := &fileEvent{
+		topic: g.topic.name,
+		message: &broker.Message{
+			Header: e.Header,
+			Body:   e.Body,
+		},
+		offset: offset,
+		group:  g,
+	}
+
+	select {
+	case g.deliveries <- ev:
+	case <-g.exit:
+		return false
+	}
+
+	return true
+}
+
+// received starts the ack wait once a member has the message
+func (g *group) received(offset uint64) {
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.pending[offset]; ok {
+		g.pending[offset] = time.Now().Add(g.ackWait)
+	}
+}
+
+// nack delivers the message at the offset again once the delay is over
+func (g *group) nack(offset uint64, delay time.Duration) {
+	g.Lock()
+	_, ok := g.pending[offset]
+	if ok {
+		g.pending[offset] = time.Now().Add(delay)
+	}
+	g.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// wake up the group rather than wait for the next check
+	time.AfterFunc(delay, func() {
+		select {
+		case g.notify <- true:
+		default:
+		}
+	})
+}
+
+// ack marks the message at the offset as consumed moving the committed
+// offset forward over the messages acked in a row
+func (g *group) ack(offset uint64) error {
+	g.Lock()
+	defer g.Unlock()
+
+	if offset <= g.committed || g.acked[offset] {
+		return nil
+	}
+
+	delete(g.pending, offset)
+	g.acked[offset] = true
+
+	committed := g.committed
+	for g.acked[committed+1] {
+		delete(g.acked, committed+1)
+		committed++
+	}
+	if committed == g.committed {
+		return nil
+	}
+	g.committed = committed
+
+	if !g.durable {
+		return nil
+	}
+	return g.topic.log.Commit(g.name, committed)
+}
+
+// leave removes the member returning true if the group is empty
+func (g *group) leave(id string) bool {
+	g.Lock()
+	defer g.Unlock()
+
+	delete(g.members, id)
+	return len(g.members) == 0
+}
+
+func (g *group) stop() {
+	select {
+	case <-g.exit:
+	default:
+		close(g.exit)
+	}
+}
+
+// run passes the messages delivered to the group to the handler
+func (s *fileSubscriber) run() {
+	for {
+		select {
+		case ev := <-s.group.deliveries:
+			s.group.received(ev.offset)
+			ev.err = s.handler(ev)
+			if ev.err == nil && s.opts.AutoAck {
+				if err := ev.Ack(); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("[file]: failed to ack %s offset %d: %v", ev.topic, ev.offset, err)
+				}
+			}
+			if ev.err != nil {
+				if eh := s.broker.opts.ErrorHandler; eh != nil {
+					eh(ev)
+				}
+			}
+		case <-s.exit:
+			return
+		case <-s.group.exit:
+			return
+		}
+	}
+}
+
+func (s *fileSubscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *fileSubscriber) Topic() string {
+	return s.topic
+}
+
+func (s *fileSubscriber) Unsubscribe() error {
+	s.once.Do(func() {
+		close(s.exit)
+
+		s.broker.RLock()
+		t, ok := s.broker.topics[s.topic]
+		s.broker.RUnlock()
+		if !ok {
+			return
+		}
+
+		t.Lock()
+		defer t.Unlock()
+
+		// the messages not acked are delivered again when the queue is back
+		if s.group.leave(s.id) {
+			s.group.stop()
+			if t.groups[s.group.name] == s.group {
+				delete(t.groups, s.group.name)
+			}
+		}
+	})
+
+	return nil
+}
+
+func (e *fileEvent) Topic() string {
+	return e.topic
+}
+
+func (e *fileEvent) Message() *broker.Message {
+	return e.message
+}
+
+// Ack marks the message as consumed by the queue group
+func (e *fileEvent) Ack() error {
+	return e.group.ack(e.offset)
+}
+
+// Nack delivers the message again once the delay is over
+// rather than waiting for the ack wait to run out
+func (e *fileEvent) Nack(delay time.Duration) error {
+	e.group.nack(e.offset, delay)
+	return nil
+}
+
+func (e *fileEvent) Error() error {
+	return e.err
+}
+
+// NewBroker returns a broker storing topics in files. Use the Dir
+// option to set where, the temp directory is used by default.
+func NewBroker(opts ...broker.Option) broker.Broker {
+	options := broker.Options{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	f := &fileBroker{
+		opts:   options,
+		topics: make(map[string]*topic),
+	}
+	f.configure()
+
+	return f
+}
@@ -0,0 +1,325 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+)
+
+func newTestBroker(t *testing.T, dir string, opts ...broker.Option) broker.Broker {
+	b := NewBroker(append([]broker.Option{Dir(dir)}, opts...)...)
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func publish(t *testing.T, b broker.Broker, topic string, n int) {
+	for i := 0; i < n; i++ {
+		if err := b.Publish(topic, &broker.Message{
+			Header: map[string]string{"foo": "bar"},
+			Body:   []byte(fmt.Sprintf("%d", i)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// receive returns the bodies of n messages
+func receive(t *testing.T, ch chan broker.Event, n int) []string {
+	var bodies []string
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-ch:
+			bodies = append(bodies, string(ev.Message().Body))
+		case <-time.After(time.Second * 5):
+			t.Fatalf("Expected %d messages got %v", n, bodies)
+		}
+	}
+	return bodies
+}
+
+func expect(t *testing.T, got []string, want ...string) {
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Expected %v got %v", want, got)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir)
+	defer b.Disconnect()
+
+	ch := make(chan broker.Event, 10)
+	sub, err := b.Subscribe("test", func(e broker.Event) error {
+		ch <- e
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	publish(t, b, "test", 3)
+	expect(t, receive(t, ch, 3), "0", "1", "2")
+}
+
+func TestBrokerDurableQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir)
+
+	ch := make(chan broker.Event, 10)
+	handler := func(e broker.Event) error {
+		ch <- e
+		return nil
+	}
+
+	sub, err := b.Subscribe("test", handler, broker.Queue("q"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish(t, b, "test", 1)
+	expect(t, receive(t, ch, 1), "0")
+	sub.Unsubscribe()
+
+	// published while the queue is down and across a restart
+	publish(t, b, "test", 2)
+	b.Disconnect()
+
+	b = newTestBroker(t, dir)
+	defer b.Disconnect()
+
+	if _, err := b.Subscribe("test", handler, broker.Queue("q")); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, receive(t, ch, 2), "0", "1")
+}
+
+func TestBrokerRedelivery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir)
+	defer b.Disconnect()
+
+	ch := make(chan broker.Event, 10)
+	var attempts int
+	_, err = b.Subscribe("test", func(e broker.Event) error {
+		attempts++
+		ch <- e
+		// only ack the second attempt
+		if attempts > 1 {
+			return e.Ack()
+		}
+		return nil
+	}, broker.Queue("q"), broker.DisableAutoAck(), AckWait(time.Millisecond*50))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, b, "test", 1)
+	expect(t, receive(t, ch, 2), "0", "0")
+
+	select {
+	case e := <-ch:
+		t.Fatalf("Unexpected delivery of %s after the ack", e.Message().Body)
+	case <-time.After(time.Millisecond * 200):
+	}
+}
+
+func TestBrokerNack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir)
+	defer b.Disconnect()
+
+	ch := make(chan broker.Event, 10)
+	var attempts int
+	_, err = b.Subscribe("test", func(e broker.Event) error {
+		attempts++
+		ch <- e
+		if attempts > 1 {
+			return nil
+		}
+		if err := broker.Nack(e, time.Millisecond*10); err != nil {
+			t.Error(err)
+		}
+		return errors.New("failed")
+	}, broker.Queue("q"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// delivered again well before the ack wait runs out
+	publish(t, b, "test", 1)
+	expect(t, receive(t, ch, 2), "0", "0")
+
+	select {
+	case e := <-ch:
+		t.Fatalf("Unexpected delivery of %s after the ack", e.Message().Body)
+	case <-time.After(time.Millisecond * 200):
+	}
+}
+
+func TestBrokerReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir)
+	defer b.Disconnect()
+
+	publish(t, b, "test", 2)
+	started := time.Now()
+	publish(t, b, "test", 2)
+
+	ch := make(chan broker.Event, 10)
+	handler := func(e broker.Event) error {
+		ch <- e
+		return nil
+	}
+
+	sub, err := b.Subscribe("test", handler, StartAtOffset(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, receive(t, ch, 3), "1", "0", "1")
+	sub.Unsubscribe()
+
+	if _, err := b.Subscribe("test", handler, StartAtTime(started)); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, receive(t, ch, 2), "0", "1")
+}
+
+func TestLogRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l, err := openLog(dir, "test", 2, time.Millisecond*50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := l.Append(&broker.Message{Body: []byte("foo")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first, _ := l.First(); first != 1 {
+		t.Fatalf("Expected the first offset to be 1 got %d", first)
+	}
+
+	// starting the next segment drops the expired ones
+	time.Sleep(time.Millisecond * 100)
+	offset, err := l.Append(&broker.Message{Body: []byte("foo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, _ := l.First(); first != offset {
+		t.Fatalf("Expected the first offset to be %d got %d", offset, first)
+	}
+	if e, _ := l.Read(1); e != nil {
+		t.Fatal("Expected the expired message to be dropped")
+	}
+	if e, _ := l.Read(offset); e == nil || string(e.Body) != "foo" {
+		t.Fatalf("Unexpected message %+v", e)
+	}
+}
+
+func TestBrokerRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir, SegmentSize(2), Retention(time.Millisecond*50))
+	defer b.Disconnect()
+
+	publish(t, b, "test", 3)
+
+	f := b.(*fileBroker)
+	f.RLock()
+	l := f.topics["test"].log
+	f.RUnlock()
+
+	// the messages expire without anything else being published
+	for i := 0; ; i++ {
+		if first, _ := l.First(); first == 4 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("Expected the expired messages to be dropped")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// the offsets carry on after everything has expired
+	publish(t, b, "test", 1)
+	if last, _ := l.Last(); last != 4 {
+		t.Fatalf("Expected the last offset to be 4 got %d", last)
+	}
+	if e, _ := l.Read(4); e == nil {
+		t.Fatal("Expected the message to be kept")
+	}
+}
+
+func TestBrokerInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newTestBroker(t, dir)
+	defer b.Disconnect()
+
+	if err := b.Init(Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Init(Dir(dir + "-other")); err == nil {
+		t.Fatal("Expected the directory not to change while connected")
+	}
+	if b.Address() != dir {
+		t.Fatalf("Expected the directory to be %s got %s", dir, b.Address())
+	}
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Init(Dir(dir + "-other")); err != nil {
+		t.Fatal(err)
+	}
+	if b.Address() != dir+"-other" {
+		t.Fatalf("Expected the directory to be %s-other got %s", dir, b.Address())
+	}
+}
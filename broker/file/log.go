@@ -0,0 +1,284 @@
+package file
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// bucket holding the segments of the log
+	segmentsBucket = []byte("segments")
+	// bucket holding the committed offsets of the queue groups
+	offsetsBucket = []byte("offsets")
+)
+
+// entry is a message stored in the log
+type entry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Header    map[string]string `json:"header"`
+	Body      []byte            `json:"body"`
+}
+
+// topicLog is the append only log of a topic. It's split into segments
+// holding up to a number of messages so old ones can be dropped whole.
+// Offsets start at 1 and increase by one with every message.
+type topicLog struct {
+	db *bolt.DB
+	// messages per segment
+	size uint64
+	// how long messages are kept, forever if zero
+	retention time.Duration
+}
+
+func itob(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
+
+func openLog(dir, topic string, size uint64, retention time.Duration) (*topicLog, error) {
+	path := filepath.Join(dir, url.PathEscape(topic)+".db")
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(segmentsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(offsetsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &topicLog{
+		db:        db,
+		size:      size,
+		retention: retention,
+	}, nil
+}
+
+// Append writes the message to the end of the log returning its offset
+func (l *topicLog) Append(m *broker.Message) (uint64, error) {
+	b, err := json.Marshal(&entry{
+		Timestamp: time.Now(),
+		Header:    m.Header,
+		Body:      m.Body,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var offset uint64
+
+	err = l.db.Update(func(tx *bolt.Tx) error {
+		segments := tx.Bucket(segmentsBucket)
+
+		offset, err = segments.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		// start a new segment when the last is full
+		var seg *bolt.Bucket
+		if k, _ := segments.Cursor().Last(); k != nil && offset-btoi(k) < l.size {
+			seg = segments.Bucket(k)
+		} else {
+			if err := l.expire(segments); err != nil {
+				return err
+			}
+			if seg, err = segments.CreateBucket(itob(offset)); err != nil {
+				return err
+			}
+		}
+
+		return seg.Put(itob(offset), b)
+	})
+
+	return offset, err
+}
+
+// Expire drops the segments whose messages are all past the retention
+func (l *topicLog) Expire() error {
+	if l.retention <= 0 {
+		return nil
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return l.expire(tx.Bucket(segmentsBucket))
+	})
+}
+
+// expire drops the segments whose messages are all past the retention
+func (l *topicLog) expire(segments *bolt.Bucket) error {
+	if l.retention <= 0 {
+		return nil
+	}
+
+	var keys [][]byte
+	c := segments.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	oldest := time.Now().Add(-l.retention)
+
+	for _, k := range keys {
+		_, v := segments.Bucket(k).Cursor().Last()
+		if v == nil {
+			break
+		}
+		var e entry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		if e.Timestamp.After(oldest) {
+			break
+		}
+		if err := segments.DeleteBucket(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// segment returns the segment holding the offset
+func segment(segments *bolt.Bucket, offset uint64) *bolt.Bucket {
+	c := segments.Cursor()
+	k, _ := c.Seek(itob(offset))
+	if k == nil || btoi(k) > offset {
+		k, _ = c.Prev()
+	}
+	if k == nil {
+		return nil
+	}
+	return segments.Bucket(k)
+}
+
+// Read returns the message at the offset or nil if there's none
+func (l *topicLog) Read(offset uint64) (*entry, error) {
+	var e *entry
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		seg := segment(tx.Bucket(segmentsBucket), offset)
+		if seg == nil {
+			return nil
+		}
+		v := seg.Get(itob(offset))
+		if v == nil {
+			return nil
+		}
+		e = new(entry)
+		return json.Unmarshal(v, e)
+	})
+
+	return e, err
+}
+
+// Last returns the offset of the last message written
+func (l *topicLog) Last() (uint64, error) {
+	var last uint64
+	err := l.db.View(func(tx *bolt.Tx) error {
+		last = tx.Bucket(segmentsBucket).Sequence()
+		return nil
+	})
+	return last, err
+}
+
+// First returns the offset of the oldest message kept
+func (l *topicLog) First() (uint64, error) {
+	var first uint64
+	err := l.db.View(func(tx *bolt.Tx) error {
+		segments := tx.Bucket(segmentsBucket)
+		if k, _ := segments.Cursor().First(); k != nil {
+			first = btoi(k)
+		} else {
+			first = segments.Sequence() + 1
+		}
+		return nil
+	})
+	return first, err
+}
+
+// Seek returns the offset of the first message written at or after t
+func (l *topicLog) Seek(t time.Time) (uint64, error) {
+	var offset uint64
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		segments := tx.Bucket(segmentsBucket)
+		offset = segments.Sequence() + 1
+
+		c := segments.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			sc := segments.Bucket(k).Cursor()
+
+			// skip the segments written before
+			_, v := sc.Last()
+			if v == nil {
+				continue
+			}
+			var e entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if e.Timestamp.Before(t) {
+				continue
+			}
+
+			for sk, sv := sc.First(); sk != nil; sk, sv = sc.Next() {
+				if err := json.Unmarshal(sv, &e); err != nil {
+					return err
+				}
+				if !e.Timestamp.Before(t) {
+					offset = btoi(sk)
+					return nil
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return offset, err
+}
+
+// Committed returns the committed offset of the queue group
+func (l *topicLog) Committed(queue string) (uint64, bool, error) {
+	var offset uint64
+	var ok bool
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(offsetsBucket).Get([]byte(queue)); v != nil {
+			offset, ok = btoi(v), true
+		}
+		return nil
+	})
+
+	return offset, ok, err
+}
+
+// Commit stores the offset the queue group has consumed up to
+func (l *topicLog) Commit(queue string, offset uint64) error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(offsetsBucket).Put([]byte(queue), itob(offset))
+	})
+}
+
+// Close closes the log
+func (l *topicLog) Close() error {
+	return l.db.Close()
+}
@@ -0,0 +1,72 @@
+package file
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+)
+
+type dirKey struct{}
+type segmentSizeKey struct{}
+type retentionKey struct{}
+type ackWaitKey struct{}
+type startOffsetKey struct{}
+type startTimeKey struct{}
+
+// Dir sets the directory the topics are stored in. It can't be changed
+// while the broker is connected.
+func Dir(dir string) broker.Option {
+	return setBrokerOption(dirKey{}, dir)
+}
+
+// SegmentSize sets the number of messages in each segment of a topic
+func SegmentSize(n int) broker.Option {
+	return setBrokerOption(segmentSizeKey{}, n)
+}
+
+// Retention sets how long messages are kept. Whole segments are dropped
+// once all their messages are older, checked a few times per period while
+// connected. Messages are kept forever if zero.
+func Retention(d time.Duration) broker.Option {
+	return setBrokerOption(retentionKey{}, d)
+}
+
+// AckWait sets how long a message is waited on to be acked before it's
+// delivered again. Messages are acked when the handler returns nil unless
+// auto ack is disabled, in which case the handler has to call Ack.
+func AckWait(d time.Duration) broker.SubscribeOption {
+	return setSubscribeOption(ackWaitKey{}, d)
+}
+
+// StartAtOffset replays the topic from the message at the offset. Offsets
+// start at 1. The position of an existing queue is moved back or forward.
+func StartAtOffset(offset uint64) broker.SubscribeOption {
+	return setSubscribeOption(startOffsetKey{}, offset)
+}
+
+// StartAtTime replays the topic from the first message published at or
+// after t. The position of an existing queue is moved back or forward.
+func StartAtTime(t time.Time) broker.SubscribeOption {
+	return setSubscribeOption(startTimeKey{}, t)
+}
+
+// setBrokerOption returns a function to setup a context with given value
+func setBrokerOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// setSubscribeOption returns a function to setup a context with given value
+func setSubscribeOption(k, v interface{}) broker.SubscribeOption {
+	return func(o *broker.SubscribeOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/registry/cache"
 	maddr "github.com/micro/go-micro/v2/util/addr"
+	"github.com/micro/go-micro/v2/util/lock"
 	mnet "github.com/micro/go-micro/v2/util/net"
 	mls "github.com/micro/go-micro/v2/util/tls"
 	"golang.org/x/net/http2"
@@ -47,6 +49,9 @@ type httpBroker struct {
 	// offline message inbox
 	mtx   sync.RWMutex
 	inbox map[string][][]byte
+
+	// locks serialising the handling of keyed messages
+	keys lock.Keys
 }
 
 type httpSubscriber struct {
@@ -329,6 +334,12 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	p := &httpEvent{m: m, t: topic}
 	id := req.Form.Get("id")
 
+	// handle the messages of a key one at a time
+	if key := m.Header[OrderingKeyHeader]; len(key) > 0 {
+		h.keys.Lock(id + "/" + key)
+		defer h.keys.Unlock(id + "/" + key)
+	}
+
 	//nolint:prealloc
 	var subs []Handler
 
@@ -510,6 +521,12 @@ func (h *httpBroker) Options() Options {
 }
 
 func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption) error {
+	var options PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	key := options.OrderingKey
+
 	// create the message first
 	m := &Message{
 		Header: make(map[string]string),
@@ -521,6 +538,9 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 	}
 
 	m.Header["Micro-Topic"] = topic
+	if len(key) > 0 {
+		m.Header[OrderingKeyHeader] = key
+	}
 
 	// encode the message
 	b, err := h.opts.Codec.Marshal(m)
@@ -528,8 +548,11 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 		return err
 	}
 
-	// save the message
-	h.saveMessage(topic, b)
+	// keyed messages skip the inbox to keep their order
+	if len(key) == 0 {
+		// save the message
+		h.saveMessage(topic, b)
+	}
 
 	// now attempt to get the service
 	h.RLock()
@@ -563,7 +586,10 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 		return nil
 	}
 
-	srv := func(s []*registry.Service, b []byte) {
+	srv := func(s []*registry.Service, b []byte) error {
+		// the subscribers which failed and why
+		var failed []string
+
 		for _, service := range s {
 			var nodes []*registry.Node
 
@@ -594,26 +620,50 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 				// publish to all nodes
 				for _, node := range nodes {
 					// publish async
-					if err := pub(node, topic, b); err == nil {
+					if perr := pub(node, topic, b); perr == nil {
 						success = true
+					} else {
+						failed = append(failed, fmt.Sprintf("%s: %v", node.Id, perr))
 					}
 				}
 
 				// save if it failed to publish at least once
-				if !success {
+				if !success && len(key) == 0 {
 					h.saveMessage(topic, b)
 				}
 			default:
 				// select node to publish to
 				node := nodes[rand.Int()%len(nodes)]
 
+				// the same node gets all the messages of the key
+				if len(key) > 0 {
+					ids := make([]string, len(nodes))
+					for i, n := range nodes {
+						ids[i] = n.Id
+					}
+					node = nodes[AssignKey(key, ids)]
+				}
+
 				// publish async to one node
-				if err := pub(node, topic, b); err != nil {
+				if perr := pub(node, topic, b); perr != nil {
+					failed = append(failed, fmt.Sprintf("%s (queue %s): %v", node.Id, service.Version, perr))
 					// if failed save it
-					h.saveMessage(topic, b)
+					if len(key) == 0 {
+						h.saveMessage(topic, b)
+					}
 				}
 			}
 		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to publish to %s", strings.Join(failed, ", "))
+		}
+		return nil
+	}
+
+	// keyed messages are sent before returning so they arrive in order
+	if len(key) > 0 {
+		return srv(s, b)
 	}
 
 	// do the rest async
@@ -1,6 +1,8 @@
 package broker_test
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -234,6 +236,135 @@ func TestBroker(t *testing.T) {
 	}
 }
 
+func TestBrokerOrderingKey(t *testing.T) {
+	m := newTestRegistry()
+
+	// the keys and the order of the messages each member received
+	var mtx sync.Mutex
+	received := make([]map[string][]string, 2)
+
+	var brokers []broker.Broker
+	for i := range received {
+		b := broker.NewBroker(broker.Registry(m))
+		if err := b.Connect(); err != nil {
+			t.Fatalf("Unexpected connect error: %v", err)
+		}
+		defer b.Disconnect()
+		brokers = append(brokers, b)
+
+		keys := make(map[string][]string)
+		received[i] = keys
+
+		if _, err := b.Subscribe("test", func(p broker.Event) error {
+			m := p.Message()
+			mtx.Lock()
+			keys[m.Header["key"]] = append(keys[m.Header["key"]], string(m.Body))
+			mtx.Unlock()
+			return nil
+		}, broker.Queue("q")); err != nil {
+			t.Fatalf("Unexpected subscribe error: %v", err)
+		}
+	}
+
+	keys := []string{"a", "b", "c", "d"}
+	for i := 0; i < 20; i++ {
+		key := keys[i%len(keys)]
+		msg := &broker.Message{
+			Header: map[string]string{"key": key},
+			Body:   []byte(fmt.Sprintf("%d", i)),
+		}
+		// keyed messages are delivered before publish returns
+		if err := brokers[0].Publish("test", msg, broker.OrderingKey(key)); err != nil {
+			t.Fatalf("Unexpected publish error: %v", err)
+		}
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	for i, key := range keys {
+		var owners int
+		for _, r := range received {
+			if msgs, ok := r[key]; ok {
+				owners++
+				want := fmt.Sprint([]int{i, i + 4, i + 8, i + 12, i + 16})
+				if fmt.Sprint(msgs) != want {
+					t.Fatalf("Expected key %s messages %s got %v", key, want, msgs)
+				}
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("Expected key %s handled by 1 member got %d", key, owners)
+		}
+	}
+}
+
+func TestBrokerOrderingKeyFailed(t *testing.T) {
+	m := newTestRegistry()
+
+	b := broker.NewBroker(broker.Registry(m))
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+	defer b.Disconnect()
+
+	received := make(chan bool, 1)
+	if _, err := b.Subscribe("test", func(p broker.Event) error {
+		received <- true
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	// a subscriber which can't be reached
+	if err := m.Register(&registry.Service{
+		Name:    "micro.http.broker",
+		Version: "ff.http.broadcast",
+		Nodes: []*registry.Node{{
+			Id:       "test-unreachable",
+			Address:  "127.0.0.1:1",
+			Metadata: map[string]string{"broker": "http", "topic": "test", "secure": "false"},
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := b.Publish("test", &broker.Message{Body: []byte("hello")}, broker.OrderingKey("a"))
+	// only the unreachable subscriber is named
+	if err == nil || !strings.Contains(err.Error(), "test-unreachable") || strings.Contains(err.Error(), ", ") {
+		t.Fatalf("Expected the unreachable subscriber in the error got %v", err)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the other subscriber to receive the message")
+	}
+}
+
+func TestAssignKey(t *testing.T) {
+	members := []string{"a", "b", "c", "d"}
+	owner := broker.AssignKey("foo", members)
+	if owner < 0 {
+		t.Fatal("Expected the key to be assigned")
+	}
+
+	// removing another member doesn't move the key
+	for i := range members {
+		if i == owner {
+			continue
+		}
+		rest := append(append([]string{}, members[:i]...), members[i+1:]...)
+		if got := rest[broker.AssignKey("foo", rest)]; got != members[owner] {
+			t.Fatalf("Expected the key to stay with %s got %s", members[owner], got)
+		}
+	}
+
+	if broker.AssignKey("foo", nil) != -1 {
+		t.Fatal("Expected no member to be assigned")
+	}
+}
+
 func TestConcurrentSubBroker(t *testing.T) {
 	m := newTestRegistry()
 	b := broker.NewBroker(broker.Registry(m))
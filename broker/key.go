@@ -0,0 +1,40 @@
+package broker
+
+import (
+	"hash/fnv"
+)
+
+var (
+	// OrderingKeyHeader carries the ordering key of a message
+	OrderingKeyHeader = "Micro-Ordering-Key"
+)
+
+// score returns the rendezvous hash weight of a member for the key
+func score(key, member string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(member))
+	// fnv has poor avalanche on short inputs so mix it
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// AssignKey returns the index of the queue member the key is assigned to.
+// Keys are assigned with rendezvous hashing so when a member leaves only
+// its keys move. It returns -1 if there are no members.
+func AssignKey(key string, members []string) int {
+	owner := -1
+	var max uint64
+	for i, m := range members {
+		if s := score(key, m); owner < 0 || s > max {
+			owner, max = i, s
+		}
+	}
+	return owner
+}
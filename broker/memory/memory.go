@@ -37,6 +37,9 @@ type memorySubscriber struct {
 	exit    chan bool
 	handler broker.Handler
 	opts    broker.SubscribeOptions
+
+	// held while handling a keyed message
+	ordered sync.Mutex
 }
 
 func (m *memoryBroker) Options() broker.Options {
@@ -120,8 +123,15 @@ func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker
 		opts:    m.opts,
 	}
 
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	subs = assign(options.OrderingKey, subs)
+
 	for _, sub := range subs {
-		if err := sub.handler(p); err != nil {
+		if err := sub.handle(p, options.OrderingKey); err != nil {
 			p.err = err
 			if eh := m.opts.ErrorHandler; eh != nil {
 				eh(p)
@@ -134,6 +144,37 @@ func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker
 	return nil
 }
 
+// assign returns the subscribers a message is delivered to, one member of
+// each queue and every subscriber without one. Keyed messages go to the
+// member the key is assigned to, the others to a random one.
+func assign(key string, subs []*memorySubscriber) []*memorySubscriber {
+	var assigned []*memorySubscriber
+	queues := make(map[string][]*memorySubscriber)
+
+	for _, sub := range subs {
+		if len(sub.opts.Queue) == 0 {
+			assigned = append(assigned, sub)
+			continue
+		}
+		queues[sub.opts.Queue] = append(queues[sub.opts.Queue], sub)
+	}
+
+	for _, members := range queues {
+		if len(key) == 0 {
+			assigned = append(assigned, members[rand.Intn(len(members))])
+			continue
+		}
+
+		ids := make([]string, len(members))
+		for i, sub := range members {
+			ids[i] = sub.id
+		}
+		assigned = append(assigned, members[broker.AssignKey(key, ids)])
+	}
+
+	return assigned
+}
+
 func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
 	m.RLock()
 	if !m.connected {
@@ -210,6 +251,15 @@ func (m *memoryEvent) Error() error {
 	return m.err
 }
 
+// handle calls the handler, one keyed message at a time
+func (m *memorySubscriber) handle(p broker.Event, key string) error {
+	if len(key) > 0 {
+		m.ordered.Lock()
+		defer m.ordered.Unlock()
+	}
+	return m.handler(p)
+}
+
 func (m *memorySubscriber) Options() broker.SubscribeOptions {
 	return m.opts
 }
@@ -48,3 +48,103 @@ func TestMemoryBroker(t *testing.T) {
 		t.Fatalf("Unexpected connect error %v", err)
 	}
 }
+
+func TestMemoryBrokerOrderingKey(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	// the keys and the order of the messages each member received
+	received := make([]map[string][]string, 3)
+	for i := range received {
+		keys := make(map[string][]string)
+		received[i] = keys
+
+		if _, err := b.Subscribe("test", func(p broker.Event) error {
+			m := p.Message()
+			keys[m.Header["key"]] = append(keys[m.Header["key"]], string(m.Body))
+			return nil
+		}, broker.Queue("q")); err != nil {
+			t.Fatalf("Unexpected error subscribing %v", err)
+		}
+	}
+
+	var broadcast int
+	if _, err := b.Subscribe("test", func(p broker.Event) error {
+		broadcast++
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	keys := []string{"a", "b", "c", "d"}
+	for i := 0; i < 20; i++ {
+		key := keys[i%len(keys)]
+		message := &broker.Message{
+			Header: map[string]string{"key": key},
+			Body:   []byte(fmt.Sprintf("%d", i)),
+		}
+		if err := b.Publish("test", message, broker.OrderingKey(key)); err != nil {
+			t.Fatalf("Unexpected error publishing %d", i)
+		}
+	}
+
+	if broadcast != 20 {
+		t.Fatalf("Expected 20 messages without a queue got %d", broadcast)
+	}
+
+	for i, key := range keys {
+		var owners int
+		for _, r := range received {
+			if msgs, ok := r[key]; ok {
+				owners++
+				want := fmt.Sprint([]string{fmt.Sprint(i), fmt.Sprint(i + 4), fmt.Sprint(i + 8), fmt.Sprint(i + 12), fmt.Sprint(i + 16)})
+				if fmt.Sprint(msgs) != want {
+					t.Fatalf("Expected key %s messages %s got %v", key, want, msgs)
+				}
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("Expected key %s handled by 1 member got %d", key, owners)
+		}
+	}
+}
+
+func TestMemoryBrokerQueue(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	// each message goes to one member of the queue
+	var queued, broadcast int
+	for i := 0; i < 3; i++ {
+		if _, err := b.Subscribe("test", func(p broker.Event) error {
+			queued++
+			return nil
+		}, broker.Queue("q")); err != nil {
+			t.Fatalf("Unexpected error subscribing %v", err)
+		}
+	}
+	if _, err := b.Subscribe("test", func(p broker.Event) error {
+		broadcast++
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := b.Publish("test", &broker.Message{Body: []byte("hello")}); err != nil {
+			t.Fatalf("Unexpected error publishing %d", i)
+		}
+	}
+
+	if queued != 10 || broadcast != 10 {
+		t.Fatalf("Expected 10 messages to the queue and 10 without got %d %d", queued, broadcast)
+	}
+}
@@ -0,0 +1,27 @@
+package broker
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNackNotSupported is returned by Nack if the broker can't deliver the message again
+	ErrNackNotSupported = errors.New("nack not supported")
+)
+
+// nacker is implemented by the events of brokers which deliver messages again
+type nacker interface {
+	Nack(delay time.Duration) error
+}
+
+// Nack hands the message back to the broker to be delivered again once
+// the delay is over. The message isn't acked. It returns
+// ErrNackNotSupported if the broker doesn't support it.
+func Nack(e Event, delay time.Duration) error {
+	n, ok := e.(nacker)
+	if !ok {
+		return ErrNackNotSupported
+	}
+	return n.Nack(delay)
+}
@@ -26,6 +26,8 @@ type Options struct {
 }
 
 type PublishOptions struct {
+	// OrderingKey of the message, see OrderingKey
+	OrderingKey string
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -56,6 +58,18 @@ func PublishContext(ctx context.Context) PublishOption {
 	}
 }
 
+// OrderingKey sets the key of the message. Brokers which support it
+// deliver the messages sharing a key to a single member of each queue,
+// one at a time and in the order they were published. The http broker
+// sends keyed messages before Publish returns and drops those it fails
+// to send rather than keep them to send later, out of order. The error
+// is returned for the publisher to retry.
+func OrderingKey(key string) PublishOption {
+	return func(o *PublishOptions) {
+		o.OrderingKey = key
+	}
+}
+
 type SubscribeOption func(*SubscribeOptions)
 
 func NewSubscribeOptions(opts ...SubscribeOption) SubscribeOptions {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/micro/go-micro/v2/util/backoff"
+	"github.com/micro/go-micro/v2/util/jitter"
 )
 
 type BackoffFunc func(ctx context.Context, req Request, attempts int) (time.Duration, error)
@@ -12,3 +13,34 @@ type BackoffFunc func(ctx context.Context, req Request, attempts int) (time.Dura
 func exponentialBackoff(ctx context.Context, req Request, attempts int) (time.Duration, error) {
 	return backoff.Do(attempts), nil
 }
+
+// FullJitterBackoff returns a BackoffFunc which sleeps a random time between
+// zero and the exponential backoff of base, limited to max.
+func FullJitterBackoff(base, max time.Duration) BackoffFunc {
+	return func(ctx context.Context, req Request, attempts int) (time.Duration, error) {
+		if attempts == 0 {
+			return 0, nil
+		}
+		return jitter.Do(backoff.Exponential(base, max, attempts-1)), nil
+	}
+}
+
+// DecorrelatedJitterBackoff returns a BackoffFunc which sleeps a random time
+// between base and three times the previous sleep, limited to max.
+func DecorrelatedJitterBackoff(base, max time.Duration) BackoffFunc {
+	return func(ctx context.Context, req Request, attempts int) (time.Duration, error) {
+		if attempts == 0 {
+			return 0, nil
+		}
+		// the func is stateless so walk the chain of sleeps, each only
+		// depends on the one before so it has the same distribution
+		sleep := base
+		for i := 1; i < attempts; i++ {
+			sleep = jitter.Between(base, sleep*3)
+			if sleep > max {
+				sleep = max
+			}
+		}
+		return sleep, nil
+	}
+}
@@ -29,3 +29,34 @@ func TestBackoff(t *testing.T) {
 		}
 	}
 }
+
+func TestJitterBackoff(t *testing.T) {
+	c := NewClient()
+	req := c.NewRequest("test", "test", nil)
+
+	base := 10 * time.Millisecond
+	max := time.Second
+
+	for name, fn := range map[string]BackoffFunc{
+		"full":         FullJitterBackoff(base, max),
+		"decorrelated": DecorrelatedJitterBackoff(base, max),
+	} {
+		d, err := fn(context.TODO(), req, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != 0 {
+			t.Fatalf("%s: Expected no initial delay got %v", name, d)
+		}
+
+		for i := 1; i < 20; i++ {
+			d, err := fn(context.TODO(), req, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d < 0 || d > max {
+				t.Fatalf("%s: Expected delay between 0 and %v got %v", name, max, d)
+			}
+		}
+	}
+}
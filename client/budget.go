@@ -0,0 +1,91 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultRetryBudget is the share of recent requests which can be retried
+	DefaultRetryBudget = 0.1
+	// DefaultBudgetMinRetries is the number of retries always allowed in the window
+	DefaultBudgetMinRetries = 10
+	// DefaultBudgetWindow is the window requests and retries are counted over
+	DefaultBudgetWindow = time.Second * 10
+
+	// number of buckets the budget window is split into
+	budgetBuckets = 10
+)
+
+// Budget limits retries to a share of the recent requests made by a client
+// so that retries can't multiply the load during an outage
+type Budget struct {
+	ratio float64
+	min   int
+	width int64
+
+	sync.Mutex
+	buckets []budgetBucket
+}
+
+type budgetBucket struct {
+	epoch    int64
+	requests int
+	retries  int
+}
+
+// NewBudget returns a budget which allows retries up to ratio of the requests
+// plus min retries made in the rolling window
+func NewBudget(ratio float64, min int, window time.Duration) *Budget {
+	width := int64(window) / int64(budgetBuckets)
+	if width <= 0 {
+		width = 1
+	}
+	return &Budget{
+		ratio:   ratio,
+		min:     min,
+		width:   width,
+		buckets: make([]budgetBucket, budgetBuckets),
+	}
+}
+
+// bucket returns the current bucket. Must be called with the lock held.
+func (b *Budget) bucket(epoch int64) *budgetBucket {
+	bk := &b.buckets[epoch%int64(len(b.buckets))]
+	if bk.epoch != epoch {
+		*bk = budgetBucket{epoch: epoch}
+	}
+	return bk
+}
+
+// Request records a request
+func (b *Budget) Request() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.bucket(time.Now().UnixNano()/b.width).requests++
+}
+
+// Retry records a retry and returns true if it's within the budget
+func (b *Budget) Retry() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	epoch := time.Now().UnixNano() / b.width
+
+	var requests, retries int
+	for _, v := range b.buckets {
+		if epoch-v.epoch >= int64(len(b.buckets)) {
+			continue
+		}
+		requests += v.requests
+		retries += v.retries
+	}
+
+	if float64(retries) >= float64(b.min)+b.ratio*float64(requests) {
+		return false
+	}
+
+	b.bucket(epoch).retries++
+	return true
+}
@@ -5,49 +5,270 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/micro/go-micro/v2/metadata"
 	cache "github.com/patrickmn/go-cache"
 )
 
-// NewCache returns an initialised cache.
-func NewCache() *Cache {
-	return &Cache{
+// Cache for responses
+type Cache interface {
+	// Init the cache with options
+	Init(...CacheOption) error
+	// Options of the cache
+	Options() CacheOptions
+	// Get reads a cached response into rsp. Stale is true if the
+	// response has expired and is only kept while it's revalidated.
+	Get(ctx context.Context, req *Request, rsp interface{}) (stale bool, ok bool)
+	// Set a response in the cache which can still be
+	// served for the stale duration after it expires
+	Set(ctx context.Context, req *Request, rsp interface{}, expiry, stale time.Duration) error
+	// Invalidate removes the cached responses of a service
+	// or only those of an endpoint if one is given
+	Invalidate(service, endpoint string) error
+	// List the key value pairs in the cache
+	List() map[string]string
+	// String returns the name of the implementation
+	String() string
+}
+
+// CacheOptions are the options for a response cache
+type CacheOptions struct {
+	// Rules set the expiry of responses per service or endpoint
+	Rules []CacheRule
+
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
+}
+
+// CacheRule sets the expiry of the responses of a service
+// or an endpoint. An empty endpoint matches all of them.
+type CacheRule struct {
+	Service  string
+	Endpoint string
+	Expiry   time.Duration
+}
+
+// CacheOption used to initialise the cache
+type CacheOption func(*CacheOptions)
+
+// CacheTTL adds a rule caching the responses of the service for the expiry.
+// If the endpoint is set the rule only applies to that endpoint.
+func CacheTTL(service, endpoint string, expiry time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.Rules = append(o.Rules, CacheRule{
+			Service:  service,
+			Endpoint: endpoint,
+			Expiry:   expiry,
+		})
+	}
+}
+
+// Expiry returns the expiry of the most specific rule matching the request
+func (o CacheOptions) Expiry(req Request) time.Duration {
+	var expiry time.Duration
+	for _, r := range o.Rules {
+		if r.Service != req.Service() {
+			continue
+		}
+		if r.Endpoint == req.Endpoint() {
+			return r.Expiry
+		}
+		if len(r.Endpoint) == 0 {
+			expiry = r.Expiry
+		}
+	}
+	return expiry
+}
+
+// NewCache returns an initialised in memory cache.
+func NewCache(opts ...CacheOption) Cache {
+	var options CacheOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &memoryCache{
+		opts:  options,
 		cache: cache.New(cache.NoExpiration, 30*time.Second),
 	}
 }
 
-// Cache for responses
-type Cache struct {
+// memoryCache keeps the responses in memory with no bound
+type memoryCache struct {
+	opts  CacheOptions
 	cache *cache.Cache
 }
 
-// Get a response from the cache
-func (c *Cache) Get(ctx context.Context, req *Request) (interface{}, bool) {
-	return c.cache.Get(key(ctx, req))
+// entry is a cached response
+type entry struct {
+	rsp     interface{}
+	expires time.Time
+}
+
+func (c *memoryCache) Init(opts ...CacheOption) error {
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	return nil
+}
+
+func (c *memoryCache) Options() CacheOptions {
+	return c.opts
+}
+
+func (c *memoryCache) Get(ctx context.Context, req *Request, rsp interface{}) (bool, bool) {
+	v, ok := c.cache.Get(CacheKey(ctx, req))
+	if !ok {
+		return false, false
+	}
+	e := v.(*entry)
+
+	val := reflect.ValueOf(rsp)
+	src := reflect.ValueOf(e.rsp)
+	if val.Kind() != reflect.Ptr || src.Kind() != reflect.Ptr || val.Type() != src.Type() {
+		return false, false
+	}
+	val.Elem().Set(src.Elem())
+
+	return !e.expires.IsZero() && time.Now().After(e.expires), true
+}
+
+func (c *memoryCache) Set(ctx context.Context, req *Request, rsp interface{}, expiry, stale time.Duration) error {
+	e := &entry{rsp: rsp}
+	if expiry > 0 {
+		e.expires = time.Now().Add(expiry)
+		expiry += stale
+	}
+	c.cache.Set(CacheKey(ctx, req), e, expiry)
+	return nil
 }
 
-// Set a response in the cache
-func (c *Cache) Set(ctx context.Context, req *Request, rsp interface{}, expiry time.Duration) {
-	c.cache.Set(key(ctx, req), rsp, expiry)
+func (c *memoryCache) Invalidate(service, endpoint string) error {
+	prefix := CacheKeyPrefix(service, endpoint)
+	for k := range c.cache.Items() {
+		if strings.HasPrefix(k, prefix) {
+			c.cache.Delete(k)
+		}
+	}
+	return nil
 }
 
 // List the key value pairs in the cache
-func (c *Cache) List() map[string]string {
+func (c *memoryCache) List() map[string]string {
 	items := c.cache.Items()
 
 	rsp := make(map[string]string, len(items))
 	for k, v := range items {
-		bytes, _ := json.Marshal(v.Object)
+		bytes, _ := json.Marshal(v.Object.(*entry).rsp)
 		rsp[k] = string(bytes)
 	}
 
 	return rsp
 }
 
-// key returns a hash for the context and request
-func key(ctx context.Context, req *Request) string {
+func (c *memoryCache) String() string {
+	return "memory"
+}
+
+// Group shares one call between concurrent identical requests.
+// The zero value is ready to use.
+type Group struct {
+	sync.Mutex
+	// calls in flight by key
+	calls map[string]*call
+}
+
+// call is a call in flight shared by identical requests
+type call struct {
+	done chan struct{}
+	rsp  interface{}
+	err  error
+}
+
+// Do executes fn once for concurrent identical requests. The callers
+// waiting on a call in flight receive its result and shared is true.
+// The call is passed a context which keeps the values and deadline of
+// the first caller but not its cancellation, so a caller giving up
+// only returns early itself and doesn't fail the others.
+func (g *Group) Do(ctx context.Context, req *Request, fn func(context.Context) (interface{}, error)) (rsp interface{}, shared bool, err error) {
+	k := CacheKey(ctx, req)
+
+	g.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	cl, shared := g.calls[k]
+	if !shared {
+		cl = &call{done: make(chan struct{})}
+		g.calls[k] = cl
+		go g.call(ctx, k, cl, fn)
+	}
+	g.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.rsp, shared, cl.err
+	case <-ctx.Done():
+		return nil, shared, ctx.Err()
+	}
+}
+
+// call executes fn and hands the result to the callers waiting on it
+func (g *Group) call(ctx context.Context, k string, cl *call, fn func(context.Context) (interface{}, error)) {
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	cl.rsp, cl.err = fn(ctx)
+
+	g.Lock()
+	delete(g.calls, k)
+	g.Unlock()
+	close(cl.done)
+}
+
+// detached keeps the values of a context but not its cancellation
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+// detach returns a context with the values and deadline of ctx
+// which isn't cancelled along with it
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached{ctx}, d)
+	}
+	return context.WithCancel(detached{ctx})
+}
+
+// CacheKeyPrefix returns the prefix of the cache keys of a
+// service or only those of an endpoint if one is given
+func CacheKeyPrefix(service, endpoint string) string {
+	if len(endpoint) == 0 {
+		return service + "/"
+	}
+	return service + "/" + endpoint + "/"
+}
+
+// CacheKey returns the key for the context and request. It's prefixed
+// by the service and endpoint so they can be invalidated.
+func CacheKey(ctx context.Context, req *Request) string {
 	ns, _ := metadata.Get(ctx, "Micro-Namespace")
 
 	bytes, _ := json.Marshal(map[string]interface{}{
@@ -62,5 +283,5 @@ func key(ctx context.Context, req *Request) string {
 
 	h := fnv.New64()
 	h.Write(bytes)
-	return fmt.Sprintf("%x", h.Sum(nil))
+	return fmt.Sprintf("%s%x", CacheKeyPrefix((*req).Service(), (*req).Endpoint()), h.Sum(nil))
 }
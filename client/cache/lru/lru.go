@@ -0,0 +1,201 @@
+// Package lru is a client response cache bounded by entries or bytes
+package lru
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/codec/json"
+)
+
+var (
+	// DefaultEntries is the max number of responses kept when no bound is set
+	DefaultEntries = 1024
+)
+
+type lruCache struct {
+	opts client.CacheOptions
+
+	sync.Mutex
+	entries int
+	bytes   int
+	size    int
+	list    *list.List
+	items   map[string]*list.Element
+}
+
+// entry is a cached response encoded as json
+type entry struct {
+	key  string
+	data []byte
+	// when the response expires
+	expires time.Time
+	// when the response can no longer be served stale
+	deadline time.Time
+}
+
+// NewCache returns a cache which evicts the least recently used
+// responses when over the max number of entries or bytes
+func NewCache(opts ...client.CacheOption) client.Cache {
+	c := &lruCache{
+		list:  list.New(),
+		items: make(map[string]*list.Element),
+	}
+	c.configure(opts...)
+	return c
+}
+
+func (c *lruCache) configure(opts ...client.CacheOption) {
+	for _, o := range opts {
+		o(&c.opts)
+	}
+
+	c.entries = 0
+	c.bytes = 0
+
+	if ctx := c.opts.Context; ctx != nil {
+		if n, ok := ctx.Value(entriesKey{}).(int); ok {
+			c.entries = n
+		}
+		if n, ok := ctx.Value(bytesKey{}).(int); ok {
+			c.bytes = n
+		}
+	}
+
+	if c.entries <= 0 && c.bytes <= 0 {
+		c.entries = DefaultEntries
+	}
+}
+
+func (c *lruCache) Init(opts ...client.CacheOption) error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.configure(opts...)
+	c.evict()
+	return nil
+}
+
+func (c *lruCache) Options() client.CacheOptions {
+	return c.opts
+}
+
+func (c *lruCache) Get(ctx context.Context, req *client.Request, rsp interface{}) (bool, bool) {
+	k := client.CacheKey(ctx, req)
+
+	c.Lock()
+	el, ok := c.items[k]
+	if !ok {
+		c.Unlock()
+		return false, false
+	}
+
+	e := el.Value.(*entry)
+	now := time.Now()
+	if !e.deadline.IsZero() && now.After(e.deadline) {
+		c.remove(el)
+		c.Unlock()
+		return false, false
+	}
+
+	c.list.MoveToFront(el)
+	data := e.data
+	stale := !e.expires.IsZero() && now.After(e.expires)
+	c.Unlock()
+
+	if err := (json.Marshaler{}).Unmarshal(data, rsp); err != nil {
+		return false, false
+	}
+
+	return stale, true
+}
+
+func (c *lruCache) Set(ctx context.Context, req *client.Request, rsp interface{}, expiry, stale time.Duration) error {
+	b, err := json.Marshaler{}.Marshal(rsp)
+	if err != nil {
+		return err
+	}
+	// the marshaler reuses its buffers
+	data := make([]byte, len(b))
+	copy(data, b)
+
+	e := &entry{
+		key:  client.CacheKey(ctx, req),
+		data: data,
+	}
+	if expiry > 0 {
+		e.expires = time.Now().Add(expiry)
+		e.deadline = e.expires.Add(stale)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if el, ok := c.items[e.key]; ok {
+		c.remove(el)
+	}
+
+	// too big to ever fit
+	if c.bytes > 0 && len(data) > c.bytes {
+		return nil
+	}
+
+	c.items[e.key] = c.list.PushFront(e)
+	c.size += len(data)
+	c.evict()
+
+	return nil
+}
+
+func (c *lruCache) Invalidate(service, endpoint string) error {
+	prefix := client.CacheKeyPrefix(service, endpoint)
+
+	c.Lock()
+	defer c.Unlock()
+
+	for k, el := range c.items {
+		if strings.HasPrefix(k, prefix) {
+			c.remove(el)
+		}
+	}
+
+	return nil
+}
+
+func (c *lruCache) List() map[string]string {
+	c.Lock()
+	defer c.Unlock()
+
+	rsp := make(map[string]string, len(c.items))
+	for k, el := range c.items {
+		rsp[k] = string(el.Value.(*entry).data)
+	}
+	return rsp
+}
+
+func (c *lruCache) String() string {
+	return "lru"
+}
+
+// evict drops the least recently used entries until within the bounds.
+// Must be called with the lock held.
+func (c *lruCache) evict() {
+	for c.list.Len() > 0 {
+		if (c.entries <= 0 || c.list.Len() <= c.entries) && (c.bytes <= 0 || c.size <= c.bytes) {
+			return
+		}
+		c.remove(c.list.Back())
+	}
+}
+
+// remove drops an entry. Must be called with the lock held.
+func (c *lruCache) remove(el *list.Element) {
+	e := el.Value.(*entry)
+	c.list.Remove(el)
+	delete(c.items, e.key)
+	c.size -= len(e.data)
+}
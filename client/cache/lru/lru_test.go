@@ -0,0 +1,85 @@
+package lru
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+)
+
+type testRsp struct {
+	Value string `json:"value"`
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.TODO()
+
+	reqs := make([]client.Request, 4)
+	for i := range reqs {
+		reqs[i] = client.NewRequest("go.micro.service.foo", fmt.Sprintf("Foo.Bar%d", i), nil)
+	}
+
+	c := NewCache(Entries(3))
+	for i := 0; i < 3; i++ {
+		c.Set(ctx, &reqs[i], &testRsp{Value: fmt.Sprintf("%d", i)}, time.Minute, 0)
+	}
+
+	// use the first so the second is the least recently used
+	rsp := new(testRsp)
+	if _, ok := c.Get(ctx, &reqs[0], rsp); !ok || rsp.Value != "0" {
+		t.Fatalf("Expected 0 got %v", rsp.Value)
+	}
+
+	c.Set(ctx, &reqs[3], &testRsp{Value: "3"}, time.Minute, 0)
+
+	if _, ok := c.Get(ctx, &reqs[1], new(testRsp)); ok {
+		t.Fatal("Expected the least recently used to be evicted")
+	}
+	for _, i := range []int{0, 2, 3} {
+		if _, ok := c.Get(ctx, &reqs[i], new(testRsp)); !ok {
+			t.Fatalf("Expected %d to be cached", i)
+		}
+	}
+
+	c.Invalidate("go.micro.service.foo", "Foo.Bar0")
+	if _, ok := c.Get(ctx, &reqs[0], new(testRsp)); ok {
+		t.Fatal("Expected Foo.Bar0 to be invalidated")
+	}
+	if n := len(c.List()); n != 2 {
+		t.Fatalf("Expected 2 entries got %d", n)
+	}
+}
+
+func TestLRUBytes(t *testing.T) {
+	ctx := context.TODO()
+	req1 := client.NewRequest("go.micro.service.foo", "Foo.Bar", nil)
+	req2 := client.NewRequest("go.micro.service.foo", "Foo.Baz", nil)
+
+	// {"value":"0123456789"} is 22 bytes
+	c := NewCache(Bytes(30))
+	c.Set(ctx, &req1, &testRsp{Value: "0123456789"}, time.Minute, 0)
+	c.Set(ctx, &req2, &testRsp{Value: "0123456789"}, time.Minute, 0)
+
+	if _, ok := c.Get(ctx, &req1, new(testRsp)); ok {
+		t.Fatal("Expected the first response to be evicted")
+	}
+	if _, ok := c.Get(ctx, &req2, new(testRsp)); !ok {
+		t.Fatal("Expected the second response to be cached")
+	}
+
+	// stale responses are served until the stale window passes
+	c.Set(ctx, &req2, &testRsp{Value: "bar"}, time.Millisecond*10, time.Millisecond*20)
+	time.Sleep(time.Millisecond * 15)
+
+	rsp := new(testRsp)
+	if stale, ok := c.Get(ctx, &req2, rsp); !ok || !stale || rsp.Value != "bar" {
+		t.Fatalf("Expected a stale response got %v %v %v", rsp, stale, ok)
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	if _, ok := c.Get(ctx, &req2, new(testRsp)); ok {
+		t.Fatal("Expected the response to be gone")
+	}
+}
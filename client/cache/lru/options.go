@@ -0,0 +1,31 @@
+package lru
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/client"
+)
+
+type entriesKey struct{}
+
+type bytesKey struct{}
+
+// Entries sets the max number of responses kept in the cache
+func Entries(n int) client.CacheOption {
+	return func(o *client.CacheOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, entriesKey{}, n)
+	}
+}
+
+// Bytes sets the max size in bytes of the responses kept in the cache
+func Bytes(n int) client.CacheOption {
+	return func(o *client.CacheOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, bytesKey{}, n)
+	}
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/client"
+	mstore "github.com/micro/go-micro/v2/store"
+)
+
+type storeKey struct{}
+
+// Store sets the store the responses are kept in
+func Store(s mstore.Store) client.CacheOption {
+	return func(o *client.CacheOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, storeKey{}, s)
+	}
+}
@@ -0,0 +1,149 @@
+// Package store is a client response cache kept in a store so it can be shared between replicas
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	mjson "github.com/micro/go-micro/v2/codec/json"
+	mstore "github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/store/memory"
+)
+
+var (
+	// prefix of the keys the responses are stored under
+	prefix = "cache/"
+)
+
+type storeCache struct {
+	opts  client.CacheOptions
+	store mstore.Store
+}
+
+// record is a cached response as written to the store
+type record struct {
+	// unix nanoseconds the response expires at
+	Expires int64 `json:"expires,omitempty"`
+	// the response encoded as json
+	Response json.RawMessage `json:"response"`
+}
+
+// NewCache returns a cache which keeps the responses in a store.
+// It defaults to the memory store if one isn't set.
+func NewCache(opts ...client.CacheOption) client.Cache {
+	c := new(storeCache)
+	c.configure(opts...)
+	return c
+}
+
+func (c *storeCache) configure(opts ...client.CacheOption) {
+	for _, o := range opts {
+		o(&c.opts)
+	}
+
+	if ctx := c.opts.Context; ctx != nil {
+		if s, ok := ctx.Value(storeKey{}).(mstore.Store); ok {
+			c.store = s
+		}
+	}
+
+	if c.store == nil {
+		c.store = memory.NewStore()
+	}
+}
+
+func (c *storeCache) Init(opts ...client.CacheOption) error {
+	c.configure(opts...)
+	return nil
+}
+
+func (c *storeCache) Options() client.CacheOptions {
+	return c.opts
+}
+
+func (c *storeCache) Get(ctx context.Context, req *client.Request, rsp interface{}) (bool, bool) {
+	recs, err := c.store.Read(prefix + client.CacheKey(ctx, req))
+	if err != nil || len(recs) == 0 {
+		return false, false
+	}
+
+	var r record
+	if err := json.Unmarshal(recs[0].Value, &r); err != nil {
+		return false, false
+	}
+
+	if err := (mjson.Marshaler{}).Unmarshal(r.Response, rsp); err != nil {
+		return false, false
+	}
+
+	return r.Expires > 0 && time.Now().UnixNano() > r.Expires, true
+}
+
+func (c *storeCache) Set(ctx context.Context, req *client.Request, rsp interface{}, expiry, stale time.Duration) error {
+	b, err := mjson.Marshaler{}.Marshal(rsp)
+	if err != nil {
+		return err
+	}
+
+	// the marshaler reuses its buffers
+	r := record{Response: append(json.RawMessage(nil), b...)}
+	if expiry > 0 {
+		r.Expires = time.Now().Add(expiry).UnixNano()
+		expiry += stale
+	}
+
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Write(&mstore.Record{
+		Key:    prefix + client.CacheKey(ctx, req),
+		Value:  v,
+		Expiry: expiry,
+	})
+}
+
+func (c *storeCache) Invalidate(service, endpoint string) error {
+	keys, err := c.store.List(mstore.ListPrefix(prefix + client.CacheKeyPrefix(service, endpoint)))
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := c.store.Delete(k); err != nil && err != mstore.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *storeCache) List() map[string]string {
+	rsp := make(map[string]string)
+
+	keys, err := c.store.List(mstore.ListPrefix(prefix))
+	if err != nil {
+		return rsp
+	}
+
+	for _, k := range keys {
+		recs, err := c.store.Read(k)
+		if err != nil || len(recs) == 0 {
+			continue
+		}
+		var r record
+		if err := json.Unmarshal(recs[0].Value, &r); err != nil {
+			continue
+		}
+		rsp[k[len(prefix):]] = string(r.Response)
+	}
+
+	return rsp
+}
+
+func (c *storeCache) String() string {
+	return "store"
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/store/memory"
+)
+
+type testRsp struct {
+	Value string `json:"value"`
+}
+
+func TestStoreCache(t *testing.T) {
+	ctx := context.TODO()
+	req1 := client.NewRequest("go.micro.service.foo", "Foo.Bar", nil)
+	req2 := client.NewRequest("go.micro.service.foo", "Foo.Baz", nil)
+
+	s := memory.NewStore()
+
+	// replicas sharing the store see each other's responses
+	c1 := NewCache(Store(s))
+	c2 := NewCache(Store(s))
+
+	if err := c1.Set(ctx, &req1, &testRsp{Value: "bar"}, time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c1.Set(ctx, &req2, &testRsp{Value: "baz"}, time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	rsp := new(testRsp)
+	if stale, ok := c2.Get(ctx, &req1, rsp); !ok || stale || rsp.Value != "bar" {
+		t.Fatalf("Expected bar got %v %v %v", rsp, stale, ok)
+	}
+
+	if vals := c2.List(); len(vals) != 2 || vals[client.CacheKey(ctx, &req2)] != `{"value":"baz"}` {
+		t.Fatalf("Unexpected list %v", vals)
+	}
+
+	if err := c2.Invalidate("go.micro.service.foo", "Foo.Bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c1.Get(ctx, &req1, new(testRsp)); ok {
+		t.Fatal("Expected Foo.Bar to be invalidated")
+	}
+	if _, ok := c1.Get(ctx, &req2, new(testRsp)); !ok {
+		t.Fatal("Expected Foo.Baz to be cached")
+	}
+
+	if err := c2.Invalidate("go.micro.service.foo", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c1.Get(ctx, &req2, new(testRsp)); ok {
+		t.Fatal("Expected Foo.Baz to be invalidated")
+	}
+}
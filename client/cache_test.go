@@ -2,18 +2,23 @@ package client
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/micro/go-micro/v2/metadata"
 )
 
+type testCacheRsp struct {
+	Value string
+}
+
 func TestCache(t *testing.T) {
 	ctx := context.TODO()
 	req := NewRequest("go.micro.service.foo", "Foo.Bar", nil)
 
 	t.Run("CacheMiss", func(t *testing.T) {
-		if _, ok := NewCache().Get(ctx, &req); ok {
+		if _, ok := NewCache().Get(ctx, &req, new(testCacheRsp)); ok {
 			t.Errorf("Expected to get no result from Get")
 		}
 	})
@@ -21,15 +26,178 @@ func TestCache(t *testing.T) {
 	t.Run("CacheHit", func(t *testing.T) {
 		c := NewCache()
 
-		rsp := "theresponse"
-		c.Set(ctx, &req, rsp, time.Minute)
+		rsp := &testCacheRsp{Value: "theresponse"}
+		c.Set(ctx, &req, rsp, time.Minute, 0)
 
-		if res, ok := c.Get(ctx, &req); !ok {
+		res := new(testCacheRsp)
+		if stale, ok := c.Get(ctx, &req, res); !ok || stale {
 			t.Errorf("Expected a result, got nothing")
-		} else if res != rsp {
+		} else if res.Value != rsp.Value {
 			t.Errorf("Expected '%v' result, got '%v'", rsp, res)
 		}
 	})
+
+	t.Run("CacheStale", func(t *testing.T) {
+		c := NewCache()
+		c.Set(ctx, &req, &testCacheRsp{Value: "theresponse"}, time.Millisecond*10, time.Minute)
+		time.Sleep(time.Millisecond * 20)
+
+		res := new(testCacheRsp)
+		if stale, ok := c.Get(ctx, &req, res); !ok || !stale || res.Value != "theresponse" {
+			t.Errorf("Expected a stale response got %v %v %v", res, stale, ok)
+		}
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		c := NewCache()
+		other := NewRequest("go.micro.service.foo", "Foo.Baz", nil)
+
+		c.Set(ctx, &req, &testCacheRsp{Value: "bar"}, time.Minute, 0)
+		c.Set(ctx, &other, &testCacheRsp{Value: "baz"}, time.Minute, 0)
+
+		c.Invalidate("go.micro.service.foo", "Foo.Bar")
+		if _, ok := c.Get(ctx, &req, new(testCacheRsp)); ok {
+			t.Errorf("Expected Foo.Bar to be invalidated")
+		}
+		if _, ok := c.Get(ctx, &other, new(testCacheRsp)); !ok {
+			t.Errorf("Expected Foo.Baz to be cached")
+		}
+
+		c.Invalidate("go.micro.service.foo", "")
+		if _, ok := c.Get(ctx, &other, new(testCacheRsp)); ok {
+			t.Errorf("Expected Foo.Baz to be invalidated")
+		}
+	})
+}
+
+func TestCacheRules(t *testing.T) {
+	opts := CacheOptions{}
+	CacheTTL("go.micro.service.foo", "", time.Minute)(&opts)
+	CacheTTL("go.micro.service.foo", "Foo.Bar", time.Second)(&opts)
+
+	for _, tc := range []struct {
+		service  string
+		endpoint string
+		expiry   time.Duration
+	}{
+		{"go.micro.service.foo", "Foo.Bar", time.Second},
+		{"go.micro.service.foo", "Foo.Baz", time.Minute},
+		{"go.micro.service.bar", "Foo.Bar", 0},
+	} {
+		req := NewRequest(tc.service, tc.endpoint, nil)
+		if d := opts.Expiry(req); d != tc.expiry {
+			t.Errorf("Expected %s %s to expire after %v got %v", tc.service, tc.endpoint, tc.expiry, d)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	ctx := context.TODO()
+	req := NewRequest("go.micro.service.foo", "Foo.Bar", nil)
+
+	g := new(Group)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var calls, shared int
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i > 0 {
+				<-started
+			}
+			rsp, s, err := g.Do(ctx, &req, func(context.Context) (interface{}, error) {
+				mtx.Lock()
+				calls++
+				mtx.Unlock()
+				close(started)
+				<-release
+				return "theresponse", nil
+			})
+			if err != nil || rsp != "theresponse" {
+				t.Errorf("Expected theresponse got %v %v", rsp, err)
+			}
+			if s {
+				mtx.Lock()
+				shared++
+				mtx.Unlock()
+			}
+		}(i)
+	}
+
+	<-started
+	// give the waiters time to join the call
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 || shared != 4 {
+		t.Errorf("Expected 1 call shared 4 times got %d calls shared %d times", calls, shared)
+	}
+}
+
+// joinCtx signals once a caller waits on the call in flight
+type joinCtx struct {
+	context.Context
+	once   sync.Once
+	joined chan struct{}
+}
+
+func (c *joinCtx) Done() <-chan struct{} {
+	c.once.Do(func() { close(c.joined) })
+	return c.Context.Done()
+}
+
+func TestGroupCancel(t *testing.T) {
+	req := NewRequest("go.micro.service.foo", "Foo.Bar", nil)
+
+	g := new(Group)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	// the first caller gives up while the call is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(ctx, &req, func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return "theresponse", ctx.Err()
+		})
+		errs <- err
+	}()
+
+	<-started
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("Expected the first caller to be cancelled got %v", err)
+	}
+
+	// another caller joins the call which is still in flight
+	wctx := &joinCtx{Context: context.Background(), joined: make(chan struct{})}
+	type result struct {
+		rsp    interface{}
+		shared bool
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		rsp, shared, err := g.Do(wctx, &req, func(context.Context) (interface{}, error) {
+			return nil, nil
+		})
+		results <- result{rsp, shared, err}
+	}()
+
+	<-wctx.joined
+	close(release)
+
+	if r := <-results; r.err != nil || !r.shared || r.rsp != "theresponse" {
+		t.Fatalf("Expected the shared response got %+v", r)
+	}
 }
 
 func TestCacheKey(t *testing.T) {
@@ -39,16 +207,16 @@ func TestCacheKey(t *testing.T) {
 	req3 := NewRequest("go.micro.service.foo", "Foo.Baz", "customquery")
 
 	t.Run("IdenticalRequests", func(t *testing.T) {
-		key1 := key(ctx, &req1)
-		key2 := key(ctx, &req1)
+		key1 := CacheKey(ctx, &req1)
+		key2 := CacheKey(ctx, &req1)
 		if key1 != key2 {
 			t.Errorf("Expected the keys to match for identical requests and context")
 		}
 	})
 
 	t.Run("DifferentRequestEndpoints", func(t *testing.T) {
-		key1 := key(ctx, &req1)
-		key2 := key(ctx, &req2)
+		key1 := CacheKey(ctx, &req1)
+		key2 := CacheKey(ctx, &req2)
 
 		if key1 == key2 {
 			t.Errorf("Expected the keys to differ for different request endpoints")
@@ -56,8 +224,8 @@ func TestCacheKey(t *testing.T) {
 	})
 
 	t.Run("DifferentRequestBody", func(t *testing.T) {
-		key1 := key(ctx, &req2)
-		key2 := key(ctx, &req3)
+		key1 := CacheKey(ctx, &req2)
+		key2 := CacheKey(ctx, &req3)
 
 		if key1 == key2 {
 			t.Errorf("Expected the keys to differ for different request bodies")
@@ -66,8 +234,8 @@ func TestCacheKey(t *testing.T) {
 
 	t.Run("DifferentMetadata", func(t *testing.T) {
 		mdCtx := metadata.Set(context.TODO(), "Micro-Namespace", "bar")
-		key1 := key(mdCtx, &req1)
-		key2 := key(ctx, &req1)
+		key1 := CacheKey(mdCtx, &req1)
+		key2 := CacheKey(ctx, &req1)
 
 		if key1 == key2 {
 			t.Errorf("Expected the keys to differ for different metadata")
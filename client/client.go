@@ -110,6 +110,10 @@ var (
 	DefaultPoolSize = 100
 	// DefaultPoolTTL sets the connection pool ttl
 	DefaultPoolTTL = time.Duration(1<<63 - 1) // maxDuration
+	// HashKeyHeader is the metadata header the consistent hash key is read from
+	HashKeyHeader = "Micro-Hash-Key"
+	// PriorityHeader is the metadata header carrying the priority of a call
+	PriorityHeader = "Micro-Priority"
 
 	// NewClient returns a new client
 	NewClient func(...Option) Client = newRpcClient
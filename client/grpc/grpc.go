@@ -73,7 +73,7 @@ func (g *grpcClient) secure(addr string) grpc.DialOption {
 	return grpc.WithInsecure()
 }
 
-func (g *grpcClient) next(request client.Request, opts client.CallOptions) (selector.Next, error) {
+func (g *grpcClient) next(ctx context.Context, request client.Request, opts client.CallOptions) (selector.Next, error) {
 	service, address, _ := pnet.Proxy(request.Service(), opts.Address)
 
 	// return remote address
@@ -85,12 +85,21 @@ func (g *grpcClient) next(request client.Request, opts client.CallOptions) (sele
 		}, nil
 	}
 
+	// a hash key in the metadata is overridden by one in the call options
+	sopts := opts.SelectOptions
+	if key, ok := metadata.Get(ctx, client.HashKeyHeader); ok {
+		sopts = append([]selector.SelectOption{selector.WithKey(key)}, sopts...)
+	}
+
 	// get next nodes from the selector
-	next, err := g.opts.Selector.Select(service, opts.SelectOptions...)
+	next, err := g.opts.Selector.Select(service, sopts...)
 	if err != nil {
 		if err == selector.ErrNotFound {
 			return nil, errors.InternalServerError("go.micro.client", "service %s: %s", service, err.Error())
 		}
+		if err == selector.ErrCircuitOpen {
+			return nil, errors.New("go.micro.client", fmt.Sprintf("service %s: %s", service, err.Error()), 503)
+		}
 		return nil, errors.InternalServerError("go.micro.client", "error selecting %s node: %s", service, err.Error())
 	}
 
@@ -114,6 +123,17 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 
 	// set timeout in nanoseconds
 	header["timeout"] = fmt.Sprintf("%d", opts.RequestTimeout)
+
+	// pass along what's left of the deadline
+	if d, ok := ctx.Deadline(); ok {
+		header["micro-deadline"] = fmt.Sprintf("%d", time.Until(d))
+	}
+
+	// let the server know how important the request is
+	if opts.Priority > 0 {
+		header[strings.ToLower(client.PriorityHeader)] = fmt.Sprintf("%d", opts.Priority)
+	}
+
 	// set the content type for the request
 	header["x-content-type"] = req.ContentType()
 
@@ -161,8 +181,11 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 		if opts := g.getGrpcCallOptions(); opts != nil {
 			grpcCallOptions = append(grpcCallOptions, opts...)
 		}
+		// read the trailer for any retry hint
+		var trailer gmetadata.MD
+		grpcCallOptions = append(grpcCallOptions, grpc.Trailer(&trailer))
 		err := cc.Invoke(ctx, methodToGRPC(req.Service(), req.Endpoint()), req.Body(), rsp, grpcCallOptions...)
-		ch <- microError(err)
+		ch <- client.RetryHint(microError(err), retryHeader(trailer))
 	}()
 
 	select {
@@ -175,6 +198,17 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 	return grr
 }
 
+// retryHeader returns the retry hint headers from the trailer
+func retryHeader(md gmetadata.MD) map[string]string {
+	hdr := make(map[string]string)
+	for _, k := range []string{"micro-retry", "micro-retry-after"} {
+		if v := md.Get(k); len(v) > 0 {
+			hdr[k] = v[0]
+		}
+	}
+	return hdr
+}
+
 func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
 	var header map[string]string
 
@@ -193,6 +227,17 @@ func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client
 	if opts.StreamTimeout > time.Duration(0) {
 		header["timeout"] = fmt.Sprintf("%d", opts.StreamTimeout)
 	}
+
+	// pass along what's left of the deadline
+	if d, ok := ctx.Deadline(); ok {
+		header["micro-deadline"] = fmt.Sprintf("%d", time.Until(d))
+	}
+
+	// let the server know how important the request is
+	if opts.Priority > 0 {
+		header[strings.ToLower(client.PriorityHeader)] = fmt.Sprintf("%d", opts.Priority)
+	}
+
 	// set the content type for the request
 	header["x-content-type"] = req.ContentType()
 
@@ -391,7 +436,7 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 		opt(&callOpts)
 	}
 
-	next, err := g.next(req, callOpts)
+	next, err := g.next(ctx, req, callOpts)
 	if err != nil {
 		return err
 	}
@@ -417,6 +462,11 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 	default:
 	}
 
+	// don't call at all with too little time left for the server
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+		return errors.Timeout("go.micro.client", "deadline too close to call: %v left", time.Until(d))
+	}
+
 	// make copy of call method
 	gcall := g.call
 
@@ -425,6 +475,9 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 		gcall = callOpts.CallWrappers[i-1](gcall)
 	}
 
+	// time the server asked us to wait before retrying
+	var wait time.Duration
+
 	// return errors.New("go.micro.client", "request timeout", 408)
 	call := func(i int) error {
 		// call backoff first. Someone may want an initial start delay
@@ -433,6 +486,11 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 			return errors.InternalServerError("go.micro.client", err.Error())
 		}
 
+		// wait at least as long as the server asked
+		if t < wait {
+			t = wait
+		}
+
 		// only sleep if greater than 0
 		if t.Seconds() > 0 {
 			time.Sleep(t)
@@ -458,6 +516,11 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 		return err
 	}
 
+	// record the request against the retry budget
+	if g.opts.Budget != nil {
+		g.opts.Budget.Request()
+	}
+
 	ch := make(chan error, callOpts.Retries+1)
 	var gerr error
 
@@ -486,6 +549,17 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 				return err
 			}
 
+			// don't retry once the budget is spent
+			if i < callOpts.Retries && g.opts.Budget != nil && !g.opts.Budget.Retry() {
+				return err
+			}
+
+			// don't retry with too little time left for the server
+			if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+				return err
+			}
+
+			wait, _ = client.RetryAfter(err)
 			gerr = err
 		}
 	}
@@ -500,7 +574,7 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 		opt(&callOpts)
 	}
 
-	next, err := g.next(req, callOpts)
+	next, err := g.next(ctx, req, callOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -514,6 +588,11 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 	default:
 	}
 
+	// don't stream at all with too little time left for the server
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+		return nil, errors.Timeout("go.micro.client", "deadline too close to stream: %v left", time.Until(d))
+	}
+
 	// make a copy of stream
 	gstream := g.stream
 
@@ -571,7 +650,12 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 		case rsp := <-ch:
 			// if the call succeeded lets bail early
 			if rsp.err == nil {
-				return rsp.stream, nil
+				stream := rsp.stream
+				// wrap the stream
+				for i := len(callOpts.StreamWrappers); i > 0; i-- {
+					stream = callOpts.StreamWrappers[i-1](stream)
+				}
+				return stream, nil
 			}
 
 			retry, rerr := callOpts.Retry(ctx, req, i, err)
@@ -583,6 +667,11 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 				return nil, rsp.err
 			}
 
+			// don't retry with too little time left for the server
+			if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+				return nil, rsp.err
+			}
+
 			grr = rsp.err
 		}
 	}
@@ -636,10 +725,15 @@ func (g *grpcClient) Publish(ctx context.Context, p client.Message, opts ...clie
 		topic = options.Exchange
 	}
 
+	pubOpts := []broker.PublishOption{broker.PublishContext(options.Context)}
+	if len(options.OrderingKey) > 0 {
+		pubOpts = append(pubOpts, broker.OrderingKey(options.OrderingKey))
+	}
+
 	return g.opts.Broker.Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
-	}, broker.PublishContext(options.Context))
+	}, pubOpts...)
 }
 
 func (g *grpcClient) String() string {
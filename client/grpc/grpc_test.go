@@ -5,6 +5,8 @@ import (
 	"net"
 	"testing"
 
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/client/selector"
 	"github.com/micro/go-micro/v2/errors"
@@ -110,3 +112,32 @@ func TestGRPCClient(t *testing.T) {
 	}
 
 }
+
+// keyBroker records the ordering keys of the published messages
+type keyBroker struct {
+	broker.Broker
+	keys []string
+}
+
+func (b *keyBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	b.keys = append(b.keys, options.OrderingKey)
+	return nil
+}
+
+func TestGRPCPublishOrderingKey(t *testing.T) {
+	b := &keyBroker{Broker: bmemory.NewBroker()}
+	c := NewClient(client.Broker(b), client.Registry(memory.NewRegistry()))
+
+	msg := c.NewMessage("test", &pb.HelloRequest{Name: "John"})
+	if err := c.Publish(context.TODO(), msg, client.WithOrderingKey("key")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b.keys) != 1 || b.keys[0] != "key" {
+		t.Fatalf("Expected the ordering key to be passed to the broker got %q", b.keys)
+	}
+}
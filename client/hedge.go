@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/client/selector"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var (
+	// latencySamples is the number of recent latencies kept per endpoint
+	latencySamples = 100
+	// minLatencySamples is the number of samples needed to use a percentile
+	minLatencySamples = 20
+)
+
+// latencies keeps a window of recent successful call latencies per endpoint
+type latencies struct {
+	sync.Mutex
+	endpoints map[string]*samples
+}
+
+type samples struct {
+	values []time.Duration
+	next   int
+}
+
+func newLatencies() *latencies {
+	return &latencies{
+		endpoints: make(map[string]*samples),
+	}
+}
+
+func (l *latencies) record(endpoint string, d time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	s, ok := l.endpoints[endpoint]
+	if !ok {
+		s = new(samples)
+		l.endpoints[endpoint] = s
+	}
+
+	if len(s.values) < latencySamples {
+		s.values = append(s.values, d)
+		return
+	}
+
+	s.values[s.next] = d
+	s.next = (s.next + 1) % latencySamples
+}
+
+// percentile returns the latency at the percentile p between 0 and 1
+func (l *latencies) percentile(endpoint string, p float64) (time.Duration, bool) {
+	l.Lock()
+	s, ok := l.endpoints[endpoint]
+	if !ok || len(s.values) < minLatencySamples {
+		l.Unlock()
+		return 0, false
+	}
+	values := make([]time.Duration, len(s.values))
+	copy(values, s.values)
+	l.Unlock()
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	i := int(p * float64(len(values)))
+	if i >= len(values) {
+		i = len(values) - 1
+	}
+	return values[i], true
+}
+
+// hedgeDelay returns how long to wait before sending a hedged request
+func (r *rpcClient) hedgeDelay(req Request, opts CallOptions) time.Duration {
+	if opts.HedgePercentile > 0 {
+		if d, ok := r.lat.percentile(req.Service()+"."+req.Endpoint(), opts.HedgePercentile); ok {
+			return d
+		}
+	}
+	return opts.HedgeDelay
+}
+
+// hedging returns true if the call can be hedged
+func (r *rpcClient) hedging(req Request, rsp interface{}, opts CallOptions) bool {
+	if !opts.Idempotent || opts.Hedges <= 0 {
+		return false
+	}
+	// we need a pointer to decode each response into
+	if rsp == nil || reflect.TypeOf(rsp).Kind() != reflect.Ptr {
+		return false
+	}
+	return r.hedgeDelay(req, opts) > 0
+}
+
+// hedge makes the call and sends the same request to another node each time
+// the hedge delay passes without a response, up to opts.Hedges extra requests.
+// The first successful response wins and the other requests are cancelled.
+func (r *rpcClient) hedge(ctx context.Context, next selector.Next, rcall CallFunc, req Request, rsp interface{}, opts CallOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		rsp interface{}
+		err error
+	}
+
+	service := req.Service()
+	endpoint := service + "." + req.Endpoint()
+	typ := reflect.TypeOf(rsp).Elem()
+	ch := make(chan result, opts.Hedges+1)
+	used := make(map[string]bool)
+
+	send := func() error {
+		var node *registry.Node
+
+		// try find a node we haven't already sent to
+		for i := 0; i <= opts.Hedges; i++ {
+			n, err := next()
+			if err != nil {
+				return err
+			}
+			if !used[n.Id+n.Address] {
+				node = n
+				break
+			}
+			// hand back the node we won't call
+			selector.Release(r.opts.Selector, service, n)
+		}
+
+		if node == nil {
+			return selector.ErrNoneAvailable
+		}
+
+		used[node.Id+node.Address] = true
+
+		go func() {
+			rsp := reflect.New(typ).Interface()
+			started := time.Now()
+			err := rcall(ctx, node, req, rsp, opts)
+			// don't count hedges we cancelled against the node
+			if err != nil && ctx.Err() == context.Canceled {
+				selector.Release(r.opts.Selector, service, node)
+			} else {
+				r.opts.Selector.Mark(service, node, err)
+			}
+			if err == nil {
+				r.lat.record(endpoint, time.Since(started))
+			}
+			ch <- result{rsp, err}
+		}()
+
+		return nil
+	}
+
+	if err := send(); err != nil {
+		return errors.InternalServerError("go.micro.client", "error getting next %s node: %s", service, err.Error())
+	}
+
+	inflight := 1
+	hedges := 0
+
+	timer := time.NewTimer(r.hedgeDelay(req, opts))
+	defer timer.Stop()
+
+	var gerr error
+
+	for inflight > 0 {
+		select {
+		case res := <-ch:
+			inflight--
+			if res.err == nil {
+				reflect.ValueOf(rsp).Elem().Set(reflect.ValueOf(res.rsp).Elem())
+				return nil
+			}
+			gerr = res.err
+		case <-timer.C:
+			if hedges >= opts.Hedges {
+				continue
+			}
+			// no other node picked so try again after the delay
+			if err := send(); err != nil {
+				timer.Reset(r.hedgeDelay(req, opts))
+				continue
+			}
+			hedges++
+			inflight++
+			timer.Reset(r.hedgeDelay(req, opts))
+		case <-ctx.Done():
+			return errors.Timeout("go.micro.client", fmt.Sprintf("%v", ctx.Err()))
+		}
+	}
+
+	return gerr
+}
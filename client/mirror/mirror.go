@@ -0,0 +1,265 @@
+// Package mirror sends a copy of client calls to a shadow service
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/client/selector"
+	dlog "github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+var (
+	// ShadowHeader marks the calls which are mirrored. Shadow
+	// services can use it to avoid side effects.
+	ShadowHeader = "Micro-Shadow"
+
+	// DefaultTimeout is the timeout of the mirrored calls
+	DefaultTimeout = time.Second * 5
+	// DefaultMaxInflight is the number of mirrored calls in flight over which more are dropped
+	DefaultMaxInflight = 100
+)
+
+// Diff is how the response of a mirrored call differs from the primary one
+type Diff struct {
+	// Service and Endpoint called
+	Service  string `json:"service"`
+	Endpoint string `json:"endpoint"`
+	// Fields are the paths of the fields which differ e.g items[1].id
+	Fields []string `json:"fields,omitempty"`
+	// Primary and Shadow are the JSON encoded responses
+	Primary json.RawMessage `json:"primary,omitempty"`
+	Shadow  json.RawMessage `json:"shadow,omitempty"`
+	// PrimaryError and ShadowError are set when the calls failed
+	PrimaryError string `json:"primary_error,omitempty"`
+	ShadowError  string `json:"shadow_error,omitempty"`
+}
+
+// Shadow returns a context for a mirrored call. It has the metadata of the
+// call marked with the shadow header but isn't cancelled along with it.
+func Shadow(ctx context.Context) context.Context {
+	md, _ := metadata.FromContext(ctx)
+	md = metadata.Copy(md)
+	md[ShadowHeader] = "true"
+	return metadata.NewContext(context.Background(), md)
+}
+
+// IsShadow returns true if the context is of a mirrored call
+func IsShadow(ctx context.Context) bool {
+	v, ok := metadata.Get(ctx, ShadowHeader)
+	return ok && v == "true"
+}
+
+// Log writes the diff to the debug log
+func Log(d Diff) {
+	dlog.DefaultLog.Write(dlog.Record{
+		Timestamp: time.Now(),
+		Metadata: map[string]string{
+			"mirror":   d.Service,
+			"endpoint": d.Endpoint,
+		},
+		Message: d,
+	})
+}
+
+// shadowCall turns off what could serve a mirrored call without sending it
+func shadowCall(o *client.CallOptions) {
+	o.CacheExpiry = 0
+	o.Coalesce = false
+	o.Hedges = 0
+}
+
+type result struct {
+	rsp []byte
+	err error
+}
+
+// call is the response of the primary call before it's encoded
+type call struct {
+	rsp interface{}
+	err error
+}
+
+// encode returns the result of the call with the response encoded
+func (c call) encode() result {
+	if c.err != nil {
+		return result{err: c.err}
+	}
+	b, _ := json.Marshal(c.rsp)
+	return result{rsp: b}
+}
+
+type mirrorWrapper struct {
+	client.Client
+	opts Options
+
+	// mirrored calls in flight
+	inflight int64
+}
+
+func (m *mirrorWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	// the mirrored call needs a response of its own
+	t := reflect.TypeOf(rsp)
+	if t == nil || t.Kind() != reflect.Ptr || IsShadow(ctx) || rand.Float64() >= m.opts.Share {
+		return m.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	// never hold up the primary call, drop the copy if too many are in flight
+	if atomic.AddInt64(&m.inflight, 1) > int64(m.opts.MaxInflight) {
+		atomic.AddInt64(&m.inflight, -1)
+		return m.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	primary := make(chan call, 1)
+	go m.mirror(ctx, req, reflect.New(t.Elem()).Interface(), opts, primary)
+
+	err := m.Client.Call(ctx, req, rsp, opts...)
+
+	// the response is encoded by the mirror to keep it off the call
+	if m.opts.Compare != nil {
+		primary <- call{rsp, err}
+	}
+
+	return err
+}
+
+// mirror sends the call to the shadow and compares the responses
+func (m *mirrorWrapper) mirror(ctx context.Context, req client.Request, rsp interface{}, opts []client.CallOption, primary chan call) {
+	defer atomic.AddInt64(&m.inflight, -1)
+
+	sctx, cancel := context.WithTimeout(Shadow(ctx), m.opts.Timeout)
+	defer cancel()
+
+	sreq := req
+	if len(m.opts.Service) > 0 && m.opts.Service != req.Service() {
+		sreq = m.Client.NewRequest(m.opts.Service, req.Endpoint(), req.Body(), client.WithContentType(req.ContentType()))
+	}
+
+	opts = append(opts[:len(opts):len(opts)], shadowCall)
+	if len(m.opts.Version) > 0 {
+		opts = append(opts, client.WithSelectOption(selector.WithFilter(selector.FilterVersion(m.opts.Version))))
+	}
+
+	err := m.Client.Call(sctx, sreq, rsp, opts...)
+	if err != nil && logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("mirror: call to %s failed: %v", sreq.Service(), err)
+	}
+
+	if m.opts.Compare == nil {
+		return
+	}
+
+	if d, ok := diff((<-primary).encode(), call{rsp, err}.encode()); ok {
+		d.Service = req.Service()
+		d.Endpoint = req.Endpoint()
+		m.opts.Compare(d)
+	}
+}
+
+// diff compares the results returning false if they're the same
+func diff(primary, shadow result) (Diff, bool) {
+	d := Diff{
+		Primary: primary.rsp,
+		Shadow:  shadow.rsp,
+	}
+	if primary.err != nil {
+		d.PrimaryError = primary.err.Error()
+	}
+	if shadow.err != nil {
+		d.ShadowError = shadow.err.Error()
+	}
+
+	if primary.err != nil || shadow.err != nil {
+		return d, d.PrimaryError != d.ShadowError
+	}
+
+	var p, s interface{}
+	json.Unmarshal(primary.rsp, &p)
+	json.Unmarshal(shadow.rsp, &s)
+
+	d.Fields = fields("", p, s)
+	return d, len(d.Fields) > 0
+}
+
+// fields returns the paths of the values which differ
+func fields(path string, a, b interface{}) []string {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]bool, len(av)+len(bv))
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+
+		var sorted []string
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var diffs []string
+		for _, k := range sorted {
+			p := k
+			if len(path) > 0 {
+				p = path + "." + k
+			}
+			diffs = append(diffs, fields(p, av[k], bv[k])...)
+		}
+		return diffs
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			break
+		}
+
+		var diffs []string
+		for i := range av {
+			diffs = append(diffs, fields(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i])...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []string{path}
+}
+
+// NewClientWrapper returns a client wrapper which mirrors calls to a shadow
+// service or version. The mirrored calls are marked with the shadow header
+// and sent without waiting for them. Their responses are discarded unless
+// compared with the primary ones.
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	options := Options{
+		Share:       1,
+		Timeout:     DefaultTimeout,
+		MaxInflight: DefaultMaxInflight,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return func(c client.Client) client.Client {
+		return &mirrorWrapper{
+			Client: c,
+			opts:   options,
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package mirror
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+type testRequest struct {
+	client.Request
+	service string
+}
+
+func (r *testRequest) Service() string     { return r.service }
+func (r *testRequest) Endpoint() string    { return "Foo.Bar" }
+func (r *testRequest) ContentType() string { return "application/json" }
+func (r *testRequest) Body() interface{}   { return nil }
+
+// testClient answers with the name of the service called, the shadow fails
+type testClient struct {
+	client.Client
+
+	sync.Mutex
+	shadows int
+	block   chan bool
+}
+
+func (c *testClient) NewRequest(service, endpoint string, req interface{}, opts ...client.RequestOption) client.Request {
+	return &testRequest{service: service}
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	m := rsp.(*map[string]string)
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+
+	if !IsShadow(ctx) {
+		(*m)["name"] = req.Service()
+		return nil
+	}
+
+	c.Lock()
+	c.shadows++
+	c.Unlock()
+
+	if c.block != nil {
+		<-c.block
+	}
+
+	(*m)["name"] = req.Service()
+	return nil
+}
+
+func TestMirror(t *testing.T) {
+	c := &testClient{block: make(chan bool)}
+	diffs := make(chan Diff, 1)
+
+	w := NewClientWrapper(Service("foo.shadow"), Compare(func(d Diff) {
+		diffs <- d
+	}))(c)
+
+	rsp := map[string]string{}
+	if err := w.Call(context.TODO(), &testRequest{service: "foo"}, &rsp); err != nil {
+		t.Fatal(err)
+	}
+
+	// the primary call returns while the shadow is blocked
+	if rsp["name"] != "foo" {
+		t.Fatalf("Expected the primary response got %v", rsp)
+	}
+	close(c.block)
+
+	select {
+	case d := <-diffs:
+		if !reflect.DeepEqual(d.Fields, []string{"name"}) || d.Endpoint != "Foo.Bar" {
+			t.Fatalf("Unexpected diff %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a diff")
+	}
+}
+
+func TestMirrorShare(t *testing.T) {
+	c := &testClient{}
+	w := NewClientWrapper(Share(0))(c)
+
+	for i := 0; i < 10; i++ {
+		if err := w.Call(context.TODO(), &testRequest{service: "foo"}, &map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.shadows != 0 {
+		t.Fatalf("Expected no calls to be mirrored got %d", c.shadows)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	testData := []struct {
+		primary, shadow result
+		fields          []string
+		differ          bool
+	}{
+		{result{rsp: []byte(`{"a": 1, "b": [1, 2]}`)}, result{rsp: []byte(`{"a": 1, "b": [1, 2]}`)}, nil, false},
+		{result{rsp: []byte(`{"a": 1, "b": [1, 2]}`)}, result{rsp: []byte(`{"a": 2, "b": [1, 3]}`)}, []string{"a", "b[1]"}, true},
+		{result{rsp: []byte(`{"a": {"b": 1}}`)}, result{rsp: []byte(`{"a": {"c": 1}}`)}, []string{"a.b", "a.c"}, true},
+		{result{rsp: []byte(`{"a": 1}`)}, result{err: errors.InternalServerError("foo", "bad")}, nil, true},
+		{result{err: errors.NotFound("foo", "none")}, result{err: errors.NotFound("foo", "none")}, nil, false},
+	}
+
+	for _, d := range testData {
+		diff, differ := diff(d.primary, d.shadow)
+		if differ != d.differ || !reflect.DeepEqual(diff.Fields, d.fields) {
+			t.Fatalf("Expected %v %v got %v %v", d.differ, d.fields, differ, diff.Fields)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package mirror
+
+import (
+	"time"
+)
+
+// Options configure the mirroring of calls
+type Options struct {
+	// Service the calls are mirrored to, the one called if blank
+	Service string
+	// Version of the service the calls are mirrored to, any if blank
+	Version string
+	// Share is the share of calls between 0 and 1 which are mirrored
+	Share float64
+	// Timeout of the mirrored calls
+	Timeout time.Duration
+	// MaxInflight is the number of mirrored calls in flight
+	// over which more are dropped
+	MaxInflight int
+	// Compare reports how the responses of the mirrored calls differ
+	// from the primary ones. Nil if the responses aren't compared.
+	Compare func(Diff)
+}
+
+// Option sets a mirror option
+type Option func(*Options)
+
+// Service sets the service the calls are mirrored to
+func Service(name string) Option {
+	return func(o *Options) {
+		o.Service = name
+	}
+}
+
+// Version sets the version of the service the calls are mirrored to
+func Version(v string) Option {
+	return func(o *Options) {
+		o.Version = v
+	}
+}
+
+// Share sets the share of calls between 0 and 1 which are mirrored
+func Share(s float64) Option {
+	return func(o *Options) {
+		o.Share = s
+	}
+}
+
+// Timeout sets the timeout of the mirrored calls
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// MaxInflight sets the number of mirrored calls in flight over which more are dropped
+func MaxInflight(n int) Option {
+	return func(o *Options) {
+		o.MaxInflight = n
+	}
+}
+
+// Compare compares the responses of the mirrored calls with the primary
+// ones, reporting the diffs to fn. Use Log to write them to the debug log.
+// The primary response is encoded to JSON by the mirror after its call
+// returns so it mustn't be modified by the caller.
+func Compare(fn func(Diff)) Option {
+	return func(o *Options) {
+		o.Compare = fn
+	}
+}
@@ -30,7 +30,10 @@ type Options struct {
 	PoolTTL  time.Duration
 
 	// Response cache
-	Cache *Cache
+	Cache Cache
+
+	// Budget limits the retries made by the client
+	Budget *Budget
 
 	// Middleware for client
 	Wrappers []Wrapper
@@ -60,13 +63,36 @@ type CallOptions struct {
 	RequestTimeout time.Duration
 	// Stream timeout for the stream
 	StreamTimeout time.Duration
+	// Minimum time left before the deadline to make a call
+	MinDeadline time.Duration
 	// Use the services own auth token
 	ServiceToken bool
 	// Duration to cache the response for
 	CacheExpiry time.Duration
+	// Duration an expired response can be served while it's revalidated
+	CacheStale time.Duration
+	// Share one call between concurrent identical requests
+	Coalesce bool
+	// Idempotent marks the call as safe to send more than once
+	Idempotent bool
+	// Max number of hedged requests sent for idempotent calls
+	Hedges int
+	// Time to wait for a response before sending a hedged request
+	HedgeDelay time.Duration
+	// Percentile of observed endpoint latency to hedge after e.g 0.95
+	HedgePercentile float64
+	// Priority of the call, servers under pressure shed the lowest first
+	Priority int
+	// Number of stream messages buffered before the server has to wait,
+	// flow control is off if zero
+	StreamWindow int
+	// Time a stream Send waits for the server to consume, forever if zero
+	StreamSendTimeout time.Duration
 
 	// Middleware for low level call func
 	CallWrappers []CallWrapper
+	// StreamWrappers wrap the streams returned by Stream
+	StreamWrappers []StreamWrapper
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -76,6 +102,8 @@ type CallOptions struct {
 type PublishOptions struct {
 	// Exchange is the routing exchange for the message
 	Exchange string
+	// OrderingKey of the message, see broker.OrderingKey
+	OrderingKey string
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -97,6 +125,7 @@ type RequestOptions struct {
 func NewOptions(options ...Option) Options {
 	opts := Options{
 		Cache:       NewCache(),
+		Budget:      NewBudget(DefaultRetryBudget, DefaultBudgetMinRetries, DefaultBudgetWindow),
 		Context:     context.Background(),
 		ContentType: DefaultContentType,
 		Codecs:      make(map[string]codec.NewCodec),
@@ -180,6 +209,13 @@ func Selector(s selector.Selector) Option {
 	}
 }
 
+// ResponseCache sets the cache used for responses
+func ResponseCache(c Cache) Option {
+	return func(o *Options) {
+		o.Cache = c
+	}
+}
+
 // Adds a Wrapper to a list of options passed into the client
 func Wrap(w Wrapper) Option {
 	return func(o *Options) {
@@ -194,6 +230,22 @@ func WrapCall(cw ...CallWrapper) Option {
 	}
 }
 
+// WrapStream adds to the wrappers of the streams returned by Stream
+func WrapStream(sw ...StreamWrapper) Option {
+	return func(o *Options) {
+		o.CallOptions.StreamWrappers = append(o.CallOptions.StreamWrappers, sw...)
+	}
+}
+
+// StreamWindow turns on flow control for streams to servers which set a
+// window too. It sets the number of stream messages buffered before the
+// server has to wait for them to be consumed. Flow control is off by default.
+func StreamWindow(n int) Option {
+	return func(o *Options) {
+		o.CallOptions.StreamWindow = n
+	}
+}
+
 // Backoff is used to set the backoff function used
 // when retrying Calls
 func Backoff(fn BackoffFunc) Option {
@@ -210,6 +262,18 @@ func Retries(i int) Option {
 	}
 }
 
+// RetryBudget limits retries to the ratio of recent requests made by the
+// client on top of a minimum number. A ratio of zero or less disables it.
+func RetryBudget(ratio float64) Option {
+	return func(o *Options) {
+		if ratio <= 0 {
+			o.Budget = nil
+			return
+		}
+		o.Budget = NewBudget(ratio, DefaultBudgetMinRetries, DefaultBudgetWindow)
+	}
+}
+
 // Retry sets the retry function to be used when re-trying.
 func Retry(fn RetryFunc) Option {
 	return func(o *Options) {
@@ -232,6 +296,14 @@ func StreamTimeout(d time.Duration) Option {
 	}
 }
 
+// MinDeadline sets the minimum time which must be left
+// before the context deadline for a call to be made
+func MinDeadline(d time.Duration) Option {
+	return func(o *Options) {
+		o.CallOptions.MinDeadline = d
+	}
+}
+
 // Transport dial timeout
 func DialTimeout(d time.Duration) Option {
 	return func(o *Options) {
@@ -239,6 +311,28 @@ func DialTimeout(d time.Duration) Option {
 	}
 }
 
+// Hedges sets the max number of hedged requests sent for idempotent calls
+func Hedges(n int) Option {
+	return func(o *Options) {
+		o.CallOptions.Hedges = n
+	}
+}
+
+// HedgeDelay sets the time to wait for a response before sending a hedged request
+func HedgeDelay(d time.Duration) Option {
+	return func(o *Options) {
+		o.CallOptions.HedgeDelay = d
+	}
+}
+
+// HedgePercentile sets the percentile of observed endpoint latency to hedge after.
+// The hedge delay is used until enough calls have been observed.
+func HedgePercentile(p float64) Option {
+	return func(o *Options) {
+		o.CallOptions.HedgePercentile = p
+	}
+}
+
 // Call Options
 
 // WithExchange sets the exchange to route a message through
@@ -248,6 +342,15 @@ func WithExchange(e string) PublishOption {
 	}
 }
 
+// WithOrderingKey sets the ordering key of the message. The messages
+// sharing a key are handled one at a time in the order they were
+// published by brokers which support it, see broker.OrderingKey.
+func WithOrderingKey(key string) PublishOption {
+	return func(o *PublishOptions) {
+		o.OrderingKey = key
+	}
+}
+
 // PublishContext sets the context in publish options
 func PublishContext(ctx context.Context) PublishOption {
 	return func(o *PublishOptions) {
@@ -268,6 +371,22 @@ func WithSelectOption(so ...selector.SelectOption) CallOption {
 	}
 }
 
+// WithHashKey is a CallOption which sets the key the consistent hash
+// balancer uses to pick the same node for every call with the key
+func WithHashKey(key string) CallOption {
+	return func(o *CallOptions) {
+		o.SelectOptions = append(o.SelectOptions, selector.WithKey(key))
+	}
+}
+
+// WithPriority is a CallOption which sets the priority of the call. Servers
+// under pressure shed the calls with the lowest priority first.
+func WithPriority(p int) CallOption {
+	return func(o *CallOptions) {
+		o.Priority = p
+	}
+}
+
 // WithCallWrapper is a CallOption which adds to the existing CallFunc wrappers
 func WithCallWrapper(cw ...CallWrapper) CallOption {
 	return func(o *CallOptions) {
@@ -275,6 +394,29 @@ func WithCallWrapper(cw ...CallWrapper) CallOption {
 	}
 }
 
+// WithStreamWrapper is a CallOption which adds to the existing stream wrappers
+func WithStreamWrapper(sw ...StreamWrapper) CallOption {
+	return func(o *CallOptions) {
+		o.StreamWrappers = append(o.StreamWrappers, sw...)
+	}
+}
+
+// WithStreamWindow is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithStreamWindow(n int) CallOption {
+	return func(o *CallOptions) {
+		o.StreamWindow = n
+	}
+}
+
+// WithStreamSendTimeout is a CallOption which sets how long a stream
+// Send waits for the server to consume
+func WithStreamSendTimeout(d time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.StreamSendTimeout = d
+	}
+}
+
 // WithBackoff is a CallOption which overrides that which
 // set in Options.CallOptions
 func WithBackoff(fn BackoffFunc) CallOption {
@@ -314,6 +456,14 @@ func WithStreamTimeout(d time.Duration) CallOption {
 	}
 }
 
+// WithMinDeadline is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithMinDeadline(d time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.MinDeadline = d
+	}
+}
+
 // WithDialTimeout is a CallOption which overrides that which
 // set in Options.CallOptions
 func WithDialTimeout(d time.Duration) CallOption {
@@ -338,6 +488,54 @@ func WithCache(c time.Duration) CallOption {
 	}
 }
 
+// WithStaleCache is a CallOption which serves a cached response for up to
+// the stale duration after it expires while it's refreshed in the background
+func WithStaleCache(stale time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.CacheStale = stale
+	}
+}
+
+// WithCoalesce is a CallOption which shares one call in flight between
+// concurrent identical requests. They all receive the same result.
+func WithCoalesce() CallOption {
+	return func(o *CallOptions) {
+		o.Coalesce = true
+	}
+}
+
+// WithIdempotent is a CallOption which marks the call as safe to send
+// more than once. Only idempotent calls are hedged.
+func WithIdempotent() CallOption {
+	return func(o *CallOptions) {
+		o.Idempotent = true
+	}
+}
+
+// WithHedges is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithHedges(n int) CallOption {
+	return func(o *CallOptions) {
+		o.Hedges = n
+	}
+}
+
+// WithHedgeDelay is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithHedgeDelay(d time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.HedgeDelay = d
+	}
+}
+
+// WithHedgePercentile is a CallOption which overrides that which
+// set in Options.CallOptions
+func WithHedgePercentile(p float64) CallOption {
+	return func(o *CallOptions) {
+		o.HedgePercentile = p
+	}
+}
+
 func WithMessageContentType(ct string) MessageOption {
 	return func(o *MessageOptions) {
 		o.ContentType = ct
@@ -2,6 +2,10 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/micro/go-micro/v2/errors"
 )
@@ -9,17 +13,102 @@ import (
 // note that returning either false or a non-nil error will result in the call not being retried
 type RetryFunc func(ctx context.Context, req Request, retryCount int, err error) (bool, error)
 
+// header returns the value for the key or its lower case form
+func header(hdr map[string]string, key string) (string, bool) {
+	if v, ok := hdr[key]; ok {
+		return v, ok
+	}
+	v, ok := hdr[strings.ToLower(key)]
+	return v, ok
+}
+
+// RetryHint sets the retry hint from the Micro-Retry and Micro-Retry-After
+// response headers on the error returned by a server. The hint is carried
+// by the *errors.Error itself so the type of the error doesn't change.
+// Errors which aren't micro errors are returned as is.
+func RetryHint(err error, hdr map[string]string) error {
+	if err == nil || hdr == nil {
+		return err
+	}
+
+	var never bool
+	var after int64
+
+	if v, ok := header(hdr, "Micro-Retry"); ok && v == "false" {
+		never = true
+	}
+
+	if v, ok := header(hdr, "Micro-Retry-After"); ok {
+		if ms, perr := strconv.ParseInt(v, 10, 64); perr == nil && ms > 0 {
+			after = ms
+		}
+	}
+
+	if !never && after == 0 {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *errors.Error:
+		e.DoNotRetry = never
+		e.RetryAfter = after
+		return e
+	case serverError:
+		me := new(errors.Error)
+		if jerr := json.Unmarshal([]byte(e), me); jerr != nil {
+			return err
+		}
+		me.DoNotRetry = never
+		me.RetryAfter = after
+		return serverError(me.Error())
+	}
+
+	return err
+}
+
+// DoNotRetry returns true if the server asked for the request not to be retried
+func DoNotRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.FromError(err).DoNotRetry
+}
+
+// RetryAfter returns the time the server asked the client to wait before retrying
+func RetryAfter(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+	e := errors.FromError(err)
+	if e.DoNotRetry || e.RetryAfter <= 0 {
+		return 0, false
+	}
+	return time.Duration(e.RetryAfter) * time.Millisecond, true
+}
+
 // RetryAlways always retry on error
 func RetryAlways(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
 	return true, nil
 }
 
-// RetryOnError retries a request on a 500 or timeout error
+// RetryOnError retries a request on a 500 or timeout error unless the
+// server asked not to be retried or to wait past the deadline. A server
+// turning the request away as overloaded is only retried if it said when.
 func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
 	if err == nil {
 		return false, nil
 	}
 
+	if DoNotRetry(err) {
+		return false, nil
+	}
+
+	if d, ok := RetryAfter(err); ok {
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+			return false, nil
+		}
+	}
+
 	e := errors.Parse(err.Error())
 	if e == nil {
 		return false, nil
@@ -29,6 +118,10 @@ func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (
 	// retry on timeout or internal server error
 	case 408, 500:
 		return true, nil
+	// retry overload once the server says it can take the request
+	case 429:
+		_, ok := RetryAfter(err)
+		return ok, nil
 	default:
 		return false, nil
 	}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(0.1, 2, time.Second)
+
+	for i := 0; i < 100; i++ {
+		b.Request()
+	}
+
+	// 10% of 100 requests plus the min of 2
+	var retries int
+	for i := 0; i < 100; i++ {
+		if b.Retry() {
+			retries++
+		}
+	}
+
+	if retries != 12 {
+		t.Fatalf("Expected 12 retries got %d", retries)
+	}
+}
+
+func TestRetryHint(t *testing.T) {
+	c := NewClient()
+	req := c.NewRequest("test", "test", nil)
+	err := errors.InternalServerError("test", "failed")
+
+	retry, _ := RetryOnError(context.TODO(), req, 0, err)
+	if !retry {
+		t.Fatal("Expected error to be retried")
+	}
+
+	herr := RetryHint(err, map[string]string{"Micro-Retry": "false"})
+	if !DoNotRetry(herr) {
+		t.Fatal("Expected do not retry hint")
+	}
+
+	retry, _ = RetryOnError(context.TODO(), req, 0, herr)
+	if retry {
+		t.Fatal("Expected error not to be retried")
+	}
+
+	herr = RetryHint(err, map[string]string{"micro-retry-after": "100"})
+	if d, ok := RetryAfter(herr); !ok || d != 100*time.Millisecond {
+		t.Fatalf("Expected retry after 100ms got %v", d)
+	}
+
+	// the error is still the one from the server
+	if e, ok := herr.(*errors.Error); !ok || e.Code != 500 {
+		t.Fatalf("Expected *errors.Error with code 500 got %#v", herr)
+	}
+
+	// the hint is carried in the error returned by an rpc server
+	serr := RetryHint(serverError(errors.InternalServerError("test", "failed").Error()), map[string]string{"Micro-Retry": "false"})
+	if _, ok := serr.(serverError); !ok || !DoNotRetry(serr) {
+		t.Fatalf("Expected server error with do not retry hint got %#v", serr)
+	}
+
+	// don't retry past the deadline
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	retry, _ = RetryOnError(ctx, req, 0, herr)
+	if retry {
+		t.Fatal("Expected error not to be retried past the deadline")
+	}
+
+	// overload is only retried once the server says when
+	oerr := errors.New("test", "overloaded", 429)
+	if retry, _ := RetryOnError(context.TODO(), req, 0, oerr); retry {
+		t.Fatal("Expected overload without a hint not to be retried")
+	}
+	oerr = RetryHint(oerr, map[string]string{"Micro-Retry-After": "100"})
+	if retry, _ := RetryOnError(context.TODO(), req, 0, oerr); !retry {
+		t.Fatal("Expected overload with a hint to be retried")
+	}
+}
@@ -0,0 +1,279 @@
+// Package route sends the calls to a service to its versions by rules
+package route
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/client/mirror"
+	"github.com/micro/go-micro/v2/client/selector"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// Rule routes the calls to a service between its versions. Rules are
+// matched in order and the first for the service whose headers match the
+// metadata of the call is used e.g
+//
+//	[
+//	  {"service": "go.micro.srv.foo", "match": {"X-Canary": "true"}, "routes": [{"version": "v2"}]},
+//	  {"service": "go.micro.srv.foo", "routes": [{"version": "v1", "weight": 95}, {"version": "v2", "weight": 5}], "mirror": "v3"}
+//	]
+type Rule struct {
+	// Service is the name of the service called
+	Service string `json:"service"`
+	// Match is the metadata the call must have for the rule to apply
+	Match map[string]string `json:"match,omitempty"`
+	// Routes are the versions to send the calls to
+	Routes []Route `json:"routes,omitempty"`
+	// Mirror is a version sent a copy of the calls, its responses are discarded
+	Mirror string `json:"mirror,omitempty"`
+}
+
+// Route is a version of a service and its share of the calls
+type Route struct {
+	// Version of the service
+	Version string `json:"version"`
+	// Weight is the share of the calls relative to the other routes.
+	// The calls are split evenly if none of the routes have a weight.
+	Weight int `json:"weight,omitempty"`
+}
+
+// Router holds the rules and applies them to calls
+type Router struct {
+	sync.RWMutex
+	rules []Rule
+
+	// watcher of the config the rules are loaded from
+	w config.Watcher
+}
+
+// NewRouter returns a router applying the rules
+func NewRouter(rules ...Rule) *Router {
+	return &Router{rules: rules}
+}
+
+// Load returns a router with the rules read from the path of the config.
+// The rules are updated as the config changes until Stop is called.
+func Load(c config.Config, path ...string) (*Router, error) {
+	var rules []Rule
+	if err := c.Get(path...).Scan(&rules); err != nil {
+		return nil, err
+	}
+
+	w, err := c.Watch(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRouter(rules...)
+	r.w = w
+	go r.watch(w)
+
+	return r, nil
+}
+
+// watch updates the rules as the config changes
+func (r *Router) watch(w config.Watcher) {
+	for {
+		v, err := w.Next()
+		if err != nil {
+			return
+		}
+
+		var rules []Rule
+		if err := v.Scan(&rules); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("route: unable to read the rules: %v", err)
+			}
+			continue
+		}
+
+		r.Update(rules...)
+	}
+}
+
+// Update replaces the rules
+func (r *Router) Update(rules ...Rule) {
+	r.Lock()
+	r.rules = rules
+	r.Unlock()
+}
+
+// Rules returns a copy of the current rules
+func (r *Router) Rules() []Rule {
+	r.RLock()
+	defer r.RUnlock()
+	return append([]Rule(nil), r.rules...)
+}
+
+// Stop stops updating the rules from the config
+func (r *Router) Stop() error {
+	if r.w == nil {
+		return nil
+	}
+	return r.w.Stop()
+}
+
+// Match returns the rule for a call to the service or false if there's none
+func (r *Router) Match(ctx context.Context, service string) (Rule, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, rule := range r.rules {
+		if rule.Service != service {
+			continue
+		}
+		if matches(ctx, rule.Match) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+// matches returns true if the metadata of the context has the headers
+func matches(ctx context.Context, headers map[string]string) bool {
+	for k, v := range headers {
+		if val, ok := metadata.Get(ctx, k); !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns a selector filter picking a version of the rule by weight.
+// The other versions of the rule are used if the one picked has no nodes.
+func (r Rule) Filter() selector.Filter {
+	if len(r.Routes) == 0 {
+		return nil
+	}
+
+	version := r.pick()
+
+	return func(old []*registry.Service) []*registry.Service {
+		if services := selector.FilterVersion(version)(old); len(services) > 0 {
+			return services
+		}
+
+		var services []*registry.Service
+		for _, service := range old {
+			for _, route := range r.Routes {
+				if service.Version == route.Version {
+					services = append(services, service)
+					break
+				}
+			}
+		}
+		return services
+	}
+}
+
+// pick returns the version of a route picked by weight
+func (r Rule) pick() string {
+	var total int
+	for _, route := range r.Routes {
+		total += route.Weight
+	}
+
+	// split evenly
+	if total <= 0 {
+		return r.Routes[rand.Intn(len(r.Routes))].Version
+	}
+
+	n := rand.Intn(total)
+	for _, route := range r.Routes {
+		if n < route.Weight {
+			return route.Version
+		}
+		n -= route.Weight
+	}
+
+	return r.Routes[len(r.Routes)-1].Version
+}
+
+// ruleKey is the context key of the rule applied to a call
+type ruleKey struct{}
+
+// routedClient sends the calls to the versions of the rule in the context.
+// Mirrored calls don't have it since their context isn't derived from the
+// call's so they're only sent to the mirrored version.
+type routedClient struct {
+	client.Client
+}
+
+func (c *routedClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	if rule, ok := ctx.Value(ruleKey{}).(Rule); ok {
+		if filter := rule.Filter(); filter != nil {
+			opts = append(opts, client.WithSelectOption(selector.WithFilter(filter)))
+		}
+	}
+
+	return c.Client.Call(ctx, req, rsp, opts...)
+}
+
+type routeWrapper struct {
+	client.Client
+	r *Router
+	c *routedClient
+
+	sync.Mutex
+	// the mirror wrappers by version
+	mirrors map[string]client.Client
+}
+
+func (w *routeWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	rule, ok := w.r.Match(ctx, req.Service())
+	if !ok {
+		return w.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	ctx = context.WithValue(ctx, ruleKey{}, rule)
+
+	// don't mirror the mirrored calls again
+	if len(rule.Mirror) > 0 && !mirror.IsShadow(ctx) {
+		return w.mirror(rule.Mirror).Call(ctx, req, rsp, opts...)
+	}
+
+	return w.c.Call(ctx, req, rsp, opts...)
+}
+
+func (w *routeWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	if rule, ok := w.r.Match(ctx, req.Service()); ok {
+		if filter := rule.Filter(); filter != nil {
+			opts = append(opts, client.WithSelectOption(selector.WithFilter(filter)))
+		}
+	}
+
+	return w.Client.Stream(ctx, req, opts...)
+}
+
+// mirror returns the client mirroring the calls to the version
+func (w *routeWrapper) mirror(version string) client.Client {
+	w.Lock()
+	defer w.Unlock()
+
+	m, ok := w.mirrors[version]
+	if !ok {
+		m = mirror.NewClientWrapper(mirror.Version(version))(w.c)
+		w.mirrors[version] = m
+	}
+	return m
+}
+
+// NewClientWrapper returns a client wrapper which applies the rules of
+// the router to the calls made. Calls are mirrored with the mirror wrapper.
+func NewClientWrapper(r *Router) client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &routeWrapper{
+			Client:  c,
+			r:       r,
+			c:       &routedClient{Client: c},
+			mirrors: make(map[string]client.Client),
+		}
+	}
+}
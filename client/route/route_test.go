@@ -0,0 +1,264 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/client/mirror"
+	"github.com/micro/go-micro/v2/client/selector"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/config/reader"
+	"github.com/micro/go-micro/v2/config/reader/json"
+	"github.com/micro/go-micro/v2/config/source"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var testServices = []*registry.Service{
+	{Name: "foo", Version: "v1", Nodes: []*registry.Node{{Id: "foo-1"}}},
+	{Name: "foo", Version: "v2", Nodes: []*registry.Node{{Id: "foo-2"}}},
+	{Name: "foo", Version: "v3", Nodes: []*registry.Node{{Id: "foo-3"}}},
+}
+
+// versions returns the versions left by the call options' filters
+func versions(opts client.CallOptions) []string {
+	var sopts selector.SelectOptions
+	for _, o := range opts.SelectOptions {
+		o(&sopts)
+	}
+
+	services := testServices
+	for _, filter := range sopts.Filters {
+		services = filter(services)
+	}
+
+	var v []string
+	for _, s := range services {
+		v = append(v, s.Version)
+	}
+	return v
+}
+
+// testClient records the versions each call could be sent to
+type testClient struct {
+	client.Client
+
+	sync.Mutex
+	calls [][]string
+	// mirrored calls and those of them without a deadline
+	shadows   int
+	unbounded int
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	var options client.CallOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	c.Lock()
+	c.calls = append(c.calls, versions(options))
+	if v, _ := metadata.Get(ctx, mirror.ShadowHeader); v == "true" {
+		c.shadows++
+		if _, ok := ctx.Deadline(); !ok {
+			c.unbounded++
+		}
+	}
+	c.Unlock()
+	return nil
+}
+
+func (c *testClient) NewRequest(service, endpoint string, req interface{}, opts ...client.RequestOption) client.Request {
+	return client.NewClient().NewRequest(service, endpoint, req, opts...)
+}
+
+func TestRuleFilter(t *testing.T) {
+	rule := Rule{
+		Service: "foo",
+		Routes:  []Route{{Version: "v1", Weight: 95}, {Version: "v2", Weight: 5}},
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		services := rule.Filter()(testServices)
+		if len(services) != 1 {
+			t.Fatalf("Expected one version got %d", len(services))
+		}
+		counts[services[0].Version]++
+	}
+	if n := counts["v2"]; n < 300 || n > 700 {
+		t.Fatalf("Expected about 5%% of calls to v2 got %d", n)
+	}
+
+	// the other versions are used when the one picked has no nodes
+	rule.Routes = []Route{{Version: "v4", Weight: 100}, {Version: "v2"}}
+	services := rule.Filter()(testServices)
+	if len(services) != 1 || services[0].Version != "v2" {
+		t.Fatalf("Expected to fall back to v2 got %v", services)
+	}
+}
+
+func TestRouterMatch(t *testing.T) {
+	r := NewRouter(
+		Rule{Service: "foo", Match: map[string]string{"X-Canary": "true"}, Routes: []Route{{Version: "v2"}}},
+		Rule{Service: "foo", Routes: []Route{{Version: "v1"}}},
+	)
+
+	rule, ok := r.Match(context.TODO(), "foo")
+	if !ok || rule.Routes[0].Version != "v1" {
+		t.Fatalf("Expected the default rule got %+v", rule)
+	}
+
+	ctx := metadata.Set(context.TODO(), "X-Canary", "true")
+	rule, ok = r.Match(ctx, "foo")
+	if !ok || rule.Routes[0].Version != "v2" {
+		t.Fatalf("Expected the canary rule got %+v", rule)
+	}
+
+	if _, ok := r.Match(ctx, "bar"); ok {
+		t.Fatal("Expected no rule for bar")
+	}
+}
+
+func TestClientWrapper(t *testing.T) {
+	c := &testClient{}
+	r := NewRouter(Rule{Service: "foo", Routes: []Route{{Version: "v2"}}, Mirror: "v3"})
+	w := NewClientWrapper(r)(c)
+
+	req := w.NewRequest("foo", "Foo.Bar", map[string]string{})
+	if err := w.Call(context.TODO(), req, &map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the mirrored call
+	for i := 0; i < 100; i++ {
+		c.Lock()
+		n := len(c.calls)
+		c.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	got := make(map[string]bool)
+	for _, v := range c.calls {
+		if len(v) != 1 {
+			t.Fatalf("Expected each call to go to one version got %v", v)
+		}
+		got[v[0]] = true
+	}
+	if len(c.calls) != 2 || !got["v2"] || !got["v3"] {
+		t.Fatalf("Expected a call to v2 mirrored to v3 got %v", c.calls)
+	}
+	if c.shadows != 1 || c.unbounded != 0 {
+		t.Fatalf("Expected one marked mirror with a timeout got %d %d", c.shadows, c.unbounded)
+	}
+}
+
+func TestClientWrapperShadow(t *testing.T) {
+	c := &testClient{}
+	r := NewRouter(Rule{Service: "foo", Routes: []Route{{Version: "v2"}}, Mirror: "v3"})
+	w := NewClientWrapper(r)(c)
+
+	// mirrored calls aren't mirrored again
+	ctx := metadata.Set(context.TODO(), mirror.ShadowHeader, "true")
+	req := w.NewRequest("foo", "Foo.Bar", map[string]string{})
+	if err := w.Call(ctx, req, &map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond * 50)
+
+	c.Lock()
+	defer c.Unlock()
+	if len(c.calls) != 1 {
+		t.Fatalf("Expected only the call itself got %v", c.calls)
+	}
+}
+
+func TestRules(t *testing.T) {
+	r := NewRouter(Rule{Service: "foo"})
+	r.Rules()[0].Service = "bar"
+	if r.Rules()[0].Service != "foo" {
+		t.Fatal("Expected the rules to be copied")
+	}
+}
+
+// testConfig is a config whose changes are sent on a channel
+type testConfig struct {
+	config.Config
+	values  reader.Values
+	changes chan reader.Value
+}
+
+// testWatcher returns the changes of the config until stopped
+type testWatcher struct {
+	changes chan reader.Value
+	exit    chan bool
+}
+
+func (w *testWatcher) Next() (reader.Value, error) {
+	select {
+	case v := <-w.changes:
+		return v, nil
+	case <-w.exit:
+		return nil, errors.New("watcher stopped")
+	}
+}
+
+func (w *testWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func (c *testConfig) Get(path ...string) reader.Value {
+	return c.values.Get(path...)
+}
+
+func (c *testConfig) Watch(path ...string) (config.Watcher, error) {
+	return &testWatcher{changes: c.changes, exit: make(chan bool)}, nil
+}
+
+// values returns the config values of the JSON data
+func values(t *testing.T, data string) reader.Values {
+	v, err := json.NewReader().Values(&source.ChangeSet{Data: []byte(data), Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLoad(t *testing.T) {
+	c := &testConfig{
+		values:  values(t, `{"routes": [{"service": "foo", "routes": [{"version": "v1"}]}]}`),
+		changes: make(chan reader.Value),
+	}
+
+	r, err := Load(c, "routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	if rules := r.Rules(); len(rules) != 1 || rules[0].Routes[0].Version != "v1" {
+		t.Fatalf("Unexpected rules %+v", rules)
+	}
+
+	// the rules follow the config
+	c.changes <- values(t, `{"routes": [{"service": "foo", "routes": [{"version": "v2"}]}]}`).Get("routes")
+
+	for i := 0; i < 100; i++ {
+		if rules := r.Rules(); len(rules) == 1 && rules[0].Routes[0].Version == "v2" {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatalf("Expected the rules to be updated got %+v", r.Rules())
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/micro/go-micro/v2/util/buf"
 	"github.com/micro/go-micro/v2/util/net"
 	"github.com/micro/go-micro/v2/util/pool"
+	"github.com/micro/go-micro/v2/util/socket"
 )
 
 type rpcClient struct {
@@ -25,6 +26,8 @@ type rpcClient struct {
 	opts Options
 	pool pool.Pool
 	seq  uint64
+	// latency of calls per endpoint used for hedging
+	lat *latencies
 }
 
 func newRpcClient(opt ...Option) Client {
@@ -40,6 +43,7 @@ func newRpcClient(opt ...Option) Client {
 		opts: opts,
 		pool: p,
 		seq:  0,
+		lat:  newLatencies(),
 	}
 	rc.once.Store(false)
 
@@ -84,6 +88,17 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 
 	// set timeout in nanoseconds
 	msg.Header["Timeout"] = fmt.Sprintf("%d", opts.RequestTimeout)
+
+	// pass along what's left of the deadline
+	if d, ok := ctx.Deadline(); ok {
+		msg.Header["Micro-Deadline"] = fmt.Sprintf("%d", time.Until(d))
+	}
+
+	// let the server know how important the request is
+	if opts.Priority > 0 {
+		msg.Header[PriorityHeader] = fmt.Sprintf("%d", opts.Priority)
+	}
+
 	// set the content type for the request
 	msg.Header["Content-Type"] = req.ContentType()
 	// set the accept header
@@ -200,6 +215,22 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 	if opts.StreamTimeout > time.Duration(0) {
 		msg.Header["Timeout"] = fmt.Sprintf("%d", opts.StreamTimeout)
 	}
+
+	// pass along what's left of the deadline
+	if d, ok := ctx.Deadline(); ok {
+		msg.Header["Micro-Deadline"] = fmt.Sprintf("%d", time.Until(d))
+	}
+
+	// let the server know how important the request is
+	if opts.Priority > 0 {
+		msg.Header[PriorityHeader] = fmt.Sprintf("%d", opts.Priority)
+	}
+
+	// offer the server our window for flow control
+	if opts.StreamWindow > 0 {
+		msg.Header[socket.WindowHeader] = fmt.Sprintf("%d", opts.StreamWindow)
+	}
+
 	// set the content type for the request
 	msg.Header["Content-Type"] = req.ContentType()
 	// set the accept header
@@ -234,11 +265,19 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 	seq := atomic.AddUint64(&r.seq, 1) - 1
 	id := fmt.Sprintf("%v", seq)
 
+	// flow control the stream once the server sends its window
+	var sock transport.Socket = c
+	if opts.StreamWindow > 0 {
+		sock = socket.NewFlow(c, opts.StreamWindow, opts.StreamSendTimeout, map[string]string{
+			"Micro-Stream": id,
+		})
+	}
+
 	// create codec with stream id
-	codec := newRpcCodec(msg, c, cf, id)
+	codec := newRpcCodec(msg, sock, cf, id)
 
 	rsp := &rpcResponse{
-		socket: c,
+		socket: sock,
 		codec:  codec,
 	}
 
@@ -258,7 +297,7 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 		// signal the end of stream,
 		sendEOS: true,
 		// release func
-		release: func(err error) { c.Close() },
+		release: func(err error) { sock.Close() },
 	}
 
 	// wait for error response
@@ -321,7 +360,7 @@ func (r *rpcClient) Options() Options {
 }
 
 // next returns an iterator for the next nodes to call
-func (r *rpcClient) next(request Request, opts CallOptions) (selector.Next, error) {
+func (r *rpcClient) next(ctx context.Context, request Request, opts CallOptions) (selector.Next, error) {
 	// try get the proxy
 	service, address, _ := net.Proxy(request.Service(), opts.Address)
 
@@ -345,12 +384,21 @@ func (r *rpcClient) next(request Request, opts CallOptions) (selector.Next, erro
 		}, nil
 	}
 
+	// a hash key in the metadata is overridden by one in the call options
+	sopts := opts.SelectOptions
+	if key, ok := metadata.Get(ctx, HashKeyHeader); ok {
+		sopts = append([]selector.SelectOption{selector.WithKey(key)}, sopts...)
+	}
+
 	// get next nodes from the selector
-	next, err := r.opts.Selector.Select(service, opts.SelectOptions...)
+	next, err := r.opts.Selector.Select(service, sopts...)
 	if err != nil {
 		if err == selector.ErrNotFound {
 			return nil, errors.InternalServerError("go.micro.client", "service %s: %s", service, err.Error())
 		}
+		if err == selector.ErrCircuitOpen {
+			return nil, errors.New("go.micro.client", fmt.Sprintf("service %s: %s", service, err.Error()), 503)
+		}
 		return nil, errors.InternalServerError("go.micro.client", "error selecting %s node: %s", service, err.Error())
 	}
 
@@ -364,7 +412,7 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 		opt(&callOpts)
 	}
 
-	next, err := r.next(request, callOpts)
+	next, err := r.next(ctx, request, callOpts)
 	if err != nil {
 		return err
 	}
@@ -390,6 +438,11 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 	default:
 	}
 
+	// don't call at all with too little time left for the server
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+		return errors.Timeout("go.micro.client", "deadline too close to call: %v left", time.Until(d))
+	}
+
 	// make copy of call method
 	rcall := r.call
 
@@ -398,6 +451,15 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 		rcall = callOpts.CallWrappers[i-1](rcall)
 	}
 
+	// time the server asked us to wait before retrying
+	var wait time.Duration
+
+	// only hedge idempotent calls and never via a proxy
+	hedge := r.hedging(request, response, callOpts)
+	if _, _, ok := net.Proxy(request.Service(), callOpts.Address); ok {
+		hedge = false
+	}
+
 	// return errors.New("go.micro.client", "request timeout", 408)
 	call := func(i int) error {
 		// call backoff first. Someone may want an initial start delay
@@ -406,11 +468,21 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 			return errors.InternalServerError("go.micro.client", "backoff error: %v", err.Error())
 		}
 
+		// wait at least as long as the server asked
+		if t < wait {
+			t = wait
+		}
+
 		// only sleep if greater than 0
 		if t.Seconds() > 0 {
 			time.Sleep(t)
 		}
 
+		// send the request to more than one node
+		if hedge {
+			return r.hedge(ctx, next, rcall, request, response, callOpts)
+		}
+
 		// select next node
 		node, err := next()
 		service := request.Service()
@@ -422,8 +494,15 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 		}
 
 		// make the call
+		started := time.Now()
 		err = rcall(ctx, node, request, response, callOpts)
 		r.opts.Selector.Mark(service, node, err)
+
+		// record the latency to work out when to hedge
+		if err == nil && callOpts.HedgePercentile > 0 {
+			r.lat.record(service+"."+request.Endpoint(), time.Since(started))
+		}
+
 		return err
 	}
 
@@ -435,6 +514,11 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 		retries = 0
 	}
 
+	// record the request against the retry budget
+	if r.opts.Budget != nil {
+		r.opts.Budget.Request()
+	}
+
 	ch := make(chan error, retries+1)
 	var gerr error
 
@@ -461,6 +545,17 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 				return err
 			}
 
+			// don't retry once the budget is spent
+			if i < retries && r.opts.Budget != nil && !r.opts.Budget.Retry() {
+				return err
+			}
+
+			// don't retry with too little time left for the server
+			if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+				return err
+			}
+
+			wait, _ = RetryAfter(err)
 			gerr = err
 		}
 	}
@@ -475,7 +570,7 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 		opt(&callOpts)
 	}
 
-	next, err := r.next(request, callOpts)
+	next, err := r.next(ctx, request, callOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -487,6 +582,11 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 	default:
 	}
 
+	// don't stream at all with too little time left for the server
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+		return nil, errors.Timeout("go.micro.client", "deadline too close to stream: %v left", time.Until(d))
+	}
+
 	call := func(i int) (Stream, error) {
 		// call backoff first. Someone may want an initial start delay
 		t, err := callOpts.Backoff(ctx, request, i)
@@ -526,6 +626,11 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 		retries = 0
 	}
 
+	// record the request against the retry budget
+	if r.opts.Budget != nil {
+		r.opts.Budget.Request()
+	}
+
 	ch := make(chan response, retries+1)
 	var grr error
 
@@ -541,7 +646,12 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 		case rsp := <-ch:
 			// if the call succeeded lets bail early
 			if rsp.err == nil {
-				return rsp.stream, nil
+				stream := rsp.stream
+				// wrap the stream
+				for i := len(callOpts.StreamWrappers); i > 0; i-- {
+					stream = callOpts.StreamWrappers[i-1](stream)
+				}
+				return stream, nil
 			}
 
 			retry, rerr := callOpts.Retry(ctx, request, i, rsp.err)
@@ -553,6 +663,16 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 				return nil, rsp.err
 			}
 
+			// don't retry once the budget is spent
+			if i < retries && r.opts.Budget != nil && !r.opts.Budget.Retry() {
+				return nil, rsp.err
+			}
+
+			// don't retry with too little time left for the server
+			if d, ok := ctx.Deadline(); ok && time.Until(d) < callOpts.MinDeadline {
+				return nil, rsp.err
+			}
+
 			grr = rsp.err
 		}
 	}
@@ -623,10 +743,15 @@ func (r *rpcClient) Publish(ctx context.Context, msg Message, opts ...PublishOpt
 		r.once.Store(true)
 	}
 
+	pubOpts := []broker.PublishOption{broker.PublishContext(options.Context)}
+	if len(options.OrderingKey) > 0 {
+		pubOpts = append(pubOpts, broker.OrderingKey(options.OrderingKey))
+	}
+
 	return r.opts.Broker.Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
-	}, broker.PublishContext(options.Context))
+	}, pubOpts...)
 }
 
 func (r *rpcClient) NewMessage(topic string, message interface{}, opts ...MessageOption) Message {
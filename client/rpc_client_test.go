@@ -3,8 +3,12 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
 	"github.com/micro/go-micro/v2/client/selector"
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/registry"
@@ -159,3 +163,228 @@ func TestCallWrapper(t *testing.T) {
 		t.Fatal("wrapper not called")
 	}
 }
+
+type testResponse struct {
+	Node string
+}
+
+func TestCallHedge(t *testing.T) {
+	var mtx sync.Mutex
+	var calls []string
+
+	wrap := func(cf CallFunc) CallFunc {
+		return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+			mtx.Lock()
+			calls = append(calls, node.Id)
+			first := len(calls) == 1
+			mtx.Unlock()
+
+			// the first node never responds
+			if first {
+				<-ctx.Done()
+				return errors.Timeout("test.error", "slow node")
+			}
+
+			rsp.(*testResponse).Node = node.Id
+			return nil
+		}
+	}
+
+	r := newTestRegistry()
+	c := NewClient(
+		Registry(r),
+		WrapCall(wrap),
+		Hedges(1),
+		HedgeDelay(time.Millisecond*10),
+	)
+	c.Options().Selector.Init(selector.Registry(r))
+
+	req := c.NewRequest("foo", "Test.Endpoint", nil)
+
+	rsp := new(testResponse)
+	if err := c.Call(context.Background(), req, rsp, WithIdempotent(), WithRetries(0)); err != nil {
+		t.Fatal("hedged call error", err)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 calls got %v", calls)
+	}
+
+	if rsp.Node != calls[1] || calls[0] == calls[1] {
+		t.Fatalf("Expected response from hedged node %s got %v", calls[1], rsp)
+	}
+
+	// calls not marked idempotent are never hedged
+	mtx.Lock()
+	calls = nil
+	mtx.Unlock()
+	if err := c.Call(context.Background(), req, rsp, WithRetries(0), WithRequestTimeout(time.Millisecond*50)); err == nil {
+		t.Fatal("Expected call to time out")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 call got %v", calls)
+	}
+}
+
+// markSelector records the results marked against nodes
+type markSelector struct {
+	selector.Selector
+	marks    chan error
+	released chan string
+}
+
+func (m *markSelector) Mark(service string, node *registry.Node, err error) {
+	m.marks <- err
+	m.Selector.Mark(service, node, err)
+}
+
+func (m *markSelector) Release(service string, node *registry.Node) {
+	m.released <- node.Id
+	selector.Release(m.Selector, service, node)
+}
+
+func TestCallHedgeRelease(t *testing.T) {
+	var mtx sync.Mutex
+	var calls []string
+
+	wrap := func(cf CallFunc) CallFunc {
+		return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+			mtx.Lock()
+			calls = append(calls, node.Id)
+			first := len(calls) == 1
+			mtx.Unlock()
+
+			// the first node never responds
+			if first {
+				<-ctx.Done()
+				return errors.Timeout("test.error", "slow node")
+			}
+			return nil
+		}
+	}
+
+	r := newTestRegistry()
+	s := &markSelector{
+		Selector: selector.NewSelector(selector.Registry(r)),
+		marks:    make(chan error, 10),
+		released: make(chan string, 10),
+	}
+	c := NewClient(
+		Registry(r),
+		Selector(s),
+		WrapCall(wrap),
+		Hedges(1),
+		HedgeDelay(time.Millisecond*10),
+	)
+
+	req := c.NewRequest("foo", "Test.Endpoint", nil)
+
+	if err := c.Call(context.Background(), req, new(testResponse), WithIdempotent(), WithRetries(0)); err != nil {
+		t.Fatal("hedged call error", err)
+	}
+
+	// the winner is marked
+	if err := <-s.marks; err != nil {
+		t.Fatalf("Expected the winner to be marked a success got %v", err)
+	}
+
+	// the cancelled hedge is released without a result
+	select {
+	case id := <-s.released:
+		mtx.Lock()
+		first := calls[0]
+		mtx.Unlock()
+		if id != first {
+			t.Fatalf("Expected %s to be released got %s", first, id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the cancelled hedge to be released")
+	}
+
+	select {
+	case err := <-s.marks:
+		t.Fatalf("Expected the cancelled hedge not to be marked got %v", err)
+	default:
+	}
+}
+
+func TestCallMinDeadline(t *testing.T) {
+	r := newTestRegistry()
+	s := &markSelector{
+		Selector: selector.NewSelector(selector.Registry(r)),
+		marks:    make(chan error, 10),
+		released: make(chan string, 10),
+	}
+	c := NewClient(Registry(r), Selector(s))
+
+	req := c.NewRequest("foo", "Test.Endpoint", nil)
+
+	var retried bool
+	retry := func(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
+		retried = true
+		return true, nil
+	}
+
+	// the request timeout is less than the min so we never dial
+	err := c.Call(context.Background(), req, nil,
+		WithRequestTimeout(time.Second),
+		WithMinDeadline(time.Minute),
+		WithRetry(retry),
+	)
+
+	if e := errors.FromError(err); e.Code != 408 {
+		t.Fatalf("Expected timeout error got %v", err)
+	}
+
+	// the refusal is never held against a node or retried
+	if len(s.marks) > 0 || retried {
+		t.Fatalf("Expected no node to be marked or retried got %d marks", len(s.marks))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := c.Stream(ctx, req, WithMinDeadline(time.Minute), WithRetry(retry)); errors.FromError(err).Code != 408 {
+		t.Fatalf("Expected timeout error got %v", err)
+	}
+
+	if len(s.marks) > 0 || retried {
+		t.Fatalf("Expected no node to be marked or retried got %d marks", len(s.marks))
+	}
+}
+
+// keyBroker records the ordering keys of the published messages
+type keyBroker struct {
+	broker.Broker
+	keys []string
+}
+
+func (b *keyBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	b.keys = append(b.keys, options.OrderingKey)
+	return nil
+}
+
+func TestPublishOrderingKey(t *testing.T) {
+	b := &keyBroker{Broker: bmemory.NewBroker()}
+	c := NewClient(Broker(b), Registry(newTestRegistry()), ContentType("application/json"))
+
+	msg := c.NewMessage("test", map[string]string{"foo": "bar"})
+	if err := c.Publish(context.TODO(), msg, WithOrderingKey("key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Publish(context.TODO(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(b.keys) != fmt.Sprint([]string{"key", ""}) {
+		t.Fatalf("Expected the ordering key to be passed to the broker got %q", b.keys)
+	}
+}
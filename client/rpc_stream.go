@@ -101,7 +101,7 @@ func (r *rpcStream) Recv(msg interface{}) error {
 		// any subsequent requests will get the ReadResponseBody
 		// error if there is one.
 		if resp.Error != lastStreamResponseError {
-			r.err = serverError(resp.Error)
+			r.err = RetryHint(serverError(resp.Error), resp.Header)
 		} else {
 			r.err = io.EOF
 		}
@@ -0,0 +1,364 @@
+package selector
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var (
+	// DefaultDecay is the time constant the peak ewma latency decays over
+	DefaultDecay = time.Second * 10
+	// DefaultPenalty is the latency recorded for a failed call
+	DefaultPenalty = time.Second
+
+	// WeightKey is the node metadata key used by the weighted round robin balancer
+	WeightKey = "weight"
+
+	// how often and after how long node state is garbage collected
+	pruneInterval = time.Minute
+	pruneIdle     = time.Minute * 5
+)
+
+// balancerReleaser is implemented by balancers which count the calls
+// in flight so an abandoned call can be dropped without a result
+type balancerReleaser interface {
+	release(service string, node *registry.Node)
+}
+
+// nodeState is the per node state kept by the balancers
+type nodeState struct {
+	// calls in flight
+	inflight int
+	// peak ewma latency in nanoseconds
+	ewma float64
+	// time the latency was last updated
+	updated time.Time
+	// current weight for weighted round robin
+	current int
+	// last time the node was passed to Select
+	seen time.Time
+}
+
+// nodeStates holds the state of nodes by service and node id
+type nodeStates struct {
+	sync.Mutex
+	services map[string]map[string]*nodeState
+	pruned   time.Time
+}
+
+func newNodeStates() *nodeStates {
+	return &nodeStates{
+		services: make(map[string]map[string]*nodeState),
+		pruned:   time.Now(),
+	}
+}
+
+// get returns the state for a node. Must be called with the lock held.
+func (n *nodeStates) get(service string, node *registry.Node) *nodeState {
+	nodes, ok := n.services[service]
+	if !ok {
+		nodes = make(map[string]*nodeState)
+		n.services[service] = nodes
+	}
+	s, ok := nodes[node.Id]
+	if !ok {
+		s = new(nodeState)
+		nodes[node.Id] = s
+	}
+	return s
+}
+
+// nodes flattens the services and marks the nodes as seen
+func (n *nodeStates) nodes(services []*registry.Service) ([]*registry.Node, []*nodeState) {
+	n.Lock()
+	defer n.Unlock()
+
+	now := time.Now()
+
+	var nodes []*registry.Node
+	var states []*nodeState
+
+	for _, service := range services {
+		for _, node := range service.Nodes {
+			s := n.get(service.Name, node)
+			s.seen = now
+			nodes = append(nodes, node)
+			states = append(states, s)
+		}
+	}
+
+	if now.Sub(n.pruned) > pruneInterval {
+		n.prune(now)
+	}
+
+	return nodes, states
+}
+
+// prune drops nodes which are idle and haven't been seen in a while
+func (n *nodeStates) prune(now time.Time) {
+	for service, nodes := range n.services {
+		for id, s := range nodes {
+			if s.inflight == 0 && now.Sub(s.seen) > pruneIdle {
+				delete(nodes, id)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(n.services, service)
+		}
+	}
+	n.pruned = now
+}
+
+// done decrements the calls in flight and returns the node state
+func (n *nodeStates) done(service string, node *registry.Node) *nodeState {
+	s := n.get(service, node)
+	if s.inflight > 0 {
+		s.inflight--
+	}
+	return s
+}
+
+func (n *nodeStates) reset(service string) {
+	n.Lock()
+	delete(n.services, service)
+	n.Unlock()
+}
+
+type peakEWMA struct {
+	decay   time.Duration
+	penalty time.Duration
+	states  *nodeStates
+}
+
+// cost is the expected latency of a call to the node
+func (p *peakEWMA) cost(s *nodeState, now time.Time) float64 {
+	latency := p.latency(s, now)
+	// no latency observed yet, favour the node unless it's busy
+	if latency == 0 && s.inflight > 0 {
+		latency = float64(p.penalty)
+	}
+	return latency * float64(s.inflight+1)
+}
+
+// latency decays the ewma towards zero while no calls complete
+func (p *peakEWMA) latency(s *nodeState, now time.Time) float64 {
+	elapsed := now.Sub(s.updated)
+	if elapsed <= 0 || s.ewma == 0 {
+		return s.ewma
+	}
+	return s.ewma * math.Exp(-float64(elapsed)/float64(p.decay))
+}
+
+func (p *peakEWMA) Select(services []*registry.Service, opts SelectOptions) Next {
+	nodes, states := p.states.nodes(services)
+
+	return func() (*registry.Node, error) {
+		if len(nodes) == 0 {
+			return nil, ErrNoneAvailable
+		}
+
+		p.states.Lock()
+		defer p.states.Unlock()
+
+		i := rand.Intn(len(nodes))
+
+		if len(nodes) > 1 {
+			// pick another distinct node
+			j := rand.Intn(len(nodes) - 1)
+			if j >= i {
+				j++
+			}
+
+			now := time.Now()
+			if p.cost(states[j], now) < p.cost(states[i], now) {
+				i = j
+			}
+		}
+
+		states[i].inflight++
+		return nodes[i], nil
+	}
+}
+
+func (p *peakEWMA) release(service string, node *registry.Node) {
+	p.states.Lock()
+	p.states.done(service, node)
+	p.states.Unlock()
+}
+
+func (p *peakEWMA) Mark(service string, node *registry.Node, err error, latency time.Duration) {
+	if err != nil && latency < p.penalty {
+		latency = p.penalty
+	}
+
+	p.states.Lock()
+	defer p.states.Unlock()
+
+	now := time.Now()
+	s := p.states.done(service, node)
+	rtt := float64(latency)
+
+	// take the peak straight away, otherwise decay towards the sample
+	if rtt > s.ewma {
+		s.ewma = rtt
+	} else {
+		w := math.Exp(-float64(now.Sub(s.updated)) / float64(p.decay))
+		s.ewma = s.ewma*w + rtt*(1-w)
+	}
+
+	s.updated = now
+}
+
+func (p *peakEWMA) Reset(service string) {
+	p.states.reset(service)
+}
+
+func (p *peakEWMA) String() string {
+	return "peak_ewma"
+}
+
+type leastRequests struct {
+	states *nodeStates
+}
+
+func (l *leastRequests) Select(services []*registry.Service, opts SelectOptions) Next {
+	nodes, states := l.states.nodes(services)
+
+	return func() (*registry.Node, error) {
+		if len(nodes) == 0 {
+			return nil, ErrNoneAvailable
+		}
+
+		l.states.Lock()
+		defer l.states.Unlock()
+
+		// start at a random offset so ties are spread out
+		offset := rand.Int()
+		best := -1
+
+		for k := range nodes {
+			i := (offset + k) % len(nodes)
+			if best < 0 || states[i].inflight < states[best].inflight {
+				best = i
+			}
+		}
+
+		states[best].inflight++
+		return nodes[best], nil
+	}
+}
+
+func (l *leastRequests) Mark(service string, node *registry.Node, err error, latency time.Duration) {
+	l.states.Lock()
+	l.states.done(service, node)
+	l.states.Unlock()
+}
+
+func (l *leastRequests) release(service string, node *registry.Node) {
+	l.states.Lock()
+	l.states.done(service, node)
+	l.states.Unlock()
+}
+
+func (l *leastRequests) Reset(service string) {
+	l.states.reset(service)
+}
+
+func (l *leastRequests) String() string {
+	return "least_requests"
+}
+
+type weightedRoundRobin struct {
+	states *nodeStates
+}
+
+// weight returns the weight of the node from its metadata
+func weight(node *registry.Node) int {
+	if node.Metadata == nil {
+		return 1
+	}
+	v, ok := node.Metadata[WeightKey]
+	if !ok {
+		return 1
+	}
+	w, err := strconv.Atoi(v)
+	if err != nil || w < 0 {
+		return 1
+	}
+	return w
+}
+
+func (w *weightedRoundRobin) Select(services []*registry.Service, opts SelectOptions) Next {
+	nodes, states := w.states.nodes(services)
+
+	weights := make([]int, len(nodes))
+	for i, node := range nodes {
+		weights[i] = weight(node)
+	}
+
+	return func() (*registry.Node, error) {
+		if len(nodes) == 0 {
+			return nil, ErrNoneAvailable
+		}
+
+		w.states.Lock()
+		defer w.states.Unlock()
+
+		// smooth weighted round robin
+		var total int
+		best := -1
+
+		for i, s := range states {
+			s.current += weights[i]
+			total += weights[i]
+			if best < 0 || s.current > states[best].current {
+				best = i
+			}
+		}
+
+		states[best].current -= total
+		return nodes[best], nil
+	}
+}
+
+func (w *weightedRoundRobin) Mark(service string, node *registry.Node, err error, latency time.Duration) {
+}
+
+func (w *weightedRoundRobin) Reset(service string) {
+	w.states.reset(service)
+}
+
+func (w *weightedRoundRobin) String() string {
+	return "weighted_round_robin"
+}
+
+// PeakEWMA returns a power of two choices balancer which picks the node
+// with the lower peak ewma latency weighted by the calls in flight
+func PeakEWMA() Balancer {
+	return &peakEWMA{
+		decay:   DefaultDecay,
+		penalty: DefaultPenalty,
+		states:  newNodeStates(),
+	}
+}
+
+// LeastRequests returns a balancer which picks the node
+// with the least outstanding requests
+func LeastRequests() Balancer {
+	return &leastRequests{
+		states: newNodeStates(),
+	}
+}
+
+// WeightedRoundRobin returns a balancer which round robins across the nodes
+// in proportion to the weight set in their metadata under WeightKey
+func WeightedRoundRobin() Balancer {
+	return &weightedRoundRobin{
+		states: newNodeStates(),
+	}
+}
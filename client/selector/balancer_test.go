@@ -0,0 +1,161 @@
+package selector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestPeakEWMA(t *testing.T) {
+	r := memory.NewRegistry(memory.Services(testData))
+	sel := NewSelector(Registry(r), SetBalancer(PeakEWMA()))
+
+	next, err := sel.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slow := "foo-1.0.1-321"
+
+	// make one node slow, the others fast
+	for i := 0; i < 20; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node.Id == slow {
+			time.Sleep(time.Millisecond * 10)
+		}
+		sel.Mark("foo", node, nil)
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[node.Id]++
+		sel.Mark("foo", node, nil)
+	}
+
+	// it can only be picked when it's chosen twice out of two
+	if counts[slow] > 20 {
+		t.Fatalf("Expected slow node to be avoided got %v", counts)
+	}
+}
+
+func TestLeastRequests(t *testing.T) {
+	r := memory.NewRegistry(memory.Services(testData))
+	sel := NewSelector(Registry(r), SetBalancer(LeastRequests()))
+
+	next, err := sel.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without marking every node should be picked once per round
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[node.Id] {
+			t.Fatalf("Expected node %s to have the most requests", node.Id)
+		}
+		seen[node.Id] = true
+	}
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	services := []*registry.Service{
+		{
+			Name: "foo",
+			Nodes: []*registry.Node{
+				{Id: "foo-1", Metadata: map[string]string{"weight": "3"}},
+				{Id: "foo-2", Metadata: map[string]string{"weight": "1"}},
+			},
+		},
+	}
+
+	b := WeightedRoundRobin()
+	counts := make(map[string]int)
+
+	// state survives across calls to select
+	for i := 0; i < 10; i++ {
+		next := b.Select(services, SelectOptions{})
+		for j := 0; j < 4; j++ {
+			node, err := next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[node.Id]++
+		}
+	}
+
+	if counts["foo-1"] != 30 || counts["foo-2"] != 10 {
+		t.Fatalf("Expected 30/10 split got %v", counts)
+	}
+}
+
+func TestPendingOutOfOrder(t *testing.T) {
+	p := newPending()
+	node := &registry.Node{Id: "foo-1"}
+
+	slow := p.start("foo", node, nil)
+	time.Sleep(time.Millisecond * 20)
+	fast := p.start("foo", node, nil)
+
+	// the second call finishes first
+	c, ok := p.done("foo", fast)
+	if !ok || time.Since(c.started) >= time.Millisecond*20 {
+		t.Fatalf("Expected the latency of the fast call got %v", time.Since(c.started))
+	}
+	c, ok = p.done("foo", slow)
+	if !ok || time.Since(c.started) < time.Millisecond*20 {
+		t.Fatalf("Expected the latency of the slow call got %v", time.Since(c.started))
+	}
+}
+
+func TestPendingPrune(t *testing.T) {
+	p := newPending()
+	gone := p.start("foo", &registry.Node{Id: "foo-1"}, nil)
+	kept := p.start("foo", &registry.Node{Id: "foo-2"}, nil)
+
+	// the calls to nodes which left are dropped
+	p.prune("foo", []*registry.Service{{Name: "foo", Nodes: []*registry.Node{{Id: "foo-2"}}}})
+
+	if _, ok := p.done("foo", gone); ok {
+		t.Fatal("Expected the call to the node which left to be dropped")
+	}
+	if _, ok := p.done("foo", kept); !ok {
+		t.Fatal("Expected the call to the current node to be kept")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	l := LeastRequests().(*leastRequests)
+	s := NewSelector(SetBalancer(l)).(*registrySelector)
+	node := &registry.Node{Id: "foo-1"}
+
+	l.states.Lock()
+	l.states.get("foo", node).inflight++
+	l.states.Unlock()
+	n := s.pc.start("foo", node, l)
+
+	Release(s, "foo", n)
+
+	if _, ok := s.pc.done("foo", n); ok {
+		t.Fatal("Expected the call to be released")
+	}
+
+	l.states.Lock()
+	inflight := l.states.get("foo", node).inflight
+	l.states.Unlock()
+	if inflight != 0 {
+		t.Fatalf("Expected 0 in flight got %d", inflight)
+	}
+}
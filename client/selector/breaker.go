@@ -0,0 +1,345 @@
+package selector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// BreakerState is the state of the circuit breaker for a node
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests through to the node
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests to the node
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe requests through
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// breaker tracks a circuit per node for every service it has seen
+type breaker struct {
+	sync.Mutex
+	opts BreakerOptions
+	// service name -> node id -> circuit
+	circuits map[string]map[string]*circuit
+}
+
+// bucket is a slice of the rolling window
+type bucket struct {
+	epoch    int64
+	requests int
+	errors   int
+	latency  time.Duration
+}
+
+// circuit is the breaker state of a single node
+type circuit struct {
+	state BreakerState
+	// time of the last state change
+	changed time.Time
+	// rolling window of results
+	buckets []bucket
+	// probes in flight while half open
+	probes int
+	// successful probes while half open
+	successes int
+}
+
+func newBreaker(opts BreakerOptions) *breaker {
+	return &breaker{
+		opts:     opts,
+		circuits: make(map[string]map[string]*circuit),
+	}
+}
+
+// failure returns true if the error should count against the node.
+// Client errors e.g 4xx are the caller's fault so don't count.
+func failure(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Code == 0 || e.Code == 408 || e.Code >= 500
+}
+
+func (b *breaker) init(opts BreakerOptions) {
+	b.Lock()
+	b.opts = opts
+	b.circuits = make(map[string]map[string]*circuit)
+	b.Unlock()
+}
+
+// width of a single bucket in the window
+func (b *breaker) width() int64 {
+	n := b.opts.Buckets
+	if n <= 0 {
+		n = 1
+	}
+	w := int64(b.opts.Window) / int64(n)
+	if w <= 0 {
+		w = 1
+	}
+	return w
+}
+
+func (b *breaker) circuit(service, id string) *circuit {
+	nodes, ok := b.circuits[service]
+	if !ok {
+		nodes = make(map[string]*circuit)
+		b.circuits[service] = nodes
+	}
+	c, ok := nodes[id]
+	if !ok {
+		n := b.opts.Buckets
+		if n <= 0 {
+			n = 1
+		}
+		c = &circuit{buckets: make([]bucket, n)}
+		nodes[id] = c
+	}
+	return c
+}
+
+// available returns true if the node can currently take requests.
+// It moves an open circuit to half open once the sleep window has passed.
+func (b *breaker) available(c *circuit, now time.Time) bool {
+	switch c.state {
+	case BreakerOpen:
+		if now.Sub(c.changed) < b.opts.SleepWindow {
+			return false
+		}
+		c.state = BreakerHalfOpen
+		c.changed = now
+		c.probes = 0
+		c.successes = 0
+		return true
+	case BreakerHalfOpen:
+		return c.probes < b.opts.Probes
+	default:
+		return true
+	}
+}
+
+// filter removes the nodes with an open circuit
+func (b *breaker) filter(old []*registry.Service) []*registry.Service {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.opts.Disabled {
+		return old
+	}
+
+	now := time.Now()
+
+	var services []*registry.Service
+
+	for _, service := range old {
+		nodes := b.circuits[service.Name]
+		// nothing tracked yet
+		if len(nodes) == 0 {
+			services = append(services, service)
+			continue
+		}
+
+		var available []*registry.Node
+
+		for _, node := range service.Nodes {
+			c, ok := nodes[node.Id]
+			if !ok || b.available(c, now) {
+				available = append(available, node)
+			}
+		}
+
+		if len(available) == 0 {
+			continue
+		}
+
+		serv := new(registry.Service)
+		*serv = *service
+		serv.Nodes = available
+		services = append(services, serv)
+	}
+
+	return services
+}
+
+// picked records the start of a request to a node
+func (b *breaker) picked(service string, node *registry.Node) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.opts.Disabled {
+		return
+	}
+
+	c := b.circuit(service, node.Id)
+	if c.state == BreakerHalfOpen {
+		c.probes++
+	}
+}
+
+// release gives back a probe taken by a request which never finished
+func (b *breaker) release(service string, node *registry.Node) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.opts.Disabled {
+		return
+	}
+
+	c := b.circuit(service, node.Id)
+	if c.state == BreakerHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// mark records the result and latency of a request to a node
+func (b *breaker) mark(service string, node *registry.Node, err error, latency time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.opts.Disabled {
+		return
+	}
+
+	now := time.Now()
+	c := b.circuit(service, node.Id)
+	failed := failure(err)
+
+	switch c.state {
+	case BreakerOpen:
+		// late response from before the circuit opened
+		return
+	case BreakerHalfOpen:
+		if c.probes > 0 {
+			c.probes--
+		}
+		if failed || b.slow(latency) {
+			b.trip(c, now)
+			return
+		}
+		c.successes++
+		if c.successes >= b.opts.Probes {
+			c.state = BreakerClosed
+			c.changed = now
+			for i := range c.buckets {
+				c.buckets[i] = bucket{}
+			}
+		}
+		return
+	}
+
+	// record the result in the current bucket
+	epoch := now.UnixNano() / b.width()
+	bk := &c.buckets[epoch%int64(len(c.buckets))]
+	if bk.epoch != epoch {
+		*bk = bucket{epoch: epoch}
+	}
+	bk.requests++
+	bk.latency += latency
+	if failed {
+		bk.errors++
+	}
+
+	// sum up the window
+	var requests, errs int
+	var total time.Duration
+	for _, v := range c.buckets {
+		if epoch-v.epoch >= int64(len(c.buckets)) {
+			continue
+		}
+		requests += v.requests
+		errs += v.errors
+		total += v.latency
+	}
+
+	if requests == 0 || requests < b.opts.MinRequests {
+		return
+	}
+
+	if float64(errs)/float64(requests) >= b.opts.ErrorThreshold {
+		b.trip(c, now)
+		return
+	}
+
+	if b.slow(total / time.Duration(requests)) {
+		b.trip(c, now)
+	}
+}
+
+// slow returns true if the latency is over the threshold
+func (b *breaker) slow(latency time.Duration) bool {
+	return b.opts.LatencyThreshold > 0 && latency >= b.opts.LatencyThreshold
+}
+
+// trip opens the circuit
+func (b *breaker) trip(c *circuit, now time.Time) {
+	c.state = BreakerOpen
+	c.changed = now
+	c.probes = 0
+	c.successes = 0
+}
+
+// prune drops the circuits of nodes which have left the service
+func (b *breaker) prune(service string, services []*registry.Service) {
+	b.Lock()
+	defer b.Unlock()
+
+	nodes, ok := b.circuits[service]
+	if !ok {
+		return
+	}
+
+	current := make(map[string]bool)
+	for _, s := range services {
+		for _, n := range s.Nodes {
+			current[n.Id] = true
+		}
+	}
+
+	for id := range nodes {
+		if !current[id] {
+			delete(nodes, id)
+		}
+	}
+}
+
+// reset drops all the circuits for a service
+func (b *breaker) reset(service string) {
+	b.Lock()
+	delete(b.circuits, service)
+	b.Unlock()
+}
+
+// state returns the breaker state of a node
+func (b *breaker) state(service, id string) BreakerState {
+	b.Lock()
+	defer b.Unlock()
+
+	nodes, ok := b.circuits[service]
+	if !ok {
+		return BreakerClosed
+	}
+	c, ok := nodes[id]
+	if !ok {
+		return BreakerClosed
+	}
+	return c.state
+}
@@ -0,0 +1,116 @@
+package selector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestBreaker(t *testing.T) {
+	r := memory.NewRegistry(memory.Services(testData))
+	sel := NewSelector(
+		Registry(r),
+		EnableBreaker(),
+		BreakerMinRequests(5),
+		BreakerSleepWindow(time.Millisecond*50),
+	)
+
+	// fail every call to a single node
+	bad := testData["foo"][0].Nodes[0]
+	for i := 0; i < 5; i++ {
+		sel.Mark("foo", bad, errors.InternalServerError("foo", "failed"))
+	}
+
+	next, err := sel.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node.Id == bad.Id {
+			t.Fatalf("Expected node %s to be excluded", bad.Id)
+		}
+		sel.Mark("foo", node, nil)
+	}
+
+	// wait for the half open probe
+	time.Sleep(time.Millisecond * 60)
+
+	next, err = sel.Select("foo", WithFilter(FilterVersion("1.0.0")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var probed bool
+	for i := 0; i < 100; i++ {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node.Id == bad.Id {
+			probed = true
+			sel.Mark("foo", node, nil)
+			break
+		}
+		sel.Mark("foo", node, nil)
+	}
+
+	if !probed {
+		t.Fatalf("Expected node %s to be probed", bad.Id)
+	}
+
+	if s := sel.(*registrySelector).cb.state("foo", bad.Id); s != BreakerClosed {
+		t.Fatalf("Expected breaker to be closed got %s", s)
+	}
+}
+
+func TestBreakerClientErrors(t *testing.T) {
+	r := memory.NewRegistry(memory.Services(testData))
+	sel := NewSelector(Registry(r), EnableBreaker(), BreakerMinRequests(5))
+
+	node := testData["foo"][0].Nodes[0]
+	for i := 0; i < 10; i++ {
+		sel.Mark("foo", node, errors.BadRequest("foo", "bad request"))
+	}
+
+	if s := sel.(*registrySelector).cb.state("foo", node.Id); s != BreakerClosed {
+		t.Fatalf("Expected breaker to be closed got %s", s)
+	}
+}
+
+func TestBreakerCircuitOpen(t *testing.T) {
+	r := memory.NewRegistry(memory.Services(testData))
+	sel := NewSelector(Registry(r), EnableBreaker(), BreakerMinRequests(1))
+
+	for _, service := range testData["foo"] {
+		for _, node := range service.Nodes {
+			sel.Mark("foo", node, errors.InternalServerError("foo", "failed"))
+		}
+	}
+
+	if _, err := sel.Select("foo"); err != ErrCircuitOpen {
+		t.Fatalf("Expected %v got %v", ErrCircuitOpen, err)
+	}
+}
+
+func TestBreakerPrune(t *testing.T) {
+	b := newBreaker(DefaultBreaker)
+	b.opts.Disabled = false
+
+	node := &registry.Node{Id: "foo-1"}
+	b.mark("foo", node, nil, 0)
+
+	// the node is replaced by another keeping the count the same
+	b.prune("foo", []*registry.Service{{Nodes: []*registry.Node{{Id: "foo-2"}}}})
+
+	if _, ok := b.circuits["foo"]["foo-1"]; ok {
+		t.Fatal("Expected the circuit of the replaced node to be dropped")
+	}
+}
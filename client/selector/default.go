@@ -10,6 +10,9 @@ import (
 type registrySelector struct {
 	so Options
 	rc cache.Cache
+	cb *breaker
+	od *outliers
+	pc *pending
 }
 
 func (c *registrySelector) newCache() cache.Cache {
@@ -29,6 +32,8 @@ func (c *registrySelector) Init(opts ...Option) error {
 
 	c.rc.Stop()
 	c.rc = c.newCache()
+	c.cb.init(c.so.Breaker)
+	c.od.init(c.so.Outlier)
 
 	return nil
 }
@@ -39,13 +44,20 @@ func (c *registrySelector) Options() Options {
 
 func (c *registrySelector) Select(service string, opts ...SelectOption) (Next, error) {
 	sopts := SelectOptions{
-		Strategy: c.so.Strategy,
+		Balancer: c.so.Balancer,
 	}
 
 	for _, opt := range opts {
 		opt(&sopts)
 	}
 
+	// a strategy passed to Select takes precedence over the balancer
+	if sopts.Strategy != nil {
+		sopts.Balancer = nil
+	} else {
+		sopts.Strategy = c.so.Strategy
+	}
+
 	// get the service
 	// try the cache first
 	// if that fails go directly to the registry
@@ -57,6 +69,11 @@ func (c *registrySelector) Select(service string, opts ...SelectOption) (Next, e
 		return nil, err
 	}
 
+	// drop state for nodes which have gone away
+	c.cb.prune(service, services)
+	c.od.prune(service, services)
+	c.pc.prune(service, services)
+
 	// apply the filters
 	for _, filter := range sopts.Filters {
 		services = filter(services)
@@ -67,13 +84,84 @@ func (c *registrySelector) Select(service string, opts ...SelectOption) (Next, e
 		return nil, ErrNoneAvailable
 	}
 
-	return sopts.Strategy(services), nil
+	// move away from nodes which are shutting down
+	services = skipDraining(services)
+
+	// remove the nodes with an open circuit
+	all := services
+	services = c.cb.filter(services)
+	if len(services) == 0 {
+		return nil, ErrCircuitOpen
+	}
+
+	// remove the ejected outliers
+	services = c.od.filter(services)
+
+	// prefer the nodes closest to us
+	topology := c.so.Topology
+	services = topology.filter(all, services)
+
+	var next Next
+	if sopts.Balancer != nil {
+		next = sopts.Balancer.Select(services, sopts)
+	} else {
+		next = sopts.Strategy(services)
+	}
+
+	return func() (*registry.Node, error) {
+		node, err := next()
+		if err != nil {
+			return nil, err
+		}
+		c.cb.picked(service, node)
+		topology.picked(node)
+		return c.pc.start(service, node, sopts.Balancer), nil
+	}, nil
 }
 
 func (c *registrySelector) Mark(service string, node *registry.Node, err error) {
+	var latency time.Duration
+
+	call, ok := c.pc.done(service, node)
+	if ok {
+		latency = time.Since(call.started)
+	}
+
+	c.cb.mark(service, node, err, latency)
+	c.od.mark(service, node, err, latency)
+
+	if ok && call.balancer != nil {
+		call.balancer.Mark(service, node, err, latency)
+	}
+}
+
+// Release drops a call in flight without marking a result
+func (c *registrySelector) Release(service string, node *registry.Node) {
+	call, ok := c.pc.done(service, node)
+	if !ok {
+		return
+	}
+
+	c.cb.release(service, node)
+
+	if r, ok := call.balancer.(balancerReleaser); ok {
+		r.release(service, node)
+	}
 }
 
 func (c *registrySelector) Reset(service string) {
+	c.cb.reset(service)
+	c.od.reset(service)
+	c.pc.reset(service)
+
+	if c.so.Balancer != nil {
+		c.so.Balancer.Reset(service)
+	}
+}
+
+// Ejections returns the nodes currently ejected by outlier detection
+func (c *registrySelector) Ejections() []Ejection {
+	return c.od.ejections()
 }
 
 // Close stops the watcher and destroys the cache
@@ -90,6 +178,11 @@ func (c *registrySelector) String() string {
 func NewSelector(opts ...Option) Selector {
 	sopts := Options{
 		Strategy: Random,
+		Breaker:  DefaultBreaker,
+		Outlier:  DefaultOutlier,
+		Topology: TopologyOptions{
+			Threshold: DefaultTopologyThreshold,
+		},
 	}
 
 	for _, opt := range opts {
@@ -102,6 +195,9 @@ func NewSelector(opts ...Option) Selector {
 
 	s := &registrySelector{
 		so: sopts,
+		cb: newBreaker(sopts.Breaker),
+		od: newOutliers(sopts.Outlier),
+		pc: newPending(),
 	}
 	s.rc = s.newCache()
 
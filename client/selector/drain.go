@@ -0,0 +1,27 @@
+package selector
+
+import (
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// draining returns true if the node is shutting down
+func draining(node *registry.Node) bool {
+	return node.Metadata != nil && node.Metadata[registry.DrainingKey] == "true"
+}
+
+// skipDraining removes the draining nodes. They're kept if there are
+// no others since they still serve requests until they stop.
+func skipDraining(services []*registry.Service) []*registry.Service {
+	if count(services, draining) == 0 {
+		return services
+	}
+
+	active := only(services, func(node *registry.Node) bool {
+		return !draining(node)
+	})
+	if len(active) == 0 {
+		return services
+	}
+
+	return active
+}
@@ -0,0 +1,53 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestDraining(t *testing.T) {
+	node := func(id string, drain bool) *registry.Node {
+		md := map[string]string{}
+		if drain {
+			md[registry.DrainingKey] = "true"
+		}
+		return &registry.Node{Id: id, Address: id, Metadata: md}
+	}
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{node("a", true), node("b", false)},
+	})
+
+	sel := NewSelector(Registry(r))
+
+	next, err := sel.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		n, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Id == "a" {
+			t.Fatal("Expected the draining node to be skipped")
+		}
+	}
+
+	// the draining nodes are used when there's nothing else
+	r.Deregister(&registry.Service{Name: "foo", Version: "latest", Nodes: []*registry.Node{node("b", false)}})
+	sel = NewSelector(Registry(r))
+
+	next, err = sel.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := next(); err != nil || n.Id != "a" {
+		t.Fatalf("Expected the draining node got %v %v", n, err)
+	}
+}
@@ -0,0 +1,86 @@
+package selector
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+type consistentHash struct{}
+
+// score returns the rendezvous hash weight of a node for the key
+func score(key string, node *registry.Node) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(node.Id))
+	// fnv has poor avalanche on short inputs so mix it
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Select orders the nodes by their score for the key. The first call to
+// Next returns the owner of the key and subsequent calls the next owners.
+func (c *consistentHash) Select(services []*registry.Service, opts SelectOptions) Next {
+	var nodes []*registry.Node
+	for _, service := range services {
+		nodes = append(nodes, service.Nodes...)
+	}
+
+	// no key so spread the load
+	if len(opts.Key) == 0 {
+		rand.Shuffle(len(nodes), func(i, j int) {
+			nodes[i], nodes[j] = nodes[j], nodes[i]
+		})
+	} else {
+		scores := make(map[*registry.Node]uint64, len(nodes))
+		for _, node := range nodes {
+			scores[node] = score(opts.Key, node)
+		}
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return scores[nodes[i]] > scores[nodes[j]]
+		})
+	}
+
+	var i int
+	var mtx sync.Mutex
+
+	return func() (*registry.Node, error) {
+		if len(nodes) == 0 {
+			return nil, ErrNoneAvailable
+		}
+
+		mtx.Lock()
+		node := nodes[i%len(nodes)]
+		i++
+		mtx.Unlock()
+
+		return node, nil
+	}
+}
+
+func (c *consistentHash) Mark(service string, node *registry.Node, err error, latency time.Duration) {
+}
+
+func (c *consistentHash) Reset(service string) {
+}
+
+func (c *consistentHash) String() string {
+	return "consistent_hash"
+}
+
+// ConsistentHash returns a rendezvous hashing balancer which always picks
+// the same node for the key set with WithKey. When the node goes away only
+// its keys move, each to the node with the next highest score.
+func ConsistentHash() Balancer {
+	return new(consistentHash)
+}
@@ -0,0 +1,61 @@
+package selector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+func TestConsistentHash(t *testing.T) {
+	service := &registry.Service{Name: "foo"}
+	for i := 0; i < 5; i++ {
+		service.Nodes = append(service.Nodes, &registry.Node{
+			Id: fmt.Sprintf("foo-%d", i),
+		})
+	}
+
+	b := ConsistentHash()
+
+	owners := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		next := b.Select([]*registry.Service{service}, SelectOptions{Key: key})
+
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		owners[key] = node.Id
+
+		// same key lands on the same node
+		next = b.Select([]*registry.Service{service}, SelectOptions{Key: key})
+		if node, _ := next(); node.Id != owners[key] {
+			t.Fatalf("Expected key %s on node %s got %s", key, owners[key], node.Id)
+		}
+	}
+
+	// remove a node, only its keys should move
+	removed := service.Nodes[0].Id
+	smaller := &registry.Service{Name: "foo", Nodes: service.Nodes[1:]}
+
+	for key, owner := range owners {
+		next := b.Select([]*registry.Service{service}, SelectOptions{Key: key})
+		first, _ := next()
+		second, _ := next()
+
+		node, err := b.Select([]*registry.Service{smaller}, SelectOptions{Key: key})()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if owner != removed && node.Id != owner {
+			t.Fatalf("Expected key %s to stay on %s got %s", key, owner, node.Id)
+		}
+
+		// keys of the removed node fall back to the next owner
+		if first.Id == removed && node.Id != second.Id {
+			t.Fatalf("Expected key %s to move to %s got %s", key, second.Id, node.Id)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package selector
 
 import (
 	"context"
+	"time"
 
 	"github.com/micro/go-micro/v2/registry"
 )
@@ -9,15 +10,78 @@ import (
 type Options struct {
 	Registry registry.Registry
 	Strategy Strategy
+	// Balancer is used over the Strategy if set
+	Balancer Balancer
+	// Breaker is the per node circuit breaker config
+	Breaker BreakerOptions
+	// Topology is the location of the caller
+	Topology TopologyOptions
+	// Outlier is the outlier detection config
+	Outlier OutlierOptions
 
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
 }
 
+// BreakerOptions configure the per node circuit breaker which
+// is fed by Mark and used to filter nodes on Select
+type BreakerOptions struct {
+	// Disabled turns off the circuit breaker
+	Disabled bool
+	// Window is the rolling window errors and latency are tracked over
+	Window time.Duration
+	// Buckets is the number of buckets the window is split into
+	Buckets int
+	// MinRequests is the number of requests in the window before the breaker can trip
+	MinRequests int
+	// ErrorThreshold is the error rate between 0 and 1 at which the breaker trips
+	ErrorThreshold float64
+	// LatencyThreshold is the mean latency at which the breaker trips, zero disables it
+	LatencyThreshold time.Duration
+	// SleepWindow is how long the breaker stays open before probing the node
+	SleepWindow time.Duration
+	// Probes is the number of requests let through while half open
+	Probes int
+}
+
+// OutlierOptions configure outlier detection which is fed by Mark
+// and ejects nodes from Select for an exponentially growing time
+type OutlierOptions struct {
+	// Disabled turns off outlier detection
+	Disabled bool
+	// ConsecutiveErrors is the number of errors in a row which ejects a node, zero disables it
+	ConsecutiveErrors int
+	// LatencyFactor is how many times the median latency of its peers a node
+	// must be over to be ejected, zero disables it
+	LatencyFactor float64
+	// MinRequests is the number of requests to a node before its latency is judged
+	MinRequests int
+	// BaseEjectionTime is how long a node is first ejected for, it doubles each time
+	BaseEjectionTime time.Duration
+	// MaxEjectionTime is the longest a node is ejected for
+	MaxEjectionTime time.Duration
+	// MaxEjectionPercent is the share of a service's nodes which can be ejected
+	MaxEjectionPercent int
+}
+
+// TopologyOptions are the location of the caller. The selector prefers
+// nodes in the same zone and region, going by their metadata.
+type TopologyOptions struct {
+	// Region of the caller
+	Region string
+	// Zone of the caller
+	Zone string
+	// Threshold is the share of local nodes which must be healthy before spilling over
+	Threshold float64
+}
+
 type SelectOptions struct {
 	Filters  []Filter
 	Strategy Strategy
+	Balancer Balancer
+	// Key is used by key aware balancers e.g consistent hashing
+	Key string
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -44,6 +108,138 @@ func SetStrategy(fn Strategy) Option {
 	}
 }
 
+// SetBalancer sets the default balancer for the selector. It takes
+// precedence over the strategy unless one is passed to Select.
+func SetBalancer(b Balancer) Option {
+	return func(o *Options) {
+		o.Balancer = b
+	}
+}
+
+// BreakerWindow sets the rolling window the breaker tracks errors and latency over
+func BreakerWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.Breaker.Window = d
+	}
+}
+
+// BreakerMinRequests sets the number of requests in the window before the breaker can trip
+func BreakerMinRequests(n int) Option {
+	return func(o *Options) {
+		o.Breaker.MinRequests = n
+	}
+}
+
+// BreakerErrorThreshold sets the error rate between 0 and 1 at which the breaker trips
+func BreakerErrorThreshold(rate float64) Option {
+	return func(o *Options) {
+		o.Breaker.ErrorThreshold = rate
+	}
+}
+
+// BreakerLatencyThreshold sets the mean latency at which the breaker trips
+func BreakerLatencyThreshold(d time.Duration) Option {
+	return func(o *Options) {
+		o.Breaker.LatencyThreshold = d
+	}
+}
+
+// BreakerSleepWindow sets how long the breaker stays open before probing the node
+func BreakerSleepWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.Breaker.SleepWindow = d
+	}
+}
+
+// BreakerProbes sets the number of requests let through while half open
+func BreakerProbes(n int) Option {
+	return func(o *Options) {
+		o.Breaker.Probes = n
+	}
+}
+
+// EnableBreaker turns on the per node circuit breaker
+func EnableBreaker() Option {
+	return func(o *Options) {
+		o.Breaker.Disabled = false
+	}
+}
+
+// DisableBreaker turns off the per node circuit breaker
+func DisableBreaker() Option {
+	return func(o *Options) {
+		o.Breaker.Disabled = true
+	}
+}
+
+// OutlierConsecutiveErrors sets the number of errors in a row which ejects a node
+func OutlierConsecutiveErrors(n int) Option {
+	return func(o *Options) {
+		o.Outlier.ConsecutiveErrors = n
+	}
+}
+
+// OutlierLatencyFactor sets how many times the median latency
+// of its peers a node must be over to be ejected
+func OutlierLatencyFactor(f float64) Option {
+	return func(o *Options) {
+		o.Outlier.LatencyFactor = f
+	}
+}
+
+// OutlierMinRequests sets the number of requests to a node before its latency is judged
+func OutlierMinRequests(n int) Option {
+	return func(o *Options) {
+		o.Outlier.MinRequests = n
+	}
+}
+
+// OutlierEjectionTime sets how long a node is first ejected for and the longest it can be
+func OutlierEjectionTime(base, max time.Duration) Option {
+	return func(o *Options) {
+		o.Outlier.BaseEjectionTime = base
+		o.Outlier.MaxEjectionTime = max
+	}
+}
+
+// OutlierMaxEjectionPercent sets the share of a service's nodes which can be ejected
+func OutlierMaxEjectionPercent(p int) Option {
+	return func(o *Options) {
+		o.Outlier.MaxEjectionPercent = p
+	}
+}
+
+// EnableOutlier turns on outlier detection
+func EnableOutlier() Option {
+	return func(o *Options) {
+		o.Outlier.Disabled = false
+	}
+}
+
+// DisableOutlier turns off outlier detection
+func DisableOutlier() Option {
+	return func(o *Options) {
+		o.Outlier.Disabled = true
+	}
+}
+
+// Topology sets the region and zone of the caller
+// so the selector prefers nodes in the same location
+func Topology(region, zone string) Option {
+	return func(o *Options) {
+		o.Topology.Region = region
+		o.Topology.Zone = zone
+	}
+}
+
+// TopologyThreshold sets the share of local nodes which
+// must be healthy before spilling over to other zones
+func TopologyThreshold(t float64) Option {
+	return func(o *Options) {
+		o.Topology.Threshold = t
+	}
+}
+
 // WithFilter adds a filter function to the list of filters
 // used during the Select call.
 func WithFilter(fn ...Filter) SelectOption {
@@ -58,3 +254,17 @@ func WithStrategy(fn Strategy) SelectOption {
 		o.Strategy = fn
 	}
 }
+
+// WithBalancer sets the balancer used for the Select call
+func WithBalancer(b Balancer) SelectOption {
+	return func(o *SelectOptions) {
+		o.Balancer = b
+	}
+}
+
+// WithKey sets the key used by key aware balancers e.g consistent hashing
+func WithKey(key string) SelectOption {
+	return func(o *SelectOptions) {
+		o.Key = key
+	}
+}
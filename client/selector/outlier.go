@@ -0,0 +1,351 @@
+package selector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var (
+	// weight of the latest latency in the moving average of a node
+	outlierDecay = 0.2
+)
+
+// Ejection is a node ejected from selection by outlier detection
+type Ejection struct {
+	// Service the node belongs to
+	Service string
+	// Node id
+	Node string
+	// Address of the node
+	Address string
+	// Reason the node was ejected
+	Reason string
+	// Ejections is the number of times in a row the node was ejected
+	Ejections int
+	// Started is when the node was ejected
+	Started time.Time
+	// Until is when the node is returned to selection
+	Until time.Time
+}
+
+// ejector is implemented by selectors which eject outliers
+type ejector interface {
+	Ejections() []Ejection
+}
+
+// Ejections returns the nodes currently ejected by the selector.
+// It returns nothing for selectors without outlier detection.
+func Ejections(s Selector) []Ejection {
+	e, ok := s.(ejector)
+	if !ok {
+		return nil
+	}
+	return e.Ejections()
+}
+
+// outliers tracks consecutive errors and latency per node
+// for every service it has seen and ejects the outliers
+type outliers struct {
+	sync.Mutex
+	opts OutlierOptions
+	// service name -> node id -> outlier
+	nodes map[string]map[string]*outlier
+	// service name -> number of nodes last selected from
+	sizes map[string]int
+}
+
+// outlier is the outlier detection state of a single node
+type outlier struct {
+	address string
+	// consecutive failures
+	errors int
+	// moving average of the latency
+	latency time.Duration
+	// number of latencies recorded
+	samples int
+	// ejection state
+	reason    string
+	ejections int
+	started   time.Time
+	until     time.Time
+}
+
+func newOutliers(opts OutlierOptions) *outliers {
+	return &outliers{
+		opts:  opts,
+		nodes: make(map[string]map[string]*outlier),
+		sizes: make(map[string]int),
+	}
+}
+
+func (o *outliers) init(opts OutlierOptions) {
+	o.Lock()
+	o.opts = opts
+	o.nodes = make(map[string]map[string]*outlier)
+	o.sizes = make(map[string]int)
+	o.Unlock()
+}
+
+func (o *outliers) outlier(service string, node *registry.Node) *outlier {
+	nodes, ok := o.nodes[service]
+	if !ok {
+		nodes = make(map[string]*outlier)
+		o.nodes[service] = nodes
+	}
+	n, ok := nodes[node.Id]
+	if !ok {
+		n = &outlier{address: node.Address}
+		nodes[node.Id] = n
+	}
+	return n
+}
+
+// ejected returns true if the node is out of selection
+func (n *outlier) ejected(now time.Time) bool {
+	return now.Before(n.until)
+}
+
+// filter removes the ejected nodes. If that
+// leaves nothing the services are returned as is.
+func (o *outliers) filter(old []*registry.Service) []*registry.Service {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.opts.Disabled {
+		return old
+	}
+
+	now := time.Now()
+
+	var services []*registry.Service
+
+	for _, service := range old {
+		nodes := o.nodes[service.Name]
+		// nothing tracked yet
+		if len(nodes) == 0 {
+			services = append(services, service)
+			continue
+		}
+
+		var available []*registry.Node
+
+		for _, node := range service.Nodes {
+			n, ok := nodes[node.Id]
+			if !ok || !n.ejected(now) {
+				available = append(available, node)
+			}
+		}
+
+		if len(available) == 0 {
+			continue
+		}
+
+		serv := new(registry.Service)
+		*serv = *service
+		serv.Nodes = available
+		services = append(services, serv)
+	}
+
+	if len(services) == 0 {
+		return old
+	}
+
+	return services
+}
+
+// mark records the result and latency of a request to a node
+// and ejects it if it has become an outlier
+func (o *outliers) mark(service string, node *registry.Node, err error, latency time.Duration) {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.opts.Disabled {
+		return
+	}
+
+	now := time.Now()
+	n := o.outlier(service, node)
+
+	// late response from before the node was ejected
+	if n.ejected(now) {
+		return
+	}
+
+	if failure(err) {
+		n.errors++
+		if o.opts.ConsecutiveErrors > 0 && n.errors >= o.opts.ConsecutiveErrors {
+			o.eject(service, n, "consecutive errors", now)
+		}
+		return
+	}
+
+	n.errors = 0
+
+	// only successful calls count towards the latency
+	if err != nil || latency <= 0 {
+		return
+	}
+
+	if n.samples == 0 {
+		n.latency = latency
+	} else {
+		n.latency = time.Duration(outlierDecay*float64(latency) + (1-outlierDecay)*float64(n.latency))
+	}
+	n.samples++
+
+	if o.slow(service, n, now) {
+		o.eject(service, n, "latency", now)
+	}
+}
+
+// slow returns true if the node's latency is far above the median of its peers
+func (o *outliers) slow(service string, n *outlier, now time.Time) bool {
+	if o.opts.LatencyFactor <= 0 || n.samples < o.opts.MinRequests {
+		return false
+	}
+
+	var peers []time.Duration
+	for _, p := range o.nodes[service] {
+		if p == n || p.ejected(now) || p.samples < o.opts.MinRequests {
+			continue
+		}
+		peers = append(peers, p.latency)
+	}
+
+	// too few peers to tell what normal looks like
+	if len(peers) < 2 {
+		return false
+	}
+
+	sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+	median := peers[len(peers)/2]
+
+	return float64(n.latency) > o.opts.LatencyFactor*float64(median)
+}
+
+// max returns the number of nodes which can be ejected for a service.
+// At least one node can be ejected but never the whole pool.
+func (o *outliers) max(service string) int {
+	size := o.sizes[service]
+	if size == 0 {
+		size = len(o.nodes[service])
+	}
+	max := size * o.opts.MaxEjectionPercent / 100
+	if max < 1 {
+		max = 1
+	}
+	if max > size-1 {
+		max = size - 1
+	}
+	return max
+}
+
+// eject takes the node out of selection for the base ejection time, doubled
+// for each time in a row it was ejected and capped at the max ejection time.
+// The count starts over once the node behaves for the max ejection time.
+func (o *outliers) eject(service string, n *outlier, reason string, now time.Time) {
+	var ejected int
+	for _, p := range o.nodes[service] {
+		if p.ejected(now) {
+			ejected++
+		}
+	}
+
+	if ejected >= o.max(service) {
+		return
+	}
+
+	// the node behaved since its last ejection so start over
+	if !n.until.IsZero() && now.Sub(n.until) > o.opts.MaxEjectionTime {
+		n.ejections = 0
+	}
+	n.ejections++
+
+	d := o.opts.BaseEjectionTime
+	for i := 1; i < n.ejections && d < o.opts.MaxEjectionTime; i++ {
+		d *= 2
+	}
+	if o.opts.MaxEjectionTime > 0 && d > o.opts.MaxEjectionTime {
+		d = o.opts.MaxEjectionTime
+	}
+
+	n.reason = reason
+	n.started = now
+	n.until = now.Add(d)
+	n.errors = 0
+	n.samples = 0
+}
+
+// prune drops the state of nodes which have left the service
+// and records the size of the pool for the max ejection percent
+func (o *outliers) prune(service string, services []*registry.Service) {
+	o.Lock()
+	defer o.Unlock()
+
+	current := make(map[string]bool)
+	for _, s := range services {
+		for _, n := range s.Nodes {
+			current[n.Id] = true
+		}
+	}
+
+	o.sizes[service] = len(current)
+
+	nodes, ok := o.nodes[service]
+	if !ok {
+		return
+	}
+
+	for id := range nodes {
+		if !current[id] {
+			delete(nodes, id)
+		}
+	}
+}
+
+// reset drops all the state for a service
+func (o *outliers) reset(service string) {
+	o.Lock()
+	delete(o.nodes, service)
+	delete(o.sizes, service)
+	o.Unlock()
+}
+
+// ejections returns the nodes currently ejected
+func (o *outliers) ejections() []Ejection {
+	o.Lock()
+	defer o.Unlock()
+
+	now := time.Now()
+
+	var ejections []Ejection
+
+	for service, nodes := range o.nodes {
+		for id, n := range nodes {
+			if !n.ejected(now) {
+				continue
+			}
+			ejections = append(ejections, Ejection{
+				Service:   service,
+				Node:      id,
+				Address:   n.address,
+				Reason:    n.reason,
+				Ejections: n.ejections,
+				Started:   n.started,
+				Until:     n.until,
+			})
+		}
+	}
+
+	sort.Slice(ejections, func(i, j int) bool {
+		if ejections[i].Service != ejections[j].Service {
+			return ejections[i].Service < ejections[j].Service
+		}
+		return ejections[i].Node < ejections[j].Node
+	})
+
+	return ejections
+}
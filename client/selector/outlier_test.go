@@ -0,0 +1,158 @@
+package selector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+func TestOutlierConsecutiveErrors(t *testing.T) {
+	service := &registry.Service{Name: "foo"}
+	for i := 0; i < 4; i++ {
+		service.Nodes = append(service.Nodes, &registry.Node{
+			Id:      fmt.Sprintf("foo-%d", i),
+			Address: fmt.Sprintf("10.0.0.%d:8080", i),
+		})
+	}
+	services := []*registry.Service{service}
+
+	o := newOutliers(OutlierOptions{
+		ConsecutiveErrors:  3,
+		BaseEjectionTime:   time.Minute,
+		MaxEjectionTime:    time.Minute * 3,
+		MaxEjectionPercent: 50,
+	})
+	o.prune("foo", services)
+
+	fail := errors.InternalServerError("foo", "failed")
+
+	// errors which aren't consecutive don't eject
+	for i := 0; i < 5; i++ {
+		o.mark("foo", service.Nodes[0], fail, 0)
+		o.mark("foo", service.Nodes[0], nil, 0)
+	}
+	if e := o.ejections(); len(e) != 0 {
+		t.Fatalf("Expected no ejections got %+v", e)
+	}
+
+	// client errors don't count
+	for i := 0; i < 5; i++ {
+		o.mark("foo", service.Nodes[0], errors.BadRequest("foo", "bad"), 0)
+	}
+	if e := o.ejections(); len(e) != 0 {
+		t.Fatalf("Expected no ejections got %+v", e)
+	}
+
+	for i := 0; i < 3; i++ {
+		o.mark("foo", service.Nodes[0], fail, 0)
+	}
+
+	e := o.ejections()
+	if len(e) != 1 || e[0].Node != "foo-0" || e[0].Address != "10.0.0.0:8080" || e[0].Ejections != 1 {
+		t.Fatalf("Expected foo-0 to be ejected got %+v", e)
+	}
+	if d := e[0].Until.Sub(e[0].Started); d != time.Minute {
+		t.Fatalf("Expected ejection for 1m got %v", d)
+	}
+
+	filtered := o.filter(services)
+	if len(filtered[0].Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes got %d", len(filtered[0].Nodes))
+	}
+	for _, node := range filtered[0].Nodes {
+		if node.Id == "foo-0" {
+			t.Fatal("Expected foo-0 to be filtered")
+		}
+	}
+
+	// a second node can be ejected but not a third
+	for _, node := range service.Nodes[1:] {
+		for i := 0; i < 3; i++ {
+			o.mark("foo", node, fail, 0)
+		}
+	}
+	if e := o.ejections(); len(e) != 2 {
+		t.Fatalf("Expected 2 ejections got %+v", e)
+	}
+
+	// the ejection time doubles each time
+	n := o.nodes["foo"]["foo-0"]
+	for i, want := range []time.Duration{time.Minute * 2, time.Minute * 3} {
+		n.until = time.Now()
+		for j := 0; j < 3; j++ {
+			o.mark("foo", service.Nodes[0], fail, 0)
+		}
+		if n.ejections != i+2 {
+			t.Fatalf("Expected %d ejections got %d", i+2, n.ejections)
+		}
+		if d := n.until.Sub(n.started); d != want {
+			t.Fatalf("Expected ejection for %v got %v", want, d)
+		}
+	}
+}
+
+func TestOutlierLatency(t *testing.T) {
+	service := &registry.Service{Name: "foo"}
+	for i := 0; i < 4; i++ {
+		service.Nodes = append(service.Nodes, &registry.Node{
+			Id: fmt.Sprintf("foo-%d", i),
+		})
+	}
+	services := []*registry.Service{service}
+
+	o := newOutliers(OutlierOptions{
+		LatencyFactor:      3,
+		MinRequests:        5,
+		BaseEjectionTime:   time.Minute,
+		MaxEjectionTime:    time.Minute * 5,
+		MaxEjectionPercent: 10,
+	})
+	o.prune("foo", services)
+
+	for i := 0; i < 10; i++ {
+		for _, node := range service.Nodes[1:] {
+			o.mark("foo", node, nil, time.Millisecond*10)
+		}
+		o.mark("foo", service.Nodes[0], nil, time.Millisecond*100)
+	}
+
+	e := o.ejections()
+	if len(e) != 1 || e[0].Node != "foo-0" || e[0].Reason != "latency" {
+		t.Fatalf("Expected foo-0 to be ejected for latency got %+v", e)
+	}
+
+	// the whole pool is never ejected
+	o.nodes["foo"]["foo-1"].until = time.Now().Add(time.Minute)
+	o.nodes["foo"]["foo-2"].until = time.Now().Add(time.Minute)
+	o.nodes["foo"]["foo-3"].until = time.Now().Add(time.Minute)
+
+	if filtered := o.filter(services); len(filtered[0].Nodes) != 4 {
+		t.Fatalf("Expected all nodes got %d", len(filtered[0].Nodes))
+	}
+}
+
+func TestOutlierPrune(t *testing.T) {
+	o := newOutliers(OutlierOptions{ConsecutiveErrors: 3})
+
+	o.mark("foo", &registry.Node{Id: "foo-1"}, nil, 0)
+
+	// the node is replaced by another keeping the count the same
+	o.prune("foo", []*registry.Service{{Nodes: []*registry.Node{{Id: "foo-2"}}}})
+
+	if _, ok := o.nodes["foo"]["foo-1"]; ok {
+		t.Fatal("Expected the state of the replaced node to be dropped")
+	}
+}
+
+func TestOutlierDefault(t *testing.T) {
+	sel := NewSelector()
+	if !sel.Options().Outlier.Disabled {
+		t.Fatal("Expected outlier detection to be off by default")
+	}
+	if NewSelector(EnableOutlier()).Options().Outlier.Disabled {
+		t.Fatal("Expected outlier detection to be turned on")
+	}
+}
@@ -0,0 +1,140 @@
+package selector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// maxPending is the max number of calls in flight tracked per node
+const maxPending = 1024
+
+// call is a call in flight to a node
+type call struct {
+	// time the node was picked
+	started time.Time
+	// the balancer which picked the node
+	balancer Balancer
+}
+
+// releaser is implemented by selectors which can drop a call
+// without recording a result against the node
+type releaser interface {
+	Release(service string, node *registry.Node)
+}
+
+// Release drops the bookkeeping for a call to a node which was abandoned
+// by the caller, e.g. a hedge cancelled because another request won.
+// Nothing is recorded against the node. It's a no-op for selectors
+// which don't track calls in flight.
+func Release(s Selector, service string, node *registry.Node) {
+	r, ok := s.(releaser)
+	if !ok {
+		return
+	}
+	r.Release(service, node)
+}
+
+// pending tracks the calls in flight so that Mark can work out their
+// latency and who picked the node. Each call is handed its own copy of
+// the node so it's paired with its Mark however the calls finish.
+type pending struct {
+	sync.Mutex
+	// service name -> node id -> the node of each call in flight
+	calls map[string]map[string]map[*registry.Node]call
+}
+
+func newPending() *pending {
+	return &pending{
+		calls: make(map[string]map[string]map[*registry.Node]call),
+	}
+}
+
+// start records a call to a node returning the node to hand to the caller
+func (p *pending) start(service string, node *registry.Node, b Balancer) *registry.Node {
+	p.Lock()
+	defer p.Unlock()
+
+	nodes, ok := p.calls[service]
+	if !ok {
+		nodes = make(map[string]map[*registry.Node]call)
+		p.calls[service] = nodes
+	}
+
+	calls, ok := nodes[node.Id]
+	if !ok {
+		calls = make(map[*registry.Node]call)
+		nodes[node.Id] = calls
+	}
+
+	// drop the oldest call if Mark is never called
+	if len(calls) >= maxPending {
+		var oldest *registry.Node
+		for n, c := range calls {
+			if oldest == nil || c.started.Before(calls[oldest].started) {
+				oldest = n
+			}
+		}
+		delete(calls, oldest)
+	}
+
+	n := *node
+	calls[&n] = call{time.Now(), b}
+	return &n
+}
+
+// done returns the call the node was handed to
+func (p *pending) done(service string, node *registry.Node) (call, bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	nodes, ok := p.calls[service]
+	if !ok {
+		return call{}, false
+	}
+
+	calls := nodes[node.Id]
+	c, ok := calls[node]
+	if !ok {
+		return call{}, false
+	}
+
+	delete(calls, node)
+	if len(calls) == 0 {
+		delete(nodes, node.Id)
+	}
+
+	return c, true
+}
+
+// prune drops the calls in flight to the nodes which have gone away
+func (p *pending) prune(service string, services []*registry.Service) {
+	p.Lock()
+	defer p.Unlock()
+
+	nodes, ok := p.calls[service]
+	if !ok {
+		return
+	}
+
+	current := make(map[string]bool)
+	for _, s := range services {
+		for _, n := range s.Nodes {
+			current[n.Id] = true
+		}
+	}
+
+	for id := range nodes {
+		if !current[id] {
+			delete(nodes, id)
+		}
+	}
+}
+
+// reset drops the calls in flight for a service
+func (p *pending) reset(service string) {
+	p.Lock()
+	delete(p.calls, service)
+	p.Unlock()
+}
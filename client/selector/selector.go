@@ -3,6 +3,7 @@ package selector
 
 import (
 	"errors"
+	"time"
 
 	"github.com/micro/go-micro/v2/registry"
 )
@@ -35,9 +36,48 @@ type Filter func([]*registry.Service) []*registry.Service
 // Strategy is a selection strategy e.g random, round robin
 type Strategy func([]*registry.Service) Next
 
+// Balancer is a stateful selection strategy. Unlike a Strategy it keeps
+// per node state between Select calls which is updated through Mark.
+type Balancer interface {
+	// Select returns a function which picks the next node from the services
+	Select(services []*registry.Service, opts SelectOptions) Next
+	// Mark records the result and latency of a call to a node
+	Mark(service string, node *registry.Node, err error, latency time.Duration)
+	// Reset discards the state held for a service
+	Reset(service string)
+	// Name of the balancer
+	String() string
+}
+
 var (
 	DefaultSelector = NewSelector()
 
 	ErrNotFound      = errors.New("not found")
 	ErrNoneAvailable = errors.New("none available")
+	// ErrCircuitOpen is returned when the circuit is open for every node
+	ErrCircuitOpen = errors.New("circuit open")
+
+	// DefaultBreaker is the default circuit breaker config, the
+	// breaker is off until turned on with EnableBreaker
+	DefaultBreaker = BreakerOptions{
+		Disabled:       true,
+		Window:         time.Second * 10,
+		Buckets:        10,
+		MinRequests:    20,
+		ErrorThreshold: 0.5,
+		SleepWindow:    time.Second * 5,
+		Probes:         1,
+	}
+
+	// DefaultOutlier is the default outlier detection config, detection
+	// is off until turned on with EnableOutlier
+	DefaultOutlier = OutlierOptions{
+		Disabled:           true,
+		ConsecutiveErrors:  5,
+		LatencyFactor:      3,
+		MinRequests:        10,
+		BaseEjectionTime:   time.Second * 30,
+		MaxEjectionTime:    time.Minute * 5,
+		MaxEjectionPercent: 10,
+	}
 )
@@ -0,0 +1,144 @@
+package selector
+
+import (
+	"sync/atomic"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var (
+	// ZoneKey is the node metadata key holding its zone
+	ZoneKey = "zone"
+	// RegionKey is the node metadata key holding its region
+	RegionKey = "region"
+
+	// DefaultTopologyThreshold is the share of local nodes which
+	// must be healthy before spilling over to other zones
+	DefaultTopologyThreshold = 0.5
+
+	// counters for the nodes picked by topology
+	localSelections       uint64
+	crossZoneSelections   uint64
+	crossRegionSelections uint64
+)
+
+// TopologyStats are counters of the nodes picked relative to the caller
+type TopologyStats struct {
+	// Local is the number of nodes picked in the same zone
+	Local uint64
+	// CrossZone is the number of nodes picked in another zone of the same region
+	CrossZone uint64
+	// CrossRegion is the number of nodes picked in another region
+	CrossRegion uint64
+}
+
+// ReadTopologyStats returns the topology counters of all the selectors
+func ReadTopologyStats() TopologyStats {
+	return TopologyStats{
+		Local:       atomic.LoadUint64(&localSelections),
+		CrossZone:   atomic.LoadUint64(&crossZoneSelections),
+		CrossRegion: atomic.LoadUint64(&crossRegionSelections),
+	}
+}
+
+func (t TopologyOptions) enabled() bool {
+	return len(t.Zone) > 0 || len(t.Region) > 0
+}
+
+// inZone returns true if the node is in the caller's zone
+func (t TopologyOptions) inZone(node *registry.Node) bool {
+	if len(t.Zone) == 0 {
+		return t.inRegion(node)
+	}
+	return node.Metadata != nil && node.Metadata[ZoneKey] == t.Zone && t.inRegion(node)
+}
+
+// inRegion returns true if the node is in the caller's region
+func (t TopologyOptions) inRegion(node *registry.Node) bool {
+	if len(t.Region) == 0 {
+		return true
+	}
+	return node.Metadata != nil && node.Metadata[RegionKey] == t.Region
+}
+
+// count returns the number of nodes matching fn
+func count(services []*registry.Service, fn func(*registry.Node) bool) int {
+	var n int
+	for _, service := range services {
+		for _, node := range service.Nodes {
+			if fn(node) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// only returns the services with only the nodes matching fn
+func only(old []*registry.Service, fn func(*registry.Node) bool) []*registry.Service {
+	var services []*registry.Service
+
+	for _, service := range old {
+		var nodes []*registry.Node
+		for _, node := range service.Nodes {
+			if fn(node) {
+				nodes = append(nodes, node)
+			}
+		}
+
+		if len(nodes) == 0 {
+			continue
+		}
+
+		serv := new(registry.Service)
+		*serv = *service
+		serv.Nodes = nodes
+		services = append(services, serv)
+	}
+
+	return services
+}
+
+// local returns true if enough of the matching nodes are healthy
+func (t TopologyOptions) local(all, healthy []*registry.Service, fn func(*registry.Node) bool) bool {
+	total := count(all, fn)
+	if total == 0 {
+		return false
+	}
+	up := count(healthy, fn)
+	return up > 0 && float64(up)/float64(total) >= t.Threshold
+}
+
+// filter prefers the healthy nodes in the caller's zone, then those in
+// its region and only spills over to the rest when there's not enough
+func (t TopologyOptions) filter(all, healthy []*registry.Service) []*registry.Service {
+	if !t.enabled() {
+		return healthy
+	}
+
+	if t.local(all, healthy, t.inZone) {
+		return only(healthy, t.inZone)
+	}
+
+	if len(t.Zone) > 0 && len(t.Region) > 0 && t.local(all, healthy, t.inRegion) {
+		return only(healthy, t.inRegion)
+	}
+
+	return healthy
+}
+
+// picked counts the node against the topology stats
+func (t TopologyOptions) picked(node *registry.Node) {
+	if !t.enabled() {
+		return
+	}
+
+	switch {
+	case t.inZone(node):
+		atomic.AddUint64(&localSelections, 1)
+	case t.inRegion(node):
+		atomic.AddUint64(&crossZoneSelections, 1)
+	default:
+		atomic.AddUint64(&crossRegionSelections, 1)
+	}
+}
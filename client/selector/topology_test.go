@@ -0,0 +1,90 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestTopology(t *testing.T) {
+	node := func(id, region, zone string) *registry.Node {
+		return &registry.Node{
+			Id:      id,
+			Address: id,
+			Metadata: map[string]string{
+				RegionKey: region,
+				ZoneKey:   zone,
+			},
+		}
+	}
+
+	services := map[string][]*registry.Service{
+		"foo": {
+			{
+				Name:    "foo",
+				Version: "latest",
+				Nodes: []*registry.Node{
+					node("a-1", "eu", "a"),
+					node("a-2", "eu", "a"),
+					node("b-1", "eu", "b"),
+					node("c-1", "us", "c"),
+				},
+			},
+		},
+	}
+
+	r := memory.NewRegistry(memory.Services(services))
+	sel := NewSelector(Registry(r), Topology("eu", "a"), TopologyThreshold(0.3), EnableBreaker(), BreakerMinRequests(1))
+
+	pick := func() map[string]int {
+		counts := make(map[string]int)
+		next, err := sel.Select("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			n, err := next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[n.Id]++
+			sel.Mark("foo", n, nil)
+		}
+		return counts
+	}
+
+	before := ReadTopologyStats()
+
+	// only the local zone
+	counts := pick()
+	if counts["a-1"]+counts["a-2"] != 100 {
+		t.Fatalf("Expected only local nodes got %v", counts)
+	}
+
+	fail := func(n *registry.Node) {
+		for i := 0; i < 1000; i++ {
+			sel.Mark("foo", n, errors.InternalServerError("foo", "failed"))
+		}
+	}
+
+	// half the zone is healthy which is still enough
+	fail(node("a-1", "eu", "a"))
+	counts = pick()
+	if counts["a-2"] != 100 {
+		t.Fatalf("Expected only a-2 got %v", counts)
+	}
+
+	// the zone is down so spill over to the region
+	fail(node("a-2", "eu", "a"))
+	counts = pick()
+	if counts["b-1"] != 100 {
+		t.Fatalf("Expected only b-1 got %v", counts)
+	}
+
+	after := ReadTopologyStats()
+	if after.Local-before.Local != 200 || after.CrossZone-before.CrossZone != 100 {
+		t.Fatalf("Expected 200 local and 100 cross zone selections got %+v", after)
+	}
+}
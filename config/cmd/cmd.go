@@ -41,6 +41,7 @@ import (
 	smucp "github.com/micro/go-micro/v2/server/mucp"
 
 	// brokers
+	brokerFile "github.com/micro/go-micro/v2/broker/file"
 	brokerHttp "github.com/micro/go-micro/v2/broker/http"
 	"github.com/micro/go-micro/v2/broker/memory"
 	"github.com/micro/go-micro/v2/broker/nats"
@@ -334,6 +335,7 @@ var (
 		"memory":  memory.NewBroker,
 		"nats":    nats.NewBroker,
 		"http":    brokerHttp.NewBroker,
+		"file":    brokerFile.NewBroker,
 	}
 
 	DefaultClients = map[string]func(...client.Option) client.Client{
@@ -478,7 +480,7 @@ func (c *cmd) Before(ctx *cli.Context) error {
 	// after the cache client since the wrappers are applied in reverse order and the cache will use
 	// some of the headers set by the auth client.
 	authFn := func() auth.Auth { return *c.opts.Auth }
-	cacheFn := func() *client.Cache { return (*c.opts.Client).Options().Cache }
+	cacheFn := func() client.Cache { return (*c.opts.Client).Options().Cache }
 	microClient := wrapper.CacheClient(cacheFn, grpc.NewClient())
 	microClient = wrapper.AuthClient(authFn, microClient)
 
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/client/selector"
 	"github.com/micro/go-micro/v2/debug/log"
 	proto "github.com/micro/go-micro/v2/debug/service/proto"
 	"github.com/micro/go-micro/v2/debug/stats"
@@ -16,10 +17,11 @@ import (
 // NewHandler returns an instance of the Debug Handler
 func NewHandler(c client.Client) *Debug {
 	return &Debug{
-		log:   log.DefaultLog,
-		stats: stats.DefaultStats,
-		trace: trace.DefaultTracer,
-		cache: c.Options().Cache,
+		log:      log.DefaultLog,
+		stats:    stats.DefaultStats,
+		trace:    trace.DefaultTracer,
+		cache:    c.Options().Cache,
+		selector: c.Options().Selector,
 	}
 }
 
@@ -33,7 +35,9 @@ type Debug struct {
 	// the tracer
 	trace trace.Tracer
 	// the cache
-	cache *client.Cache
+	cache client.Cache
+	// the client selector
+	selector selector.Selector
 }
 
 func (d *Debug) Health(ctx context.Context, req *proto.HealthRequest, rsp *proto.HealthResponse) error {
@@ -61,6 +65,38 @@ func (d *Debug) Stats(ctx context.Context, req *proto.StatsRequest, rsp *proto.S
 	rsp.Requests = stats[0].Requests
 	rsp.Errors = stats[0].Errors
 
+	// the live concurrency limits
+	for endpoint, l := range server.ReadLimits() {
+		if rsp.Limits == nil {
+			rsp.Limits = make(map[string]*proto.Limit)
+		}
+		rsp.Limits[endpoint] = &proto.Limit{
+			Limit:    l.Limit,
+			Inflight: l.Inflight,
+			Rejected: l.Rejected,
+		}
+	}
+
+	// the endpoints which ran over their timeout
+	for endpoint, t := range server.ReadTimeouts() {
+		if rsp.Timeouts == nil {
+			rsp.Timeouts = make(map[string]*proto.Timeout)
+		}
+		rsp.Timeouts[endpoint] = &proto.Timeout{
+			Timeout:  uint64(t.Timeout),
+			Exceeded: t.Exceeded,
+			Longest:  uint64(t.Longest),
+		}
+	}
+
+	// the nodes picked relative to our zone and region
+	topology := selector.ReadTopologyStats()
+	rsp.Topology = &proto.Topology{
+		Local:       topology.Local,
+		CrossZone:   topology.CrossZone,
+		CrossRegion: topology.CrossRegion,
+	}
+
 	return nil
 }
 
@@ -174,3 +210,24 @@ func (d *Debug) Cache(ctx context.Context, req *proto.CacheRequest, rsp *proto.C
 	rsp.Values = d.cache.List()
 	return nil
 }
+
+// Outliers returns the nodes ejected from selection by the client
+func (d *Debug) Outliers(ctx context.Context, req *proto.OutliersRequest, rsp *proto.OutliersResponse) error {
+	if d.selector == nil {
+		return nil
+	}
+
+	for _, e := range selector.Ejections(d.selector) {
+		rsp.Ejections = append(rsp.Ejections, &proto.Ejection{
+			Service:   e.Service,
+			Node:      e.Node,
+			Address:   e.Address,
+			Reason:    e.Reason,
+			Ejections: int64(e.Ejections),
+			Started:   uint64(e.Started.UnixNano()),
+			Until:     uint64(e.Until.UnixNano()),
+		})
+	}
+
+	return nil
+}
@@ -185,10 +185,16 @@ type StatsResponse struct {
 	// total number of requests
 	Requests uint64 `protobuf:"varint,7,opt,name=requests,proto3" json:"requests,omitempty"`
 	// total number of errors
-	Errors               uint64   `protobuf:"varint,8,opt,name=errors,proto3" json:"errors,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Errors uint64 `protobuf:"varint,8,opt,name=errors,proto3" json:"errors,omitempty"`
+	// concurrency limits by endpoint, * for the server
+	Limits map[string]*Limit `protobuf:"bytes,9,rep,name=limits,proto3" json:"limits,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// requests over their timeout by endpoint
+	Timeouts map[string]*Timeout `protobuf:"bytes,10,rep,name=timeouts,proto3" json:"timeouts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// nodes picked by the client relative to its zone and region
+	Topology             *Topology `protobuf:"bytes,11,opt,name=topology,proto3" json:"topology,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
 func (m *StatsResponse) Reset()         { *m = StatsResponse{} }
@@ -272,6 +278,203 @@ func (m *StatsResponse) GetErrors() uint64 {
 	return 0
 }
 
+func (m *StatsResponse) GetLimits() map[string]*Limit {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+func (m *StatsResponse) GetTimeouts() map[string]*Timeout {
+	if m != nil {
+		return m.Timeouts
+	}
+	return nil
+}
+
+func (m *StatsResponse) GetTopology() *Topology {
+	if m != nil {
+		return m.Topology
+	}
+	return nil
+}
+
+// Limit is the live value of a concurrency limiter
+type Limit struct {
+	// requests allowed in flight
+	Limit uint64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// requests in flight
+	Inflight uint64 `protobuf:"varint,2,opt,name=inflight,proto3" json:"inflight,omitempty"`
+	// total requests rejected
+	Rejected             uint64   `protobuf:"varint,3,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Limit) Reset()         { *m = Limit{} }
+func (m *Limit) String() string { return proto.CompactTextString(m) }
+func (*Limit) ProtoMessage()    {}
+func (*Limit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{4}
+}
+
+func (m *Limit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Limit.Unmarshal(m, b)
+}
+func (m *Limit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Limit.Marshal(b, m, deterministic)
+}
+func (m *Limit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Limit.Merge(m, src)
+}
+func (m *Limit) XXX_Size() int {
+	return xxx_messageInfo_Limit.Size(m)
+}
+func (m *Limit) XXX_DiscardUnknown() {
+	xxx_messageInfo_Limit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Limit proto.InternalMessageInfo
+
+func (m *Limit) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *Limit) GetInflight() uint64 {
+	if m != nil {
+		return m.Inflight
+	}
+	return 0
+}
+
+func (m *Limit) GetRejected() uint64 {
+	if m != nil {
+		return m.Rejected
+	}
+	return 0
+}
+
+type Timeout struct {
+	// max execution time in nanoseconds
+	Timeout uint64 `protobuf:"varint,1,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// requests which ran over
+	Exceeded uint64 `protobuf:"varint,2,opt,name=exceeded,proto3" json:"exceeded,omitempty"`
+	// longest request in nanoseconds
+	Longest              uint64   `protobuf:"varint,3,opt,name=longest,proto3" json:"longest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Timeout) Reset()         { *m = Timeout{} }
+func (m *Timeout) String() string { return proto.CompactTextString(m) }
+func (*Timeout) ProtoMessage()    {}
+func (*Timeout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{5}
+}
+
+func (m *Timeout) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Timeout.Unmarshal(m, b)
+}
+func (m *Timeout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Timeout.Marshal(b, m, deterministic)
+}
+func (m *Timeout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Timeout.Merge(m, src)
+}
+func (m *Timeout) XXX_Size() int {
+	return xxx_messageInfo_Timeout.Size(m)
+}
+func (m *Timeout) XXX_DiscardUnknown() {
+	xxx_messageInfo_Timeout.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Timeout proto.InternalMessageInfo
+
+func (m *Timeout) GetTimeout() uint64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *Timeout) GetExceeded() uint64 {
+	if m != nil {
+		return m.Exceeded
+	}
+	return 0
+}
+
+func (m *Timeout) GetLongest() uint64 {
+	if m != nil {
+		return m.Longest
+	}
+	return 0
+}
+
+// Topology counts the nodes picked by zone aware routing
+type Topology struct {
+	// nodes picked in the same zone
+	Local uint64 `protobuf:"varint,1,opt,name=local,proto3" json:"local,omitempty"`
+	// nodes picked in another zone of the same region
+	CrossZone uint64 `protobuf:"varint,2,opt,name=cross_zone,json=crossZone,proto3" json:"cross_zone,omitempty"`
+	// nodes picked in another region
+	CrossRegion          uint64   `protobuf:"varint,3,opt,name=cross_region,json=crossRegion,proto3" json:"cross_region,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Topology) Reset()         { *m = Topology{} }
+func (m *Topology) String() string { return proto.CompactTextString(m) }
+func (*Topology) ProtoMessage()    {}
+func (*Topology) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{6}
+}
+
+func (m *Topology) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Topology.Unmarshal(m, b)
+}
+func (m *Topology) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Topology.Marshal(b, m, deterministic)
+}
+func (m *Topology) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Topology.Merge(m, src)
+}
+func (m *Topology) XXX_Size() int {
+	return xxx_messageInfo_Topology.Size(m)
+}
+func (m *Topology) XXX_DiscardUnknown() {
+	xxx_messageInfo_Topology.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Topology proto.InternalMessageInfo
+
+func (m *Topology) GetLocal() uint64 {
+	if m != nil {
+		return m.Local
+	}
+	return 0
+}
+
+func (m *Topology) GetCrossZone() uint64 {
+	if m != nil {
+		return m.CrossZone
+	}
+	return 0
+}
+
+func (m *Topology) GetCrossRegion() uint64 {
+	if m != nil {
+		return m.CrossRegion
+	}
+	return 0
+}
+
 // LogRequest requests service logs
 type LogRequest struct {
 	// service to request logs for
@@ -293,7 +496,7 @@ func (m *LogRequest) Reset()         { *m = LogRequest{} }
 func (m *LogRequest) String() string { return proto.CompactTextString(m) }
 func (*LogRequest) ProtoMessage()    {}
 func (*LogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{4}
+	return fileDescriptor_df91f41a5db378e6, []int{7}
 }
 
 func (m *LogRequest) XXX_Unmarshal(b []byte) error {
@@ -359,7 +562,7 @@ func (m *Record) Reset()         { *m = Record{} }
 func (m *Record) String() string { return proto.CompactTextString(m) }
 func (*Record) ProtoMessage()    {}
 func (*Record) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{5}
+	return fileDescriptor_df91f41a5db378e6, []int{8}
 }
 
 func (m *Record) XXX_Unmarshal(b []byte) error {
@@ -413,7 +616,7 @@ func (m *TraceRequest) Reset()         { *m = TraceRequest{} }
 func (m *TraceRequest) String() string { return proto.CompactTextString(m) }
 func (*TraceRequest) ProtoMessage()    {}
 func (*TraceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{6}
+	return fileDescriptor_df91f41a5db378e6, []int{9}
 }
 
 func (m *TraceRequest) XXX_Unmarshal(b []byte) error {
@@ -452,7 +655,7 @@ func (m *TraceResponse) Reset()         { *m = TraceResponse{} }
 func (m *TraceResponse) String() string { return proto.CompactTextString(m) }
 func (*TraceResponse) ProtoMessage()    {}
 func (*TraceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{7}
+	return fileDescriptor_df91f41a5db378e6, []int{10}
 }
 
 func (m *TraceResponse) XXX_Unmarshal(b []byte) error {
@@ -505,7 +708,7 @@ func (m *Span) Reset()         { *m = Span{} }
 func (m *Span) String() string { return proto.CompactTextString(m) }
 func (*Span) ProtoMessage()    {}
 func (*Span) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{8}
+	return fileDescriptor_df91f41a5db378e6, []int{11}
 }
 
 func (m *Span) XXX_Unmarshal(b []byte) error {
@@ -592,7 +795,7 @@ func (m *CacheRequest) Reset()         { *m = CacheRequest{} }
 func (m *CacheRequest) String() string { return proto.CompactTextString(m) }
 func (*CacheRequest) ProtoMessage()    {}
 func (*CacheRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{9}
+	return fileDescriptor_df91f41a5db378e6, []int{12}
 }
 
 func (m *CacheRequest) XXX_Unmarshal(b []byte) error {
@@ -624,7 +827,7 @@ func (m *CacheResponse) Reset()         { *m = CacheResponse{} }
 func (m *CacheResponse) String() string { return proto.CompactTextString(m) }
 func (*CacheResponse) ProtoMessage()    {}
 func (*CacheResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_df91f41a5db378e6, []int{10}
+	return fileDescriptor_df91f41a5db378e6, []int{13}
 }
 
 func (m *CacheResponse) XXX_Unmarshal(b []byte) error {
@@ -652,12 +855,182 @@ func (m *CacheResponse) GetValues() map[string]string {
 	return nil
 }
 
+type OutliersRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OutliersRequest) Reset()         { *m = OutliersRequest{} }
+func (m *OutliersRequest) String() string { return proto.CompactTextString(m) }
+func (*OutliersRequest) ProtoMessage()    {}
+func (*OutliersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{14}
+}
+
+func (m *OutliersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OutliersRequest.Unmarshal(m, b)
+}
+func (m *OutliersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OutliersRequest.Marshal(b, m, deterministic)
+}
+func (m *OutliersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutliersRequest.Merge(m, src)
+}
+func (m *OutliersRequest) XXX_Size() int {
+	return xxx_messageInfo_OutliersRequest.Size(m)
+}
+func (m *OutliersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutliersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OutliersRequest proto.InternalMessageInfo
+
+type OutliersResponse struct {
+	Ejections            []*Ejection `protobuf:"bytes,1,rep,name=ejections,proto3" json:"ejections,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *OutliersResponse) Reset()         { *m = OutliersResponse{} }
+func (m *OutliersResponse) String() string { return proto.CompactTextString(m) }
+func (*OutliersResponse) ProtoMessage()    {}
+func (*OutliersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{15}
+}
+
+func (m *OutliersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OutliersResponse.Unmarshal(m, b)
+}
+func (m *OutliersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OutliersResponse.Marshal(b, m, deterministic)
+}
+func (m *OutliersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutliersResponse.Merge(m, src)
+}
+func (m *OutliersResponse) XXX_Size() int {
+	return xxx_messageInfo_OutliersResponse.Size(m)
+}
+func (m *OutliersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutliersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OutliersResponse proto.InternalMessageInfo
+
+func (m *OutliersResponse) GetEjections() []*Ejection {
+	if m != nil {
+		return m.Ejections
+	}
+	return nil
+}
+
+// Ejection is a node ejected from selection by the client
+type Ejection struct {
+	// name of the service
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// id of the node
+	Node string `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	// address of the node
+	Address string `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	// why the node was ejected
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// number of times in a row the node was ejected
+	Ejections int64 `protobuf:"varint,5,opt,name=ejections,proto3" json:"ejections,omitempty"`
+	// time of ejection in nanoseconds
+	Started uint64 `protobuf:"varint,6,opt,name=started,proto3" json:"started,omitempty"`
+	// time the node returns in nanoseconds
+	Until                uint64   `protobuf:"varint,7,opt,name=until,proto3" json:"until,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ejection) Reset()         { *m = Ejection{} }
+func (m *Ejection) String() string { return proto.CompactTextString(m) }
+func (*Ejection) ProtoMessage()    {}
+func (*Ejection) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{16}
+}
+
+func (m *Ejection) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ejection.Unmarshal(m, b)
+}
+func (m *Ejection) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ejection.Marshal(b, m, deterministic)
+}
+func (m *Ejection) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ejection.Merge(m, src)
+}
+func (m *Ejection) XXX_Size() int {
+	return xxx_messageInfo_Ejection.Size(m)
+}
+func (m *Ejection) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ejection.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ejection proto.InternalMessageInfo
+
+func (m *Ejection) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *Ejection) GetNode() string {
+	if m != nil {
+		return m.Node
+	}
+	return ""
+}
+
+func (m *Ejection) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Ejection) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Ejection) GetEjections() int64 {
+	if m != nil {
+		return m.Ejections
+	}
+	return 0
+}
+
+func (m *Ejection) GetStarted() uint64 {
+	if m != nil {
+		return m.Started
+	}
+	return 0
+}
+
+func (m *Ejection) GetUntil() uint64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("SpanType", SpanType_name, SpanType_value)
 	proto.RegisterType((*HealthRequest)(nil), "HealthRequest")
 	proto.RegisterType((*HealthResponse)(nil), "HealthResponse")
 	proto.RegisterType((*StatsRequest)(nil), "StatsRequest")
 	proto.RegisterType((*StatsResponse)(nil), "StatsResponse")
+	proto.RegisterMapType((map[string]*Limit)(nil), "StatsResponse.LimitsEntry")
+	proto.RegisterMapType((map[string]*Timeout)(nil), "StatsResponse.TimeoutsEntry")
+	proto.RegisterType((*Limit)(nil), "Limit")
+	proto.RegisterType((*Timeout)(nil), "Timeout")
+	proto.RegisterType((*Topology)(nil), "Topology")
 	proto.RegisterType((*LogRequest)(nil), "LogRequest")
 	proto.RegisterType((*Record)(nil), "Record")
 	proto.RegisterMapType((map[string]string)(nil), "Record.MetadataEntry")
@@ -668,53 +1041,76 @@ func init() {
 	proto.RegisterType((*CacheRequest)(nil), "CacheRequest")
 	proto.RegisterType((*CacheResponse)(nil), "CacheResponse")
 	proto.RegisterMapType((map[string]string)(nil), "CacheResponse.ValuesEntry")
+	proto.RegisterType((*OutliersRequest)(nil), "OutliersRequest")
+	proto.RegisterType((*OutliersResponse)(nil), "OutliersResponse")
+	proto.RegisterType((*Ejection)(nil), "Ejection")
 }
 
 func init() { proto.RegisterFile("debug/service/proto/debug.proto", fileDescriptor_df91f41a5db378e6) }
 
 var fileDescriptor_df91f41a5db378e6 = []byte{
-	// 646 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0xdb, 0x6e, 0xd3, 0x4a,
-	0x14, 0x8d, 0xed, 0x38, 0xb1, 0x77, 0x62, 0x9f, 0x6a, 0xce, 0x45, 0x96, 0x0f, 0xd0, 0xca, 0x12,
-	0x52, 0xb8, 0x68, 0x02, 0xe1, 0x85, 0xcb, 0x1b, 0x14, 0x09, 0xa4, 0xd2, 0x4a, 0xd3, 0x96, 0xf7,
-	0xa9, 0x3d, 0x4a, 0x03, 0xf5, 0x85, 0x99, 0x71, 0xa5, 0xbc, 0xf0, 0x23, 0xfc, 0x04, 0xff, 0x82,
-	0xf8, 0x1f, 0x34, 0x17, 0xb7, 0xb6, 0x10, 0xaa, 0x10, 0x6f, 0x5e, 0x6b, 0xaf, 0xd9, 0xd9, 0x7b,
-	0x69, 0x65, 0xc3, 0x6e, 0xc1, 0xce, 0xda, 0xf5, 0x52, 0x30, 0x7e, 0xb9, 0xc9, 0xd9, 0xb2, 0xe1,
-	0xb5, 0xac, 0x97, 0x9a, 0xc3, 0xfa, 0x3b, 0xbb, 0x07, 0xd1, 0x1b, 0x46, 0x2f, 0xe4, 0x39, 0x61,
-	0x9f, 0x5a, 0x26, 0x24, 0x4a, 0x60, 0x6a, 0xd5, 0x89, 0xb3, 0xe7, 0x2c, 0x42, 0xd2, 0xc1, 0x6c,
-	0x01, 0x71, 0x27, 0x15, 0x4d, 0x5d, 0x09, 0x86, 0xfe, 0x83, 0x89, 0x90, 0x54, 0xb6, 0xc2, 0x4a,
-	0x2d, 0xca, 0x16, 0x30, 0x3f, 0x96, 0x54, 0x8a, 0x9b, 0x7b, 0x7e, 0x77, 0x20, 0xb2, 0x52, 0xdb,
-	0xf3, 0x16, 0x84, 0x72, 0x53, 0x32, 0x21, 0x69, 0xd9, 0x68, 0xf5, 0x98, 0x5c, 0x13, 0xba, 0x93,
-	0xa4, 0x5c, 0xb2, 0x22, 0x71, 0x75, 0xad, 0x83, 0x6a, 0x96, 0xb6, 0x51, 0xc2, 0xc4, 0xd3, 0x05,
-	0x8b, 0x14, 0x5f, 0xb2, 0xb2, 0xe6, 0xdb, 0x64, 0x6c, 0x78, 0x83, 0x54, 0x27, 0x79, 0xce, 0x19,
-	0x2d, 0x44, 0xe2, 0x9b, 0x4e, 0x16, 0xa2, 0x18, 0xdc, 0x75, 0x9e, 0x4c, 0x34, 0xe9, 0xae, 0x73,
-	0x94, 0x42, 0xc0, 0xcd, 0x22, 0x22, 0x99, 0x6a, 0xf6, 0x0a, 0xab, 0xee, 0x8c, 0xf3, 0x9a, 0x8b,
-	0x24, 0x30, 0xdd, 0x0d, 0xca, 0x3e, 0x00, 0x1c, 0xd4, 0xeb, 0x1b, 0xf7, 0x37, 0x0e, 0x72, 0x46,
-	0x4b, 0xbd, 0x4e, 0x40, 0x2c, 0x42, 0xff, 0x80, 0x9f, 0xd7, 0x6d, 0x25, 0xf5, 0x32, 0x1e, 0x31,
-	0x40, 0xb1, 0x62, 0x53, 0xe5, 0x4c, 0xaf, 0xe2, 0x11, 0x03, 0xb2, 0xaf, 0x0e, 0x4c, 0x08, 0xcb,
-	0x6b, 0x5e, 0xfc, 0x6c, 0x9e, 0xd7, 0x37, 0xef, 0x31, 0x04, 0x25, 0x93, 0xb4, 0xa0, 0x92, 0x26,
-	0xee, 0x9e, 0xb7, 0x98, 0xad, 0xfe, 0xc5, 0xe6, 0x21, 0x7e, 0x67, 0xf9, 0xd7, 0x95, 0xe4, 0x5b,
-	0x72, 0x25, 0x53, 0x93, 0x97, 0x4c, 0x08, 0xba, 0x36, 0xb6, 0x86, 0xa4, 0x83, 0xe9, 0x0b, 0x88,
-	0x06, 0x8f, 0xd0, 0x0e, 0x78, 0x1f, 0xd9, 0xd6, 0x2e, 0xa8, 0x3e, 0xd5, 0xb8, 0x97, 0xf4, 0xa2,
-	0x65, 0x7a, 0xb7, 0x90, 0x18, 0xf0, 0xdc, 0x7d, 0xea, 0x64, 0x77, 0x60, 0x7e, 0xc2, 0x69, 0xce,
-	0x3a, 0x83, 0x62, 0x70, 0x37, 0x85, 0x7d, 0xea, 0x6e, 0x8a, 0xec, 0x21, 0x44, 0xb6, 0x6e, 0x53,
-	0xf1, 0x3f, 0xf8, 0xa2, 0xa1, 0x95, 0x0a, 0x9a, 0x9a, 0xdb, 0xc7, 0xc7, 0x0d, 0xad, 0x88, 0xe1,
-	0xb2, 0x2f, 0x2e, 0x8c, 0x15, 0x56, 0x3f, 0x28, 0xd5, 0x33, 0xdb, 0xc9, 0x00, 0xdb, 0xdc, 0xed,
-	0x9a, 0x2b, 0xcf, 0x1b, 0xca, 0x99, 0x35, 0x37, 0x24, 0x16, 0x21, 0x04, 0xe3, 0x8a, 0x96, 0xc6,
-	0xdc, 0x90, 0xe8, 0xef, 0x7e, 0xde, 0xfc, 0x61, 0xde, 0x52, 0x08, 0x8a, 0x96, 0x53, 0xb9, 0xa9,
-	0x2b, 0x9b, 0x95, 0x2b, 0x8c, 0x96, 0x3d, 0xa3, 0xa7, 0x7a, 0xe0, 0xbf, 0xf5, 0xc0, 0xbf, 0xb4,
-	0xf9, 0x36, 0x8c, 0xe5, 0xb6, 0x61, 0x3a, 0x44, 0xf1, 0x2a, 0xd4, 0xe2, 0x93, 0x6d, 0xc3, 0x88,
-	0xa6, 0xff, 0xcc, 0xeb, 0x18, 0xe6, 0xaf, 0x68, 0x7e, 0xde, 0x79, 0x9d, 0x7d, 0x86, 0xc8, 0x62,
-	0xeb, 0xed, 0x0a, 0x26, 0x5a, 0xdd, 0x99, 0x9b, 0xe2, 0x41, 0x1d, 0xbf, 0xd7, 0x45, 0x33, 0xb2,
-	0x55, 0xa6, 0xcf, 0x60, 0xd6, 0xa3, 0x7f, 0x67, 0x9e, 0xfb, 0x77, 0x21, 0xe8, 0xd6, 0x43, 0x33,
-	0x98, 0xbe, 0x3d, 0x7c, 0x79, 0x74, 0x7a, 0xb8, 0xbf, 0x33, 0x42, 0x73, 0x08, 0x8e, 0x4e, 0x4f,
-	0x0c, 0x72, 0x56, 0xdf, 0x1c, 0xf0, 0xf7, 0xd5, 0xa1, 0x42, 0xbb, 0xe0, 0x1d, 0xd4, 0x6b, 0x34,
-	0xc3, 0xd7, 0xff, 0xa8, 0x74, 0x6a, 0x83, 0x9b, 0x8d, 0x1e, 0x39, 0xe8, 0x01, 0x4c, 0xcc, 0x61,
-	0x42, 0x31, 0x1e, 0x1c, 0xb3, 0xf4, 0x2f, 0x3c, 0xbc, 0x58, 0xd9, 0x08, 0x2d, 0xc0, 0xd7, 0x07,
-	0x07, 0x45, 0xb8, 0x7f, 0xa3, 0xd2, 0x18, 0x0f, 0xee, 0x90, 0x51, 0xea, 0x10, 0xa2, 0x08, 0xf7,
-	0xc3, 0x9a, 0xc6, 0x78, 0x90, 0x4d, 0xa3, 0xd4, 0x96, 0xa1, 0x08, 0xf7, 0xad, 0x4e, 0xe3, 0xa1,
-	0x93, 0xd9, 0xe8, 0x6c, 0xa2, 0xaf, 0xee, 0x93, 0x1f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x22, 0x65,
-	0x99, 0x10, 0x98, 0x05, 0x00, 0x00,
+	// 975 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xcd, 0x8e, 0xe3, 0x44,
+	0x10, 0x4e, 0x9c, 0x38, 0xb1, 0x2b, 0x3f, 0x3b, 0xdb, 0x0c, 0xc8, 0x32, 0xb3, 0xbb, 0x83, 0xa5,
+	0x15, 0xe1, 0x47, 0x3d, 0x10, 0x2e, 0x0b, 0x7b, 0x02, 0x76, 0x24, 0x90, 0x86, 0x1d, 0xc9, 0x9b,
+	0x41, 0x82, 0x0b, 0xea, 0xb1, 0x1b, 0x8f, 0x97, 0xc4, 0x6d, 0xba, 0xdb, 0x2b, 0xc2, 0x81, 0x23,
+	0x2f, 0xc1, 0x4b, 0x70, 0xe4, 0xc1, 0x78, 0x00, 0xd4, 0x7f, 0x8e, 0x3d, 0x2c, 0xac, 0x10, 0xb7,
+	0xfe, 0xbe, 0xaa, 0x2e, 0x57, 0xaa, 0xbe, 0xaa, 0x0e, 0x3c, 0xc8, 0xe9, 0x75, 0x53, 0x9c, 0x09,
+	0xca, 0x5f, 0x94, 0x19, 0x3d, 0xab, 0x39, 0x93, 0xec, 0x4c, 0x73, 0x58, 0x9f, 0x93, 0x77, 0x60,
+	0xf1, 0x05, 0x25, 0x5b, 0x79, 0x93, 0xd2, 0x1f, 0x1b, 0x2a, 0x24, 0x8a, 0x60, 0x6a, 0xbd, 0xa3,
+	0xe1, 0xe9, 0x70, 0x15, 0xa6, 0x0e, 0x26, 0x2b, 0x58, 0x3a, 0x57, 0x51, 0xb3, 0x4a, 0x50, 0xf4,
+	0x06, 0x4c, 0x84, 0x24, 0xb2, 0x11, 0xd6, 0xd5, 0xa2, 0x64, 0x05, 0xf3, 0x67, 0x92, 0x48, 0xf1,
+	0xea, 0x98, 0x7f, 0x8e, 0x60, 0x61, 0x5d, 0x6d, 0xcc, 0x13, 0x08, 0x65, 0xb9, 0xa3, 0x42, 0x92,
+	0x5d, 0xad, 0xbd, 0xc7, 0xe9, 0x81, 0xd0, 0x91, 0x24, 0xe1, 0x92, 0xe6, 0x91, 0xa7, 0x6d, 0x0e,
+	0xaa, 0x5c, 0x9a, 0x5a, 0x39, 0x46, 0x23, 0x6d, 0xb0, 0x48, 0xf1, 0x3b, 0xba, 0x63, 0x7c, 0x1f,
+	0x8d, 0x0d, 0x6f, 0x90, 0x8a, 0x24, 0x6f, 0x38, 0x25, 0xb9, 0x88, 0x7c, 0x13, 0xc9, 0x42, 0xb4,
+	0x04, 0xaf, 0xc8, 0xa2, 0x89, 0x26, 0xbd, 0x22, 0x43, 0x31, 0x04, 0xdc, 0xfc, 0x10, 0x11, 0x4d,
+	0x35, 0xdb, 0x62, 0x15, 0x9d, 0x72, 0xce, 0xb8, 0x88, 0x02, 0x13, 0xdd, 0x20, 0xb4, 0x86, 0xc9,
+	0xb6, 0xdc, 0x95, 0x52, 0x44, 0xe1, 0xe9, 0x68, 0x35, 0x5b, 0xc7, 0xb8, 0xf7, 0x2b, 0xf1, 0x85,
+	0x36, 0x9e, 0x57, 0x92, 0xef, 0x53, 0xeb, 0x89, 0x1e, 0x41, 0xa0, 0x32, 0x66, 0x8d, 0x14, 0x11,
+	0xe8, 0x5b, 0x27, 0xb7, 0x6e, 0x6d, 0xac, 0xd9, 0xdc, 0x6b, 0xbd, 0xd1, 0x43, 0x08, 0x24, 0xab,
+	0xd9, 0x96, 0x15, 0xfb, 0x68, 0x76, 0x3a, 0x5c, 0xcd, 0xd6, 0x21, 0xde, 0x58, 0x22, 0x6d, 0x4d,
+	0xf1, 0xa7, 0x30, 0xeb, 0x7c, 0x17, 0x1d, 0xc1, 0xe8, 0x07, 0xba, 0xb7, 0x1d, 0x51, 0x47, 0x74,
+	0x02, 0xfe, 0x0b, 0xb2, 0x6d, 0xa8, 0xae, 0xed, 0x6c, 0x3d, 0x31, 0x69, 0xa6, 0x86, 0xfc, 0xc4,
+	0x7b, 0x34, 0x8c, 0xcf, 0x61, 0xd1, 0x4b, 0xe2, 0x25, 0x41, 0xee, 0xf7, 0x83, 0x04, 0x2e, 0xeb,
+	0x4e, 0x98, 0xe4, 0x0a, 0x7c, 0x1d, 0x1a, 0x1d, 0x83, 0xaf, 0x7f, 0xbd, 0xed, 0xb4, 0x01, 0xaa,
+	0xe2, 0x65, 0xf5, 0xfd, 0xb6, 0x2c, 0x6e, 0xa4, 0x6d, 0x73, 0x8b, 0x4d, 0x37, 0x9e, 0xd3, 0x4c,
+	0x49, 0x60, 0xe4, 0xba, 0x61, 0x70, 0xf2, 0x0d, 0x4c, 0xed, 0xc7, 0x74, 0x7b, 0xcd, 0xd1, 0x86,
+	0x76, 0x50, 0x05, 0xa0, 0x3f, 0x65, 0x94, 0xe6, 0xad, 0x86, 0x5a, 0xac, 0x6e, 0x6d, 0x59, 0x55,
+	0x50, 0x21, 0x6d, 0x6c, 0x07, 0x93, 0x6b, 0x08, 0x5c, 0x45, 0x75, 0xd2, 0x2c, 0x23, 0xdb, 0x36,
+	0x69, 0x05, 0xd0, 0x3d, 0x80, 0x8c, 0x33, 0x21, 0xbe, 0xfb, 0x99, 0x55, 0xd4, 0x46, 0x0e, 0x35,
+	0xf3, 0x2d, 0xab, 0x28, 0x7a, 0x0b, 0xe6, 0xc6, 0xcc, 0x69, 0x51, 0xb2, 0xca, 0xc6, 0x9f, 0x69,
+	0x2e, 0xd5, 0x54, 0xf2, 0x1c, 0xe0, 0x82, 0x15, 0xaf, 0x1c, 0x1a, 0x33, 0x76, 0x9c, 0x92, 0x9d,
+	0xfe, 0x4a, 0x90, 0x5a, 0xa4, 0xf2, 0xca, 0x58, 0x53, 0x99, 0xdc, 0x47, 0xa9, 0x01, 0x8a, 0x15,
+	0x65, 0x95, 0x51, 0xad, 0xff, 0x51, 0x6a, 0x40, 0xf2, 0xfb, 0x10, 0x26, 0x29, 0xcd, 0x18, 0xcf,
+	0xff, 0x3e, 0x71, 0xa3, 0xee, 0xc4, 0x7d, 0x08, 0xc1, 0x8e, 0x4a, 0x92, 0x13, 0x49, 0x22, 0x4f,
+	0xab, 0xf2, 0x75, 0x6c, 0x2e, 0xe2, 0xaf, 0x2c, 0x6f, 0xe5, 0xe8, 0xdc, 0x54, 0xe6, 0x3b, 0x2a,
+	0x04, 0x29, 0xcc, 0x2c, 0x86, 0xa9, 0x83, 0xf1, 0x63, 0x58, 0xf4, 0x2e, 0xbd, 0x44, 0x3e, 0xc7,
+	0x5d, 0xf9, 0x84, 0x5d, 0xd1, 0xdc, 0x87, 0xf9, 0x86, 0x93, 0x8c, 0xba, 0x02, 0x2d, 0xc1, 0x2b,
+	0x73, 0x7b, 0xd5, 0x2b, 0xf3, 0xe4, 0x7d, 0x58, 0x58, 0xbb, 0x5d, 0x25, 0x6f, 0x82, 0x2f, 0x6a,
+	0x52, 0xa9, 0xed, 0xa4, 0xf2, 0xf6, 0xf1, 0xb3, 0x9a, 0x54, 0xa9, 0xe1, 0x92, 0xdf, 0x3c, 0x18,
+	0x2b, 0xac, 0x3e, 0x28, 0xd5, 0x35, 0x1b, 0xc9, 0x00, 0x1b, 0xdc, 0x73, 0xc1, 0x55, 0xcd, 0x6b,
+	0xc2, 0xa9, 0x2d, 0x6e, 0x98, 0x5a, 0x84, 0x10, 0x8c, 0x2b, 0xb2, 0x33, 0xc5, 0x0d, 0x53, 0x7d,
+	0xee, 0x2e, 0x29, 0xbf, 0xbf, 0xa4, 0x62, 0x08, 0xf2, 0x86, 0x13, 0xa9, 0x04, 0x60, 0x16, 0x4c,
+	0x8b, 0xd1, 0x59, 0xa7, 0xd0, 0x53, 0x9d, 0xf0, 0x6b, 0x3a, 0xe1, 0x7f, 0x2c, 0xf3, 0x3d, 0x18,
+	0xcb, 0x7d, 0x4d, 0xf5, 0xe6, 0x59, 0xae, 0x43, 0xed, 0xbc, 0xd9, 0xd7, 0x34, 0xd5, 0xf4, 0xff,
+	0xab, 0xf5, 0x12, 0xe6, 0x9f, 0x93, 0xec, 0xc6, 0xd5, 0x3a, 0xf9, 0x05, 0x16, 0x16, 0xdb, 0xda,
+	0xae, 0x61, 0xa2, 0xbd, 0x5d, 0x71, 0x63, 0xdc, 0xb3, 0xe3, 0xaf, 0xb5, 0xd1, 0x2e, 0x38, 0xe3,
+	0x19, 0x7f, 0x0c, 0xb3, 0x0e, 0xfd, 0x9f, 0xf2, 0xb9, 0x0b, 0x77, 0x2e, 0x1b, 0xb9, 0x2d, 0x29,
+	0x77, 0x8f, 0x4a, 0xf2, 0x18, 0x8e, 0x0e, 0x94, 0xcd, 0xea, 0x6d, 0x08, 0xf5, 0x2e, 0x28, 0x59,
+	0xdb, 0xf5, 0x10, 0x9f, 0x5b, 0x26, 0x3d, 0xd8, 0x92, 0x3f, 0x86, 0x10, 0x38, 0xfe, 0x5f, 0x26,
+	0x4d, 0x75, 0x97, 0xe5, 0x2e, 0x1f, 0x7d, 0x56, 0xde, 0x24, 0xcf, 0x39, 0x15, 0xc2, 0xa9, 0xdb,
+	0x42, 0xa5, 0x11, 0x4e, 0x89, 0x60, 0x95, 0x55, 0x83, 0x45, 0x6a, 0xc0, 0x0e, 0x59, 0xf9, 0x66,
+	0xc0, 0x5a, 0xa2, 0xab, 0x96, 0x49, 0x5f, 0x2d, 0xc7, 0xe0, 0x37, 0x95, 0x2c, 0xb7, 0xf6, 0xd5,
+	0x31, 0xe0, 0xdd, 0x87, 0x10, 0xb8, 0x4e, 0xa3, 0x19, 0x4c, 0xbf, 0x7c, 0xfa, 0xd9, 0xe5, 0xd5,
+	0xd3, 0x27, 0x47, 0x03, 0x34, 0x87, 0xe0, 0xf2, 0x6a, 0x63, 0xd0, 0x70, 0xfd, 0xab, 0x07, 0xfe,
+	0x13, 0xf5, 0xd0, 0xa3, 0x07, 0x30, 0xba, 0x60, 0x05, 0x9a, 0xe1, 0xc3, 0x72, 0x89, 0xa7, 0x76,
+	0x86, 0x93, 0xc1, 0x07, 0x43, 0xf4, 0x1e, 0x4c, 0xcc, 0xc3, 0x8e, 0x96, 0xb8, 0xf7, 0x67, 0x20,
+	0xbe, 0x83, 0xfb, 0x2f, 0x7e, 0x32, 0x40, 0x2b, 0xf0, 0xf5, 0xa3, 0x84, 0x16, 0xb8, 0xfb, 0xc6,
+	0xc7, 0xcb, 0xfe, 0x5b, 0x65, 0x3c, 0xf5, 0x3c, 0xa2, 0x05, 0xee, 0xce, 0x6d, 0xbc, 0xc4, 0xbd,
+	0x31, 0x35, 0x9e, 0x5a, 0x3d, 0x68, 0x81, 0xbb, 0xaa, 0x8b, 0x97, 0x7d, 0x51, 0x25, 0x03, 0xb5,
+	0x8d, 0x5c, 0xd3, 0xd1, 0x11, 0xbe, 0x25, 0x89, 0xf8, 0x2e, 0xbe, 0xad, 0x88, 0x64, 0x70, 0x3d,
+	0xd1, 0x7f, 0x74, 0x3e, 0xfa, 0x6b, 0x00, 0xbe, 0xe7, 0xbd, 0x9b, 0x0b, 0x09, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -734,6 +1130,7 @@ type DebugClient interface {
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
 	Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (*TraceResponse, error)
 	Cache(ctx context.Context, in *CacheRequest, opts ...grpc.CallOption) (*CacheResponse, error)
+	Outliers(ctx context.Context, in *OutliersRequest, opts ...grpc.CallOption) (*OutliersResponse, error)
 }
 
 type debugClient struct {
@@ -812,6 +1209,15 @@ func (c *debugClient) Cache(ctx context.Context, in *CacheRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *debugClient) Outliers(ctx context.Context, in *OutliersRequest, opts ...grpc.CallOption) (*OutliersResponse, error) {
+	out := new(OutliersResponse)
+	err := c.cc.Invoke(ctx, "/Debug/Outliers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DebugServer is the server API for Debug service.
 type DebugServer interface {
 	Log(*LogRequest, Debug_LogServer) error
@@ -819,6 +1225,7 @@ type DebugServer interface {
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
 	Trace(context.Context, *TraceRequest) (*TraceResponse, error)
 	Cache(context.Context, *CacheRequest) (*CacheResponse, error)
+	Outliers(context.Context, *OutliersRequest) (*OutliersResponse, error)
 }
 
 // UnimplementedDebugServer can be embedded to have forward compatible implementations.
@@ -840,6 +1247,9 @@ func (*UnimplementedDebugServer) Trace(ctx context.Context, req *TraceRequest) (
 func (*UnimplementedDebugServer) Cache(ctx context.Context, req *CacheRequest) (*CacheResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Cache not implemented")
 }
+func (*UnimplementedDebugServer) Outliers(ctx context.Context, req *OutliersRequest) (*OutliersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Outliers not implemented")
+}
 
 func RegisterDebugServer(s *grpc.Server, srv DebugServer) {
 	s.RegisterService(&_Debug_serviceDesc, srv)
@@ -938,6 +1348,24 @@ func _Debug_Cache_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Debug_Outliers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutliersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Outliers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Debug/Outliers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Outliers(ctx, req.(*OutliersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Debug_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "Debug",
 	HandlerType: (*DebugServer)(nil),
@@ -958,6 +1386,10 @@ var _Debug_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Cache",
 			Handler:    _Debug_Cache_Handler,
 		},
+		{
+			MethodName: "Outliers",
+			Handler:    _Debug_Outliers_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
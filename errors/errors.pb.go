@@ -21,10 +21,14 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type Error struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Code                 int32    `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
-	Detail               string   `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
-	Status               string   `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Id     string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Code   int32  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Detail string `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// the server asked for the request not to be retried
+	DoNotRetry bool `protobuf:"varint,5,opt,name=do_not_retry,json=doNotRetry,proto3" json:"do_not_retry,omitempty"`
+	// milliseconds the server asked to wait before a retry
+	RetryAfter           int64    `protobuf:"varint,6,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -83,6 +87,20 @@ func (m *Error) GetStatus() string {
 	return ""
 }
 
+func (m *Error) GetDoNotRetry() bool {
+	if m != nil {
+		return m.DoNotRetry
+	}
+	return false
+}
+
+func (m *Error) GetRetryAfter() int64 {
+	if m != nil {
+		return m.RetryAfter
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Error)(nil), "errors.Error")
 }
@@ -90,13 +108,16 @@ func init() {
 func init() { proto.RegisterFile("errors/errors.proto", fileDescriptor_85c4eef3398a32b2) }
 
 var fileDescriptor_85c4eef3398a32b2 = []byte{
-	// 118 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4e, 0x2d, 0x2a, 0xca,
-	0x2f, 0x2a, 0xd6, 0x87, 0x50, 0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x6c, 0x10, 0x9e, 0x52,
-	0x34, 0x17, 0xab, 0x2b, 0x88, 0x25, 0xc4, 0xc7, 0xc5, 0x94, 0x99, 0x22, 0xc1, 0xa8, 0xc0, 0xa8,
-	0xc1, 0x19, 0xc4, 0x94, 0x99, 0x22, 0x24, 0xc4, 0xc5, 0x92, 0x9c, 0x9f, 0x92, 0x2a, 0xc1, 0xa4,
-	0xc0, 0xa8, 0xc1, 0x1a, 0x04, 0x66, 0x0b, 0x89, 0x71, 0xb1, 0xa5, 0xa4, 0x96, 0x24, 0x66, 0xe6,
-	0x48, 0x30, 0x83, 0xd5, 0x41, 0x79, 0x20, 0xf1, 0xe2, 0x92, 0xc4, 0x92, 0xd2, 0x62, 0x09, 0x16,
-	0x88, 0x38, 0x84, 0x97, 0xc4, 0x06, 0xb6, 0xcb, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0xca, 0xc3,
-	0xcb, 0x29, 0x82, 0x00, 0x00, 0x00,
+	// 168 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x2c, 0xce, 0x3f, 0x0a, 0x42, 0x31,
+	0x0c, 0x06, 0x70, 0xfa, 0xfe, 0x14, 0x8d, 0xe2, 0x10, 0x41, 0xba, 0x59, 0x9c, 0x3a, 0xe9, 0xe0,
+	0x09, 0x1c, 0x5c, 0x1d, 0x7a, 0x81, 0xc7, 0xd3, 0x56, 0x78, 0x20, 0x46, 0xd2, 0x38, 0x78, 0x19,
+	0xcf, 0x2a, 0x6d, 0x9d, 0xf2, 0x7d, 0xbf, 0x64, 0x08, 0xac, 0x23, 0x33, 0x71, 0x3a, 0xd4, 0xb1,
+	0x7f, 0x31, 0x09, 0xa1, 0xae, 0x6d, 0xf7, 0x55, 0xd0, 0x9f, 0x73, 0xc4, 0x15, 0x34, 0x53, 0x30,
+	0xca, 0x2a, 0x37, 0xf7, 0xcd, 0x14, 0x10, 0xa1, 0xbb, 0x51, 0x88, 0xa6, 0xb1, 0xca, 0xf5, 0xbe,
+	0x64, 0xdc, 0x80, 0x0e, 0x51, 0xc6, 0xe9, 0x61, 0xda, 0x72, 0xf7, 0x6f, 0xd9, 0x93, 0x8c, 0xf2,
+	0x4e, 0xa6, 0xab, 0x5e, 0x1b, 0x5a, 0x58, 0x06, 0x1a, 0x9e, 0x24, 0x03, 0x47, 0xe1, 0x8f, 0xe9,
+	0xad, 0x72, 0x33, 0x0f, 0x81, 0x2e, 0x24, 0x3e, 0x0b, 0x6e, 0x61, 0x51, 0x56, 0xc3, 0x78, 0x97,
+	0xc8, 0x46, 0x5b, 0xe5, 0x5a, 0x0f, 0x85, 0x4e, 0x59, 0xae, 0xba, 0xfc, 0x7b, 0xfc, 0x0d, 0x00,
+	0x28, 0xe6, 0x79, 0x66, 0xc6, 0x00, 0x00, 0x00,
 }
@@ -3,6 +3,7 @@ package memory
 
 import (
 	"context"
+	"reflect"
 	"sync"
 	"time"
 
@@ -196,12 +197,28 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 	}
 
 	// refresh TTL and timestamp
+	updatedNodes := false
 	for _, n := range s.Nodes {
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 			logger.Debugf("Updated registration for service: %s, version: %s", s.Name, s.Version)
 		}
-		m.records[s.Name][s.Version].Nodes[n.Id].TTL = options.TTL
-		m.records[s.Name][s.Version].Nodes[n.Id].LastSeen = time.Now()
+		rn := m.records[s.Name][s.Version].Nodes[n.Id]
+		rn.TTL = options.TTL
+		rn.LastSeen = time.Now()
+
+		// pick up metadata changes e.g. the node draining
+		if !reflect.DeepEqual(rn.Metadata, n.Metadata) && (len(rn.Metadata) > 0 || len(n.Metadata) > 0) {
+			metadata := make(map[string]string, len(n.Metadata))
+			for k, v := range n.Metadata {
+				metadata[k] = v
+			}
+			rn.Metadata = metadata
+			updatedNodes = true
+		}
+	}
+
+	if updatedNodes {
+		go m.sendEvent(&registry.Result{Action: "update", Service: s})
 	}
 
 	return nil
@@ -12,6 +12,10 @@ var (
 	ErrNotFound = errors.New("service not found")
 	// Watcher stopped error when watcher is stopped
 	ErrWatcherStopped = errors.New("watcher stopped")
+
+	// DrainingKey is the node metadata key set to "true" by servers
+	// while they drain so selectors skip the node
+	DrainingKey = "draining"
 )
 
 // The registry provides an interface for service discovery
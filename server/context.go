@@ -6,6 +6,13 @@ import (
 )
 
 type serverKey struct{}
+type subscriberKey struct{}
+
+// subscriberHandler identifies the subscriber handler a message is passed to
+type subscriberHandler struct {
+	queue    string
+	endpoint string
+}
 
 func wait(ctx context.Context) *sync.WaitGroup {
 	if ctx == nil {
@@ -26,3 +33,17 @@ func FromContext(ctx context.Context) (Server, bool) {
 func NewContext(ctx context.Context, s Server) context.Context {
 	return context.WithValue(ctx, serverKey{}, s)
 }
+
+// SubscriberFromContext returns the queue and endpoint of the subscriber
+// handler the message is passed to. Several handlers get the messages of
+// a topic when it has several subscribers or a subscriber has several methods.
+func SubscriberFromContext(ctx context.Context) (queue, endpoint string, ok bool) {
+	h, ok := ctx.Value(subscriberKey{}).(subscriberHandler)
+	return h.queue, h.endpoint, ok
+}
+
+// NewSubscriberContext returns a context with the queue and endpoint of
+// the subscriber handler the message is passed to
+func NewSubscriberContext(ctx context.Context, queue, endpoint string) context.Context {
+	return context.WithValue(ctx, subscriberKey{}, subscriberHandler{queue: queue, endpoint: endpoint})
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	dlog "github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+var (
+	// CrashMetadata are the request headers copied into crash records
+	CrashMetadata = []string{
+		"Micro-From-Service",
+		"Micro-Trace-Id",
+		"Micro-Span-Id",
+		"Content-Type",
+		"Remote",
+	}
+)
+
+// Crash is the record of a panic recovered while serving a request or event
+type Crash struct {
+	// Id correlates the record with the error returned to the caller
+	Id string `json:"id"`
+	// Service is the name of the server
+	Service string `json:"service"`
+	// Endpoint or topic being served
+	Endpoint string `json:"endpoint"`
+	// RequestId is the Micro-Id of the request
+	RequestId string `json:"request_id,omitempty"`
+	// Panic is the value passed to panic
+	Panic string `json:"panic"`
+	// Stack of the goroutine which panicked
+	Stack string `json:"stack"`
+	// Metadata of the request
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Timestamp of the crash
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Panic is a value recovered from a panic on another goroutine, e.g a handler
+// run with a timeout, along with the stack where it happened. RecoverPanic
+// records the stack of a Panic rather than the one it's called from.
+type Panic struct {
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack of the goroutine which panicked
+	Stack []byte
+}
+
+// CrashTopic sets the broker topic crash records are published to
+func CrashTopic(topic string) Option {
+	return func(o *Options) {
+		o.CrashTopic = topic
+	}
+}
+
+// RecoverPanic turns the value recovered from a panic into an internal server
+// error. A crash record with the stack and the request details is written to the
+// debug log, and published to the crash topic if set, under the id of the error.
+// It should be called in the deferred recover so the stack is the panic's, or
+// with a *Panic carrying the stack if recovered elsewhere.
+func RecoverPanic(ctx context.Context, opts Options, endpoint string, p interface{}) error {
+	stack := debug.Stack()
+	if v, ok := p.(*Panic); ok {
+		p = v.Value
+		stack = v.Stack
+	}
+
+	crash := &Crash{
+		Id:        uuid.New().String(),
+		Service:   opts.Name,
+		Endpoint:  endpoint,
+		Panic:     fmt.Sprintf("%v", p),
+		Stack:     string(stack),
+		Metadata:  make(map[string]string),
+		Timestamp: time.Now(),
+	}
+
+	if md, ok := metadata.FromContext(ctx); ok {
+		crash.RequestId = crashHeader(md, "Micro-Id")
+		for _, k := range CrashMetadata {
+			if v := crashHeader(md, k); len(v) > 0 {
+				crash.Metadata[k] = v
+			}
+		}
+	}
+
+	if logger.V(logger.ErrorLevel, log) {
+		log.Errorf("panic recovered serving %s: %v [crash %s]", endpoint, p, crash.Id)
+		log.Error(crash.Stack)
+	}
+
+	dlog.DefaultLog.Write(dlog.Record{
+		Timestamp: crash.Timestamp,
+		Metadata: map[string]string{
+			"crash":    crash.Id,
+			"endpoint": endpoint,
+		},
+		Message: crash,
+	})
+
+	if len(opts.CrashTopic) > 0 && opts.Broker != nil {
+		if err := publishCrash(opts, crash); err != nil && logger.V(logger.ErrorLevel, log) {
+			log.Errorf("failed to publish crash %s to %s: %v", crash.Id, opts.CrashTopic, err)
+		}
+	}
+
+	return errors.InternalServerError(opts.Name, "panic recovered serving %s, crash id %s", endpoint, crash.Id)
+}
+
+// crashHeader returns the header as is or in lower case as sent by grpc
+func crashHeader(md metadata.Metadata, k string) string {
+	if v, ok := md.Get(k); ok {
+		return v
+	}
+	return md[strings.ToLower(k)]
+}
+
+func publishCrash(opts Options, crash *Crash) error {
+	b, err := json.Marshal(crash)
+	if err != nil {
+		return err
+	}
+	return opts.Broker.Publish(opts.CrashTopic, &broker.Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+			"Micro-Topic":  opts.CrashTopic,
+		},
+		Body: b,
+	})
+}
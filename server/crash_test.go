@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	dlog "github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+type panicRouter struct{}
+
+func (panicRouter) ProcessMessage(ctx context.Context, msg Message) error {
+	panic("boom")
+}
+
+func (panicRouter) ServeRequest(ctx context.Context, req Request, rsp Response) error {
+	panic("boom")
+}
+
+// timeoutRouter serves requests with a handler bound by a timeout
+type timeoutRouter struct {
+	panicRouter
+	h HandlerFunc
+}
+
+func (r timeoutRouter) ServeRequest(ctx context.Context, req Request, rsp Response) error {
+	return r.h(ctx, req, rsp)
+}
+
+func panicHandler(ctx context.Context, req Request, rsp interface{}) error {
+	panic("boom")
+}
+
+func TestCrash(t *testing.T) {
+	b := bmemory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	crashes := make(chan *Crash, 1)
+	if _, err := b.Subscribe("crashes", func(e broker.Event) error {
+		c := new(Crash)
+		if err := json.Unmarshal(e.Message().Body, c); err != nil {
+			return err
+		}
+		crashes <- c
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newRpcServer(Name("foo"), Broker(b), CrashTopic("crashes")).(*rpcServer)
+
+	ctx := metadata.NewContext(context.Background(), map[string]string{
+		"Micro-Id":           "1",
+		"Micro-From-Service": "bar",
+		"Authorization":      "secret",
+	})
+
+	err := s.serveRequest(ctx, panicRouter{}, &rpcRequest{endpoint: "Foo.Bar"}, &rpcResponse{})
+	e := errors.FromError(err)
+	if e.Code != 500 || e.Id != "foo" {
+		t.Fatalf("Expected an internal server error got %v", err)
+	}
+
+	var c *Crash
+	select {
+	case c = <-crashes:
+	default:
+		t.Fatal("Expected the crash to be published")
+	}
+
+	if !strings.Contains(e.Detail, c.Id) {
+		t.Fatalf("Expected the crash id %s in the error %v", c.Id, err)
+	}
+	if c.Endpoint != "Foo.Bar" || c.RequestId != "1" || c.Panic != "boom" || len(c.Stack) == 0 {
+		t.Fatalf("Unexpected crash record %+v", c)
+	}
+	if c.Metadata["Micro-From-Service"] != "bar" {
+		t.Fatalf("Expected the request metadata in the crash record got %v", c.Metadata)
+	}
+	if _, ok := c.Metadata["Authorization"]; ok {
+		t.Fatal("Expected only the selected metadata in the crash record")
+	}
+
+	// the record is in the debug log
+	recs, err := dlog.DefaultLog.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, r := range recs {
+		if r.Metadata["crash"] == c.Id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Expected the crash to be written to the debug log")
+	}
+
+	// events are recovered too
+	err = s.processMessage(ctx, panicRouter{}, &rpcMessage{topic: "foo.events"})
+	if e := errors.FromError(err); e.Code != 500 {
+		t.Fatalf("Expected an internal server error got %v", err)
+	}
+	if c := <-crashes; c.Endpoint != "foo.events" {
+		t.Fatalf("Expected the topic as the endpoint got %s", c.Endpoint)
+	}
+}
+
+func TestCrashTimeout(t *testing.T) {
+	b := bmemory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	crashes := make(chan *Crash, 1)
+	if _, err := b.Subscribe("crashes", func(e broker.Event) error {
+		c := new(Crash)
+		if err := json.Unmarshal(e.Message().Body, c); err != nil {
+			return err
+		}
+		crashes <- c
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newRpcServer(Name("foo"), Broker(b), CrashTopic("crashes")).(*rpcServer)
+
+	// the handler runs on its own goroutine with a timeout set
+	var timeouts Timeouts
+	h := timeouts.Wrapper("foo", func(string) time.Duration { return time.Second }, nil)(panicHandler)
+
+	err := s.serveRequest(context.Background(), timeoutRouter{h: h}, &rpcRequest{endpoint: "Foo.Bar"}, &rpcResponse{})
+	if e := errors.FromError(err); e.Code != 500 {
+		t.Fatalf("Expected an internal server error got %v", err)
+	}
+
+	c := <-crashes
+	if c.Panic != "boom" {
+		t.Fatalf("Expected the panic value got %s", c.Panic)
+	}
+	if !strings.Contains(c.Stack, "server.panicHandler") {
+		t.Fatalf("Expected the handler in the stack got %s", c.Stack)
+	}
+}
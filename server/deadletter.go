@@ -0,0 +1,399 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/store/memory"
+	"github.com/micro/go-micro/v2/util/backoff"
+	"github.com/micro/go-micro/v2/util/lock"
+)
+
+var (
+	// AttemptsTTL is how long the failed attempts of a message are counted
+	AttemptsTTL = time.Hour * 24
+
+	// serialises counting the attempts of a message
+	attemptLocks lock.Keys
+)
+
+const (
+	// DeadLetterSuffix is appended to a topic to name its dead letter topic
+	DeadLetterSuffix = ".dlq"
+
+	// headers describing the failure of a dead letter
+	DeadLetterIdHeader       = "Micro-Dead-Letter-Id"
+	DeadLetterTopicHeader    = "Micro-Dead-Letter-Topic"
+	DeadLetterQueueHeader    = "Micro-Dead-Letter-Queue"
+	DeadLetterErrorHeader    = "Micro-Dead-Letter-Error"
+	DeadLetterAttemptsHeader = "Micro-Dead-Letter-Attempts"
+	DeadLetterTimeHeader     = "Micro-Dead-Letter-Time"
+)
+
+// DeadLetter is a message whose delivery attempts ran out
+type DeadLetter struct {
+	// Id of the dead letter
+	Id string `json:"id"`
+	// Topic the message was published to
+	Topic string `json:"topic"`
+	// Queue of the subscriber which failed it
+	Queue string `json:"queue,omitempty"`
+	// Error returned by the last attempt
+	Error string `json:"error"`
+	// Attempts made to handle the message
+	Attempts int `json:"attempts"`
+	// Timestamp the message was dead lettered at
+	Timestamp time.Time `json:"timestamp"`
+	// Message as it was published
+	Message *broker.Message `json:"message"`
+}
+
+// DeadLetterTopic returns the topic the dead letters of a topic are published to
+func DeadLetterTopic(topic string) string {
+	return topic + DeadLetterSuffix
+}
+
+// SubscriberRedelivery sets how many times a failed message is handled and
+// how long the broker waits before delivering it again. Once the attempts
+// run out the message is published to the dead letter topic and acked. The
+// backoff defaults to backoff.Do. Messages are handled as the broker
+// delivers them if zero.
+func SubscriberRedelivery(maxAttempts int, fn func(attempt int) time.Duration) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.MaxAttempts = maxAttempts
+		o.Backoff = fn
+	}
+}
+
+// SubscriberAttempts sets the store the failed attempts of messages are
+// counted in. Share it between the instances of a queue to count the
+// attempts they all make. They're counted in memory by default. Counting
+// in a shared store is best effort, instances failing the same message at
+// once may count one attempt between them.
+func SubscriberAttempts(s store.Store) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.Attempts = s
+	}
+}
+
+// RedeliveryHandler wraps the broker handler of a subscriber with its
+// redelivery policy. A failed message is handed back to the broker with
+// broker.Nack to be delivered again after the backoff, brokers which
+// don't support it deliver it again as they do for any failed message.
+// The attempts are counted across deliveries by the message's Micro-Id
+// header or its content. Messages turned away by the server's limits
+// don't use up an attempt.
+func RedeliveryHandler(b broker.Broker, topic string, opts SubscriberOptions, h broker.Handler) broker.Handler {
+	if opts.MaxAttempts <= 0 {
+		return h
+	}
+
+	fn := opts.Backoff
+	if fn == nil {
+		fn = backoff.Do
+	}
+
+	attempts := opts.Attempts
+	if attempts == nil {
+		attempts = memory.NewStore()
+	}
+
+	return func(e broker.Event) error {
+		err := h(e)
+		if err == nil {
+			return nil
+		}
+
+		// the server was too busy, try again once it's hinted to
+		if errors.FromError(err).Code == http.StatusTooManyRequests {
+			var after time.Duration
+			var rerr *retryError
+			if stderrors.As(err, &rerr) {
+				after = rerr.after
+			}
+			nack(e, topic, after)
+			return err
+		}
+
+		key := attemptKey(topic, opts.Queue, e.Message())
+
+		attempt, cerr := countAttempt(attempts, key)
+		if cerr != nil {
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Failed to count the attempts of a message from %s: %v", topic, cerr)
+			}
+		}
+
+		if attempt < opts.MaxAttempts {
+			nack(e, topic, fn(attempt))
+			return err
+		}
+
+		// leave the message to the broker if it can't be dead lettered
+		if derr := publishDeadLetter(b, topic, opts.Queue, e.Message(), err, attempt); derr != nil {
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Failed to publish the dead letter of %s: %v", topic, derr)
+			}
+			return err
+		}
+
+		attempts.Delete(key)
+
+		if !opts.AutoAck {
+			return e.Ack()
+		}
+		return nil
+	}
+}
+
+// nack asks the broker to deliver the message again after the delay
+func nack(e broker.Event, topic string, delay time.Duration) {
+	if err := broker.Nack(e, delay); err != nil && err != broker.ErrNackNotSupported {
+		if logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Failed to nack a message from %s: %v", topic, err)
+		}
+	}
+}
+
+// attemptKey returns the key the attempts of the message are counted by
+func attemptKey(topic, queue string, m *broker.Message) string {
+	id := m.Header["Micro-Id"]
+	if len(id) == 0 {
+		keys := make([]string, 0, len(m.Header))
+		for k := range m.Header {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		h := sha256.New()
+		for _, k := range keys {
+			fmt.Fprintf(h, "%s=%s\n", k, m.Header[k])
+		}
+		h.Write(m.Body)
+		id = hex.EncodeToString(h.Sum(nil))
+	}
+	return "redelivery/" + topic + "/" + queue + "/" + id
+}
+
+// countAttempt adds a failed attempt returning how many there have been.
+// It's the first if they can't be read. Attempts in the process are counted
+// one at a time, the store has no atomic increment to do so across them.
+func countAttempt(s store.Store, key string) (int, error) {
+	attemptLocks.Lock(key)
+	defer attemptLocks.Unlock(key)
+
+	attempt := 1
+
+	recs, err := s.Read(key)
+	if err != nil && err != store.ErrNotFound {
+		return attempt, err
+	}
+	if len(recs) > 0 {
+		n, _ := strconv.Atoi(string(recs[0].Value))
+		attempt = n + 1
+	}
+
+	err = s.Write(&store.Record{
+		Key:   key,
+		Value: []byte(strconv.Itoa(attempt)),
+	}, store.WriteTTL(AttemptsTTL))
+
+	return attempt, err
+}
+
+func publishDeadLetter(b broker.Broker, topic, queue string, m *broker.Message, err error, attempts int) error {
+	hdr := make(map[string]string, len(m.Header)+6)
+	for k, v := range m.Header {
+		hdr[k] = v
+	}
+
+	hdr["Micro-Topic"] = DeadLetterTopic(topic)
+	hdr[DeadLetterIdHeader] = uuid.New().String()
+	hdr[DeadLetterTopicHeader] = topic
+	hdr[DeadLetterErrorHeader] = err.Error()
+	hdr[DeadLetterAttemptsHeader] = strconv.Itoa(attempts)
+	hdr[DeadLetterTimeHeader] = time.Now().Format(time.RFC3339Nano)
+	if len(queue) > 0 {
+		hdr[DeadLetterQueueHeader] = queue
+	}
+
+	return b.Publish(DeadLetterTopic(topic), &broker.Message{
+		Header: hdr,
+		Body:   m.Body,
+	})
+}
+
+// ParseDeadLetter reads the failure of a message received on a dead letter topic
+func ParseDeadLetter(m *broker.Message) (*DeadLetter, error) {
+	id := m.Header[DeadLetterIdHeader]
+	topic := m.Header[DeadLetterTopicHeader]
+	if len(id) == 0 || len(topic) == 0 {
+		return nil, fmt.Errorf("not a dead letter")
+	}
+
+	attempts, _ := strconv.Atoi(m.Header[DeadLetterAttemptsHeader])
+	timestamp, _ := time.Parse(time.RFC3339Nano, m.Header[DeadLetterTimeHeader])
+
+	// the message as originally published
+	hdr := make(map[string]string, len(m.Header))
+	for k, v := range m.Header {
+		if !strings.HasPrefix(k, "Micro-Dead-Letter-") {
+			hdr[k] = v
+		}
+	}
+	hdr["Micro-Topic"] = topic
+
+	return &DeadLetter{
+		Id:        id,
+		Topic:     topic,
+		Queue:     m.Header[DeadLetterQueueHeader],
+		Error:     m.Header[DeadLetterErrorHeader],
+		Attempts:  attempts,
+		Timestamp: timestamp,
+		Message: &broker.Message{
+			Header: hdr,
+			Body:   m.Body,
+		},
+	}, nil
+}
+
+// DeadLetters collects the dead letters of topics into a store so
+// they can be listed, inspected and published again
+type DeadLetters struct {
+	broker broker.Broker
+	store  store.Store
+
+	sync.Mutex
+	subs map[string]broker.Subscriber
+}
+
+// NewDeadLetters returns dead letters kept in the store
+func NewDeadLetters(b broker.Broker, s store.Store) *DeadLetters {
+	return &DeadLetters{
+		broker: b,
+		store:  s,
+		subs:   make(map[string]broker.Subscriber),
+	}
+}
+
+func deadLetterKey(topic, id string) string {
+	return "deadletter/" + topic + "/" + id
+}
+
+// Watch subscribes to the dead letter topic of the topic and stores what's
+// received. Pass a queue to store each dead letter once across instances.
+func (d *DeadLetters) Watch(topic string, opts ...broker.SubscribeOption) error {
+	d.Lock()
+	defer d.Unlock()
+
+	if _, ok := d.subs[topic]; ok {
+		return nil
+	}
+
+	sub, err := d.broker.Subscribe(DeadLetterTopic(topic), func(e broker.Event) error {
+		dl, err := ParseDeadLetter(e.Message())
+		if err != nil {
+			return err
+		}
+		return d.write(dl)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	d.subs[topic] = sub
+	return nil
+}
+
+func (d *DeadLetters) write(dl *DeadLetter) error {
+	b, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+	return d.store.Write(&store.Record{
+		Key:   deadLetterKey(dl.Topic, dl.Id),
+		Value: b,
+	})
+}
+
+// List returns the dead letters of the topic
+func (d *DeadLetters) List(topic string) ([]*DeadLetter, error) {
+	recs, err := d.store.Read(deadLetterKey(topic, ""), store.ReadPrefix())
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	dls := make([]*DeadLetter, 0, len(recs))
+	for _, r := range recs {
+		dl := new(DeadLetter)
+		if err := json.Unmarshal(r.Value, dl); err != nil {
+			return nil, err
+		}
+		dls = append(dls, dl)
+	}
+
+	return dls, nil
+}
+
+// Read returns the dead letter of the topic with the id
+func (d *DeadLetters) Read(topic, id string) (*DeadLetter, error) {
+	recs, err := d.store.Read(deadLetterKey(topic, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	dl := new(DeadLetter)
+	if err := json.Unmarshal(recs[0].Value, dl); err != nil {
+		return nil, err
+	}
+	return dl, nil
+}
+
+// Republish publishes the dead letter to its topic again and deletes it
+func (d *DeadLetters) Republish(topic, id string) error {
+	dl, err := d.Read(topic, id)
+	if err != nil {
+		return err
+	}
+	if err := d.broker.Publish(dl.Topic, dl.Message); err != nil {
+		return err
+	}
+	return d.Delete(topic, id)
+}
+
+// Delete drops the dead letter
+func (d *DeadLetters) Delete(topic, id string) error {
+	return d.store.Delete(deadLetterKey(topic, id))
+}
+
+// Stop unsubscribes from the dead letter topics
+func (d *DeadLetters) Stop() error {
+	d.Lock()
+	defer d.Unlock()
+
+	var err error
+	for topic, sub := range d.subs {
+		if uerr := sub.Unsubscribe(); uerr != nil {
+			err = uerr
+		}
+		delete(d.subs, topic)
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bfile "github.com/micro/go-micro/v2/broker/file"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/store"
+	smemory "github.com/micro/go-micro/v2/store/memory"
+)
+
+func TestDeadLetters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deadletter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := bfile.NewBroker(bfile.Dir(dir))
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	dls := NewDeadLetters(b, smemory.NewStore())
+	if err := dls.Watch("test"); err != nil {
+		t.Fatal(err)
+	}
+	defer dls.Stop()
+
+	ch := make(chan *broker.Message, 1)
+
+	// the handler fails until the message is dead lettered
+	var calls int32
+	opts := NewSubscriberOptions(SubscriberQueue("q"), SubscriberRedelivery(3, func(int) time.Duration {
+		return time.Millisecond
+	}))
+	handler := RedeliveryHandler(b, "test", opts, func(e broker.Event) error {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			// turned away by the limits which doesn't use up an attempt
+			return errors.TooManyRequests("test", "too many requests")
+		case 2, 3, 4:
+			return fmt.Errorf("failed")
+		}
+		ch <- e.Message()
+		return nil
+	})
+
+	if _, err := b.Subscribe("test", handler, broker.Queue("q")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Publish("test", &broker.Message{
+		Header: map[string]string{"Micro-Topic": "test", "foo": "bar"},
+		Body:   []byte("hello"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the broker delivers the message again without being held up
+	for i := 0; ; i++ {
+		if list, _ := dls.List("test"); len(list) > 0 {
+			break
+		}
+		if i == 500 {
+			t.Fatal("Expected the message to be dead lettered")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Fatalf("Expected 4 calls got %d", n)
+	}
+
+	list, err := dls.List("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 dead letter got %d", len(list))
+	}
+
+	dl, err := dls.Read("test", list[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl.Topic != "test" || dl.Queue != "q" || dl.Error != "failed" || dl.Attempts != 3 || dl.Timestamp.IsZero() {
+		t.Fatalf("Unexpected dead letter %+v", dl)
+	}
+
+	if err := dls.Republish("test", dl.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-ch:
+		if string(m.Body) != "hello" || m.Header["foo"] != "bar" || len(m.Header[DeadLetterErrorHeader]) > 0 {
+			t.Fatalf("Unexpected message %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the dead letter to be republished")
+	}
+
+	if list, _ := dls.List("test"); len(list) != 0 {
+		t.Fatalf("Expected the dead letter to be deleted got %d", len(list))
+	}
+}
+
+// slowStore takes a while to return reads so concurrent counts overlap
+type slowStore struct {
+	store.Store
+}
+
+func (s slowStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	recs, err := s.Store.Read(key, opts...)
+	time.Sleep(time.Millisecond)
+	return recs, err
+}
+
+func TestCountAttempt(t *testing.T) {
+	s := slowStore{smemory.NewStore()}
+
+	// deliveries failing at once each count an attempt
+	var wg sync.WaitGroup
+	counted := make(chan int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := countAttempt(s, "foo")
+			if err != nil {
+				t.Error(err)
+			}
+			counted <- n
+		}()
+	}
+	wg.Wait()
+	close(counted)
+
+	seen := make(map[int]bool)
+	for n := range counted {
+		seen[n] = true
+	}
+	for i := 1; i <= 20; i++ {
+		if !seen[i] {
+			t.Fatalf("Expected attempt %d to be counted got %v", i, seen)
+		}
+	}
+}
@@ -32,6 +32,8 @@ func microError(err *errors.Error) codes.Code {
 		return codes.FailedPrecondition
 	case http.StatusNotImplemented:
 		return codes.Unimplemented
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
 	case http.StatusInternalServerError:
 		return codes.Internal
 	case http.StatusServiceUnavailable:
@@ -4,10 +4,10 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	stderrors "errors"
 	"fmt"
 	"net"
 	"reflect"
-	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
@@ -51,6 +51,10 @@ type grpcServer struct {
 	srv  *grpc.Server
 	exit chan chan error
 	wg   *sync.WaitGroup
+	// requests in flight
+	reqs server.Requests
+	// requests which ran over their timeout
+	timeouts server.Timeouts
 
 	sync.RWMutex
 	opts        server.Options
@@ -60,6 +64,8 @@ type grpcServer struct {
 	started bool
 	// used for first registration
 	registered bool
+	// marks the node as draining on stop
+	draining bool
 
 	// registry service instance
 	rsvc *registry.Service
@@ -183,11 +189,13 @@ func (g *grpcServer) getListener() net.Listener {
 	return nil
 }
 
-func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
+func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err error) {
 	if g.wg != nil {
 		g.wg.Add(1)
 		defer g.wg.Done()
 	}
+	g.reqs.Add()
+	defer g.reqs.Done()
 
 	fullMethod, ok := grpc.MethodFromServerStream(stream)
 	if !ok {
@@ -245,6 +253,17 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 		}
 	}
 
+	// take a slot from the limiters, if we can't the request is rejected
+	// lower priority requests are rejected first as the limit nears
+	release, limitErr := server.Acquire(g.opts, serviceName+"."+methodName, g.priority(md, serviceName))
+	if limitErr != nil {
+		return rejected(stream, limitErr)
+	}
+	started := time.Now()
+	defer func() {
+		release(time.Since(started), err)
+	}()
+
 	// process via router
 	if g.opts.Router != nil {
 		cc, err := g.newGRPCCodec(ct)
@@ -274,7 +293,12 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 		}
 
 		// create a wrapped function
-		handler := func(ctx context.Context, req server.Request, rsp interface{}) error {
+		handler := func(ctx context.Context, req server.Request, rsp interface{}) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = server.RecoverPanic(ctx, g.opts, req.Endpoint(), r)
+				}
+			}()
 			return g.opts.Router.ServeRequest(ctx, req, rsp.(server.Response))
 		}
 
@@ -319,6 +343,48 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 	return g.processStream(stream, service, mtype, ct, ctx)
 }
 
+// timeout returns the max execution time of the endpoint
+func (g *grpcServer) timeout(endpoint string) time.Duration {
+	g.RLock()
+	defer g.RUnlock()
+
+	if h, ok := g.handlers[strings.Split(endpoint, ".")[0]]; ok {
+		if d, ok := h.Options().Timeouts[endpoint]; ok {
+			return d
+		}
+	}
+	return g.opts.HandlerTimeout
+}
+
+// priority returns the priority of a request to the service
+// falling back to the priority of its handler
+func (g *grpcServer) priority(md map[string]string, service string) int {
+	var def int
+
+	g.RLock()
+	if h, ok := g.handlers[service]; ok {
+		def = h.Options().Priority
+	}
+	g.RUnlock()
+
+	return server.RequestPriority(md, def)
+}
+
+// rejected returns the status for a request rejected by a limiter
+func rejected(stream grpc.ServerStream, err error) error {
+	// tell the client when to retry
+	if hdr := server.RetryHeader(err); hdr != nil {
+		stream.SetTrailer(metadata.New(hdr))
+	}
+
+	verr := errors.FromError(stderrors.Unwrap(err))
+	st, serr := status.New(microError(verr), verr.Error()).WithDetails(verr)
+	if serr != nil {
+		return serr
+	}
+	return st.Err()
+}
+
 func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service, mtype *methodType, ct string, ctx context.Context) error {
 	for {
 		var argv, replyv reflect.Value
@@ -369,11 +435,7 @@ func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service,
 		fn := func(ctx context.Context, req server.Request, rsp interface{}) (err error) {
 			defer func() {
 				if r := recover(); r != nil {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Error("panic recovered: ", r)
-						logger.Error(string(debug.Stack()))
-					}
-					err = errors.InternalServerError("go.micro.server", "panic recovered: %v", r)
+					err = server.RecoverPanic(ctx, g.opts, req.Endpoint(), r)
 				}
 			}()
 			returnValues = function.Call([]reflect.Value{service.rcvr, mtype.prepareContext(ctx), reflect.ValueOf(argv.Interface()), reflect.ValueOf(rsp)})
@@ -386,6 +448,9 @@ func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service,
 			return err
 		}
 
+		// apply the endpoint timeout closest to the handler
+		fn = g.timeouts.Wrapper(g.opts.Name, g.timeout, g.opts.Tracer)(fn)
+
 		// wrap the handler func
 		for i := len(g.opts.HdlrWrappers); i > 0; i-- {
 			fn = g.opts.HdlrWrappers[i-1](fn)
@@ -394,6 +459,12 @@ func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service,
 		statusDesc := ""
 		// execute the handler
 		if appErr := fn(ctx, r, replyv.Interface()); appErr != nil {
+			// tell the client whether to retry
+			if hdr := server.RetryHeader(appErr); hdr != nil {
+				stream.SetTrailer(metadata.New(hdr))
+				appErr = stderrors.Unwrap(appErr)
+			}
+
 			var errStatus *status.Status
 			switch verr := appErr.(type) {
 			case *errors.Error:
@@ -448,7 +519,12 @@ func (g *grpcServer) processStream(stream grpc.ServerStream, service *service, m
 	var returnValues []reflect.Value
 
 	// Invoke the method, providing a new value for the reply.
-	fn := func(ctx context.Context, req server.Request, stream interface{}) error {
+	fn := func(ctx context.Context, req server.Request, stream interface{}) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = server.RecoverPanic(ctx, opts, req.Endpoint(), r)
+			}
+		}()
 		returnValues = function.Call([]reflect.Value{service.rcvr, mtype.prepareContext(ctx), reflect.ValueOf(stream)})
 		if err := returnValues[0].Interface(); err != nil {
 			return err.(error)
@@ -461,10 +537,16 @@ func (g *grpcServer) processStream(stream grpc.ServerStream, service *service, m
 		fn = opts.HdlrWrappers[i-1](fn)
 	}
 
+	// wrap the stream
+	var ws server.Stream = ss
+	for i := len(opts.StreamWrappers); i > 0; i-- {
+		ws = opts.StreamWrappers[i-1](ws)
+	}
+
 	statusCode := codes.OK
 	statusDesc := ""
 
-	if appErr := fn(ctx, r, ss); appErr != nil {
+	if appErr := fn(ctx, r, ws); appErr != nil {
 		var err error
 		var errStatus *status.Status
 		switch verr := appErr.(type) {
@@ -570,6 +652,7 @@ func (g *grpcServer) Register() error {
 	g.RLock()
 	rsvc := g.rsvc
 	config := g.opts
+	draining := g.draining
 	g.RUnlock()
 
 	regFunc := func(service *registry.Service) error {
@@ -637,6 +720,11 @@ func (g *grpcServer) Register() error {
 	// make copy of metadata
 	md := meta.Copy(config.Metadata)
 
+	// let selectors know to skip the node
+	if draining {
+		md[registry.DrainingKey] = "true"
+	}
+
 	// register service
 	node := &registry.Node{
 		Id:       config.Name + "-" + config.Id,
@@ -712,7 +800,7 @@ func (g *grpcServer) Register() error {
 	defer g.Unlock()
 
 	for sb := range g.subscribers {
-		handler := g.createSubHandler(sb, g.opts)
+		handler := server.RedeliveryHandler(config.Broker, sb.Topic(), sb.Options(), g.createSubHandler(sb, g.opts))
 		var opts []broker.SubscribeOption
 		if queue := sb.Options().Queue; len(queue) > 0 {
 			opts = append(opts, broker.Queue(queue))
@@ -824,6 +912,38 @@ func (g *grpcServer) Deregister() error {
 	return nil
 }
 
+// drain marks the node as draining in the registry so selectors skip
+// it then keeps serving for the grace period and until the requests in
+// flight are done, waiting no longer than the drain timeout
+func (g *grpcServer) drain() {
+	g.Lock()
+	config := g.opts
+	if config.DrainTimeout <= 0 || !g.registered {
+		g.Unlock()
+		return
+	}
+	g.draining = true
+	// don't register the cached service
+	g.rsvc = nil
+	g.Unlock()
+
+	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+		logger.Infof("Server %s-%s draining for up to %v", config.Name, config.Id, config.DrainTimeout)
+	}
+
+	if err := g.Register(); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Server register error: ", err)
+		}
+	}
+
+	// give watchers and selector caches time to see the node draining
+	grace := server.DrainGraceFor(config)
+	time.Sleep(grace)
+
+	g.reqs.Wait(config.DrainTimeout - grace)
+}
+
 func (g *grpcServer) Start() error {
 	g.RLock()
 	if g.started {
@@ -867,6 +987,10 @@ func (g *grpcServer) Start() error {
 	g.opts.Address = ts.Addr().String()
 	g.Unlock()
 
+	// expose the limiters and timeouts in the debug stats
+	server.RegisterLimits(config)
+	server.RegisterTimeouts(&g.timeouts)
+
 	// only connect if we're subscribed
 	if len(g.subscribers) > 0 {
 		// connect to the broker
@@ -926,6 +1050,9 @@ func (g *grpcServer) Start() error {
 			}
 		}
 
+		// keep serving while clients move away
+		g.drain()
+
 		// deregister self
 		if err := g.Deregister(); err != nil {
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
@@ -991,9 +1118,13 @@ func (g *grpcServer) Stop() error {
 		g.Lock()
 		g.rsvc = nil
 		g.started = false
+		g.draining = false
 		g.Unlock()
 	}
 
+	server.DeregisterLimits(g.Options())
+	server.DeregisterTimeouts(&g.timeouts)
+
 	return err
 }
 
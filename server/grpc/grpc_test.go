@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/micro/go-micro/v2"
 	bmemory "github.com/micro/go-micro/v2/broker/memory"
@@ -201,3 +202,88 @@ func TestGRPCServer(t *testing.T) {
 		}
 	}
 }
+
+func TestGRPCLimit(t *testing.T) {
+	r := rmemory.NewRegistry()
+	tr := tgrpc.NewTransport()
+	l := server.NewFixedLimiter(1)
+	s := gsrv.NewServer(
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tr),
+		server.EndpointLimit("Test.Call", l),
+	)
+	c := gcli.NewClient(
+		client.Registry(r),
+		client.Transport(tr),
+	)
+
+	pb.RegisterTestHandler(s, &testServer{})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"})
+	if err := c.Call(context.TODO(), req, new(pb.Response)); err != nil {
+		t.Fatalf("error calling server: %v", err)
+	}
+
+	// take the only slot so the next request is rejected
+	l.Acquire(server.PriorityCritical)
+	defer l.Release(0, nil)
+
+	err := c.Call(context.TODO(), req, new(pb.Response), client.WithRetries(0))
+	if e, ok := err.(*errors.Error); !ok || e.Code != 429 {
+		t.Fatalf("Expected a 429 error got %#v", err)
+	}
+	if d, ok := client.RetryAfter(err); !ok || d != server.DefaultLimitRetryAfter {
+		t.Fatalf("Expected a retry after hint got %v", d)
+	}
+}
+
+// slowServer holds requests until it's released
+type slowServer struct {
+	*testServer
+	release chan struct{}
+}
+
+func (s *slowServer) Call(ctx context.Context, req *pb.Request, rsp *pb.Response) error {
+	<-s.release
+	return nil
+}
+
+func TestGRPCTimeout(t *testing.T) {
+	r := rmemory.NewRegistry()
+	tr := tgrpc.NewTransport()
+	s := gsrv.NewServer(
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tr),
+	)
+	c := gcli.NewClient(
+		client.Registry(r),
+		client.Transport(tr),
+	)
+
+	h := &slowServer{testServer: &testServer{}, release: make(chan struct{})}
+	pb.RegisterTestHandler(s, h, server.EndpointTimeout("Test.Call", time.Millisecond*50))
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+	defer close(h.release)
+
+	// the timeout error doesn't wait for the handler
+	req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"})
+	err := c.Call(context.TODO(), req, new(pb.Response), client.WithRetries(0))
+	if e := errors.FromError(err); e.Code != 408 || e.Id != "foo" {
+		t.Fatalf("Expected a timeout error got %v", err)
+	}
+
+	if stats := server.ReadTimeouts()["Test.Call"]; stats.Exceeded != 1 {
+		t.Fatalf("Expected 1 request over the timeout got %+v", stats)
+	}
+}
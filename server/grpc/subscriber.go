@@ -4,12 +4,10 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"runtime/debug"
+	"runtime"
 	"strings"
 
 	"github.com/micro/go-micro/v2/broker"
-	"github.com/micro/go-micro/v2/errors"
-	"github.com/micro/go-micro/v2/logger"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/server"
@@ -20,6 +18,8 @@ const (
 )
 
 type handler struct {
+	// name identifies the handler of the subscriber
+	name    string
 	method  reflect.Value
 	reqType reflect.Type
 	ctxType reflect.Type
@@ -49,6 +49,7 @@ func newSubscriber(topic string, sub interface{}, opts ...server.SubscriberOptio
 
 	if typ := reflect.TypeOf(sub); typ.Kind() == reflect.Func {
 		h := &handler{
+			name:   runtime.FuncForPC(reflect.ValueOf(sub).Pointer()).Name(),
 			method: reflect.ValueOf(sub),
 		}
 
@@ -77,6 +78,7 @@ func newSubscriber(topic string, sub interface{}, opts ...server.SubscriberOptio
 		for m := 0; m < typ.NumMethod(); m++ {
 			method := typ.Method(m)
 			h := &handler{
+				name:   name + "." + method.Name,
 				method: method.Func,
 			}
 
@@ -171,11 +173,8 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 
 		defer func() {
 			if r := recover(); r != nil {
-				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-					logger.Error("panic recovered: ", r)
-					logger.Error(string(debug.Stack()))
-				}
-				err = errors.InternalServerError("go.micro.server", "panic recovered: %v", r)
+				ctx := metadata.NewContext(context.Background(), p.Message().Header)
+				err = server.RecoverPanic(ctx, opts, sb.Topic(), r)
 			}
 		}()
 
@@ -246,6 +245,8 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 				fn = opts.SubWrappers[i-1](fn)
 			}
 
+			hctx := server.NewSubscriberContext(ctx, sb.opts.Queue, handler.name)
+
 			if g.wg != nil {
 				g.wg.Add(1)
 			}
@@ -253,7 +254,7 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 				if g.wg != nil {
 					defer g.wg.Done()
 				}
-				err := fn(ctx, &rpcMessage{
+				err := fn(hctx, &rpcMessage{
 					topic:       sb.topic,
 					contentType: ct,
 					payload:     req.Interface(),
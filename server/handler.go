@@ -1,12 +1,21 @@
 package server
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/store"
+)
 
 type HandlerOption func(*HandlerOptions)
 
 type HandlerOptions struct {
 	Internal bool
 	Metadata map[string]map[string]string
+	// Priority of requests which don't set their own
+	Priority int
+	// Timeouts are the max execution times by endpoint
+	Timeouts map[string]time.Duration
 }
 
 type SubscriberOption func(*SubscriberOptions)
@@ -18,6 +27,13 @@ type SubscriberOptions struct {
 	Queue    string
 	Internal bool
 	Context  context.Context
+	// MaxAttempts is the number of times a failed message is
+	// handled before it's dead lettered, unlimited if zero
+	MaxAttempts int
+	// Backoff returns how long to wait before the next attempt
+	Backoff func(attempt int) time.Duration
+	// Attempts is the store the failed attempts are counted in
+	Attempts store.Store
 }
 
 // EndpointMetadata is a Handler option that allows metadata to be added to
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+var (
+	// DefaultLimitRetryAfter is the retry hint sent with requests rejected by a limiter
+	DefaultLimitRetryAfter = time.Millisecond * 100
+
+	// the limiters of the running servers
+	limits = &limitRegistry{
+		limiters: make(map[Limiter]string),
+	}
+)
+
+// Limiter caps the number of requests in flight
+type Limiter interface {
+	// Acquire takes a slot for a request of the priority. It returns false
+	// if over the share of the limit the priority can use.
+	Acquire(priority int) bool
+	// Release frees the slot recording the latency and error of the request.
+	// A zero latency frees it without adjusting the limit.
+	Release(latency time.Duration, err error)
+	// Stats returns the live values of the limiter
+	Stats() LimitStats
+}
+
+// LimitStats are the live values of a limiter
+type LimitStats struct {
+	// Limit is the current number of requests allowed in flight
+	Limit uint64
+	// Inflight is the number of requests being served
+	Inflight uint64
+	// Rejected is the total number of requests rejected
+	Rejected uint64
+}
+
+// limiter counts the requests in flight against a limit which
+// is adjusted by the algorithm as requests are released
+type limiter struct {
+	sync.Mutex
+	limit    float64
+	min      float64
+	max      float64
+	inflight int
+	rejected uint64
+	// adjust updates the limit, called with the lock held
+	adjust func(l *limiter, latency time.Duration, dropped bool)
+}
+
+// NewFixedLimiter returns a limiter which allows n requests in flight
+func NewFixedLimiter(n int) Limiter {
+	return &limiter{
+		limit: float64(n),
+		min:   float64(n),
+		max:   float64(n),
+	}
+}
+
+// NewAIMDLimiter returns a limiter which adds one to the limit while requests
+// succeed under the latency threshold and backs off by 10% when they don't
+func NewAIMDLimiter(min, max int, threshold time.Duration) Limiter {
+	return &limiter{
+		limit: float64(min),
+		min:   float64(min),
+		max:   float64(max),
+		adjust: func(l *limiter, latency time.Duration, dropped bool) {
+			if dropped || (threshold > 0 && latency > threshold) {
+				l.limit *= 0.9
+				return
+			}
+			// only grow when the limit is being used
+			if float64(l.inflight)*2 >= l.limit {
+				l.limit++
+			}
+		},
+	}
+}
+
+// NewGradientLimiter returns a limiter which adjusts the limit by the
+// gradient between the long term and recent latency. The limit shrinks as
+// latency rises above what's normal and grows back while it's steady.
+func NewGradientLimiter(min, max int) Limiter {
+	var short, long float64
+
+	return &limiter{
+		limit: float64(min),
+		min:   float64(min),
+		max:   float64(max),
+		adjust: func(l *limiter, latency time.Duration, dropped bool) {
+			rtt := float64(latency)
+			if dropped {
+				// treat it as twice as slow as normal
+				rtt = math.Max(rtt, long*2)
+			}
+			if rtt <= 0 {
+				return
+			}
+
+			if long == 0 {
+				short, long = rtt, rtt
+				return
+			}
+			short = short*0.9 + rtt*0.1
+			long = long*0.99 + rtt*0.01
+
+			// recover quickly once a spell of high latency is over
+			if long > short*2 {
+				long *= 0.95
+			}
+
+			// don't grow when the limit isn't being used
+			if float64(l.inflight)*2 < l.limit && short <= long {
+				return
+			}
+
+			gradient := math.Max(0.5, math.Min(1.0, long/short))
+			limit := l.limit*gradient + math.Sqrt(l.limit)
+			l.limit = l.limit*0.8 + limit*0.2
+		},
+	}
+}
+
+func (l *limiter) Acquire(priority int) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	// lower priorities only get part of the limit
+	limit := int(l.limit * share(priority))
+	if limit < 1 {
+		limit = 1
+	}
+
+	if l.inflight >= limit {
+		l.rejected++
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+func (l *limiter) Release(latency time.Duration, err error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.adjust != nil && latency > 0 {
+		l.adjust(l, latency, dropped(err))
+		l.limit = math.Max(l.min, math.Min(l.max, l.limit))
+	}
+	if l.inflight > 0 {
+		l.inflight--
+	}
+}
+
+func (l *limiter) Stats() LimitStats {
+	l.Lock()
+	defer l.Unlock()
+
+	return LimitStats{
+		Limit:    uint64(l.limit),
+		Inflight: uint64(l.inflight),
+		Rejected: l.rejected,
+	}
+}
+
+// dropped returns true if the request timed out
+func dropped(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	return errors.FromError(err).Code == 408
+}
+
+// limitRegistry tracks the limiters of the running servers
+type limitRegistry struct {
+	sync.RWMutex
+	// limiter -> endpoint
+	limiters map[Limiter]string
+}
+
+func (r *limitRegistry) register(opts Options) {
+	r.Lock()
+	defer r.Unlock()
+
+	if opts.Limiter != nil {
+		r.limiters[opts.Limiter] = "*"
+	}
+	for endpoint, l := range opts.EndpointLimiters {
+		r.limiters[l] = endpoint
+	}
+}
+
+func (r *limitRegistry) deregister(opts Options) {
+	r.Lock()
+	defer r.Unlock()
+
+	if opts.Limiter != nil {
+		delete(r.limiters, opts.Limiter)
+	}
+	for _, l := range opts.EndpointLimiters {
+		delete(r.limiters, l)
+	}
+}
+
+// RegisterLimits exposes the limiters of a server starting up in ReadLimits
+func RegisterLimits(opts Options) {
+	limits.register(opts)
+}
+
+// DeregisterLimits removes the limiters of a server which stopped
+func DeregisterLimits(opts Options) {
+	limits.deregister(opts)
+}
+
+// ReadLimits returns the live values of the limiters of the running
+// servers keyed by endpoint. The server wide limiter is keyed by "*".
+func ReadLimits() map[string]LimitStats {
+	limits.RLock()
+	defer limits.RUnlock()
+
+	stats := make(map[string]LimitStats, len(limits.limiters))
+	for l, endpoint := range limits.limiters {
+		s := l.Stats()
+		if v, ok := stats[endpoint]; ok {
+			s.Limit += v.Limit
+			s.Inflight += v.Inflight
+			s.Rejected += v.Rejected
+		}
+		stats[endpoint] = s
+	}
+	return stats
+}
+
+// Acquire takes a slot from the server wide and endpoint limiters of the
+// options. It returns a func to release them or an error if over either
+// limit. It's used by the server implementations for each request.
+func Acquire(opts Options, endpoint string, priority int) (func(time.Duration, error), error) {
+	var held []Limiter
+
+	release := func(d time.Duration, err error) {
+		for _, l := range held {
+			l.Release(d, err)
+		}
+	}
+
+	for _, l := range []Limiter{opts.Limiter, opts.EndpointLimiters[endpoint]} {
+		if l == nil {
+			continue
+		}
+		if !l.Acquire(priority) {
+			// give back what we took without counting it
+			for _, h := range held {
+				h.Release(0, nil)
+			}
+			after := opts.LimitRetryAfter
+			if after == 0 {
+				after = DefaultLimitRetryAfter
+			}
+			return nil, RetryAfter(errors.TooManyRequests(opts.Name, "too many requests for %s", endpoint), after)
+		}
+		held = append(held, l)
+	}
+
+	return release, nil
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+func TestLimitAcquire(t *testing.T) {
+	global := NewFixedLimiter(2)
+	endpoint := NewFixedLimiter(1)
+
+	opts := Options{Name: "foo"}
+	Limit(global)(&opts)
+	EndpointLimit("Foo.Bar", endpoint)(&opts)
+
+	release, err := Acquire(opts, "Foo.Bar", PriorityCritical)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// over the endpoint limit
+	_, err = Acquire(opts, "Foo.Bar", PriorityCritical)
+	if err == nil {
+		t.Fatal("Expected the request to be rejected")
+	}
+	if e := errors.FromError(err); e.Code != 429 {
+		t.Fatalf("Expected a 429 error got %v", err)
+	}
+	if hdr := RetryHeader(err); hdr["Micro-Retry-After"] != "100" {
+		t.Fatalf("Expected a retry after hint got %v", hdr)
+	}
+
+	// the global slot taken by the rejected request was given back
+	if s := global.Stats(); s.Inflight != 1 {
+		t.Fatalf("Expected 1 request in flight got %d", s.Inflight)
+	}
+
+	// other endpoints only count against the global limit
+	if _, err := Acquire(opts, "Foo.Baz", PriorityCritical); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire(opts, "Foo.Baz", PriorityCritical); err == nil {
+		t.Fatal("Expected the request to be rejected")
+	}
+
+	release(time.Millisecond, nil)
+	if s := endpoint.Stats(); s.Inflight != 0 || s.Rejected != 1 {
+		t.Fatalf("Unexpected endpoint stats %+v", s)
+	}
+}
+
+func TestLimitAIMD(t *testing.T) {
+	l := NewAIMDLimiter(2, 10, time.Millisecond*100)
+
+	// grows while the limit is used and requests are fast
+	for i := 0; i < 20; i++ {
+		n := int(l.Stats().Limit)
+		for j := 0; j < n; j++ {
+			l.Acquire(PriorityCritical)
+		}
+		for j := 0; j < n; j++ {
+			l.Release(time.Millisecond, nil)
+		}
+	}
+	if s := l.Stats(); s.Limit != 10 {
+		t.Fatalf("Expected the limit to grow to 10 got %d", s.Limit)
+	}
+
+	// backs off on timeouts
+	l.Acquire(PriorityCritical)
+	l.Release(time.Millisecond, errors.Timeout("foo", "timeout"))
+	if s := l.Stats(); s.Limit != 9 {
+		t.Fatalf("Expected the limit to back off to 9 got %d", s.Limit)
+	}
+
+	// and when slow
+	l.Acquire(PriorityCritical)
+	l.Release(time.Second, nil)
+	if s := l.Stats(); s.Limit != 8 {
+		t.Fatalf("Expected the limit to back off to 8 got %d", s.Limit)
+	}
+}
+
+func TestLimitGradient(t *testing.T) {
+	l := NewGradientLimiter(5, 100)
+
+	run := func(rounds int, latency time.Duration) {
+		for i := 0; i < rounds; i++ {
+			n := int(l.Stats().Limit)
+			for j := 0; j < n; j++ {
+				l.Acquire(PriorityCritical)
+			}
+			for j := 0; j < n; j++ {
+				l.Release(latency, nil)
+			}
+		}
+	}
+
+	run(200, time.Millisecond*10)
+	grown := l.Stats().Limit
+	if grown <= 5 {
+		t.Fatalf("Expected the limit to grow got %d", grown)
+	}
+
+	// latency jumps so the limit is cut
+	run(2, time.Millisecond*100)
+	if s := l.Stats(); s.Limit >= grown {
+		t.Fatalf("Expected the limit to shrink from %d got %d", grown, s.Limit)
+	}
+}
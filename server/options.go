@@ -29,6 +29,8 @@ type Options struct {
 	Version      string
 	HdlrWrappers []HandlerWrapper
 	SubWrappers  []SubscriberWrapper
+	// StreamWrappers wrap the stream of every streaming endpoint
+	StreamWrappers []StreamWrapper
 
 	// RegisterCheck runs a check function before registering the service
 	RegisterCheck func(context.Context) error
@@ -37,9 +39,37 @@ type Options struct {
 	// The interval on which to register
 	RegisterInterval time.Duration
 
+	// DrainTimeout is the longest to wait for the requests in flight
+	// after the node is marked as draining before the server stops
+	DrainTimeout time.Duration
+	// DrainGrace is the least time to keep serving after the node is
+	// marked as draining so registry watchers and selector caches see it
+	DrainGrace time.Duration
+
 	// The router for requests
 	Router Router
 
+	// Limiter caps the requests in flight across the server
+	Limiter Limiter
+	// EndpointLimiters cap the requests in flight per endpoint
+	EndpointLimiters map[string]Limiter
+	// LimitRetryAfter is the retry hint sent with rejected requests
+	LimitRetryAfter time.Duration
+
+	// HandlerTimeout is the max execution time of endpoints
+	// which don't set their own
+	HandlerTimeout time.Duration
+
+	// CrashTopic is the broker topic crash records are published to
+	CrashTopic string
+
+	// StreamWindow is the number of messages buffered for a stream
+	// before the client has to wait, flow control is off if zero
+	StreamWindow int
+	// StreamSendTimeout is how long a stream Send waits for the
+	// client to consume, forever if zero
+	StreamSendTimeout time.Duration
+
 	// TLSConfig specifies tls.Config for secure serving
 	TLSConfig *tls.Config
 
@@ -240,6 +270,45 @@ func WithRouter(r Router) Option {
 	}
 }
 
+// StreamWindow turns on flow control for streams from clients which set a
+// window too. It sets the number of messages buffered for a stream, a
+// client sending faster than the handler consumes waits for the window to
+// open. Flow control is off by default.
+func StreamWindow(n int) Option {
+	return func(o *Options) {
+		o.StreamWindow = n
+	}
+}
+
+// StreamSendTimeout sets how long a stream Send waits for the client to
+// consume before failing. It waits for as long as the stream lives if zero.
+func StreamSendTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.StreamSendTimeout = d
+	}
+}
+
+// DrainTimeout sets the longest to keep serving on stop after the node is
+// marked as draining in the registry. The server stops as soon as the
+// requests in flight are done. Selectors skip draining nodes so clients
+// move away before the subscribers and listeners are closed.
+func DrainTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = d
+	}
+}
+
+// DrainGrace sets the least time to keep serving on stop after the node
+// is marked as draining, even with no requests in flight, so clients see
+// the change before the listener closes. Set it to at least the selector
+// cache TTL. It defaults to DefaultDrainGrace and is capped by the drain
+// timeout.
+func DrainGrace(d time.Duration) Option {
+	return func(o *Options) {
+		o.DrainGrace = d
+	}
+}
+
 // Wait tells the server to wait for requests to finish before exiting
 // If `wg` is nil, server only wait for completion of rpc handler.
 // For user need finer grained control, pass a concrete `wg` here, server will
@@ -256,6 +325,31 @@ func Wait(wg *sync.WaitGroup) Option {
 	}
 }
 
+// Limit caps the requests in flight across the server. Requests over
+// the limit are rejected with a 429 error and a retry after hint.
+func Limit(l Limiter) Option {
+	return func(o *Options) {
+		o.Limiter = l
+	}
+}
+
+// EndpointLimit caps the requests in flight for an endpoint e.g Greeter.Hello
+func EndpointLimit(endpoint string, l Limiter) Option {
+	return func(o *Options) {
+		if o.EndpointLimiters == nil {
+			o.EndpointLimiters = make(map[string]Limiter)
+		}
+		o.EndpointLimiters[endpoint] = l
+	}
+}
+
+// LimitRetryAfter sets the retry hint sent with requests rejected by a limiter
+func LimitRetryAfter(d time.Duration) Option {
+	return func(o *Options) {
+		o.LimitRetryAfter = d
+	}
+}
+
 // Adds a handler Wrapper to a list of options passed into the server
 func WrapHandler(w HandlerWrapper) Option {
 	return func(o *Options) {
@@ -269,3 +363,11 @@ func WrapSubscriber(w SubscriberWrapper) Option {
 		o.SubWrappers = append(o.SubWrappers, w)
 	}
 }
+
+// WrapStream adds a stream wrapper to a list of options passed into the server.
+// It wraps the stream of every streaming endpoint e.g to hook Send and Recv.
+func WrapStream(w StreamWrapper) Option {
+	return func(o *Options) {
+		o.StreamWrappers = append(o.StreamWrappers, w)
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// PriorityLow is for work which can wait e.g batch jobs
+	PriorityLow = iota + 1
+	// PriorityNormal is the priority of requests which don't set one
+	PriorityNormal
+	// PriorityHigh is for interactive user requests
+	PriorityHigh
+	// PriorityCritical is for requests which must get through e.g health checks
+	PriorityCritical
+)
+
+var (
+	// PriorityHeader is the header carrying the priority of a request or event
+	PriorityHeader = "Micro-Priority"
+
+	// DefaultEventPriority is the priority of broker events which don't set one
+	DefaultEventPriority = PriorityLow
+
+	// PriorityShare is the share of a limiter each priority can use. As the
+	// requests in flight near the limit the low priority ones are shed first.
+	// Requests which don't set a priority can use the whole limit.
+	PriorityShare = map[int]float64{
+		PriorityLow:      0.5,
+		PriorityNormal:   1,
+		PriorityHigh:     1,
+		PriorityCritical: 1,
+	}
+)
+
+// HandlerPriority sets the priority of requests to the handler
+// which don't set their own through the priority header
+func HandlerPriority(p int) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Priority = p
+	}
+}
+
+// RequestPriority returns the priority from the header, which may be in
+// lower case, falling back to def if it's missing or invalid
+func RequestPriority(hdr map[string]string, def int) int {
+	v := getHeader(PriorityHeader, hdr)
+	if len(v) == 0 {
+		v = hdr[strings.ToLower(PriorityHeader)]
+	}
+	if len(v) > 0 {
+		if p, err := strconv.Atoi(v); err == nil {
+			return clampPriority(p)
+		}
+	}
+	if def == 0 {
+		return PriorityNormal
+	}
+	return clampPriority(def)
+}
+
+func clampPriority(p int) int {
+	if p < PriorityLow {
+		return PriorityLow
+	}
+	if p > PriorityCritical {
+		return PriorityCritical
+	}
+	return p
+}
+
+// share returns the share of a limit the priority can use
+func share(p int) float64 {
+	if s, ok := PriorityShare[p]; ok {
+		return s
+	}
+	return 1
+}
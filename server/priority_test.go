@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"testing"
+)
+
+type PriorityGreeter struct{}
+
+func (t *PriorityGreeter) Hello(ctx context.Context, req *struct{}, rsp *struct{}) error {
+	return nil
+}
+
+func TestPriority(t *testing.T) {
+	testData := []struct {
+		header   map[string]string
+		def      int
+		priority int
+	}{
+		{nil, 0, PriorityNormal},
+		{nil, PriorityLow, PriorityLow},
+		{map[string]string{"Micro-Priority": "3"}, PriorityLow, PriorityHigh},
+		{map[string]string{"X-Micro-Priority": "4"}, 0, PriorityCritical},
+		{map[string]string{"micro-priority": "1"}, PriorityHigh, PriorityLow},
+		{map[string]string{"Micro-Priority": "100"}, 0, PriorityCritical},
+		{map[string]string{"Micro-Priority": "bad"}, PriorityHigh, PriorityHigh},
+	}
+
+	for _, d := range testData {
+		if p := RequestPriority(d.header, d.def); p != d.priority {
+			t.Fatalf("Expected priority %d for %v got %d", d.priority, d.header, p)
+		}
+	}
+
+	s := newRpcServer().(*rpcServer)
+	if err := s.Handle(s.NewHandler(&PriorityGreeter{}, HandlerPriority(PriorityHigh))); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.priority(nil, "PriorityGreeter.Hello"); p != PriorityHigh {
+		t.Fatalf("Expected the handler priority got %d", p)
+	}
+	if p := s.priority(map[string]string{"Micro-Priority": "1"}, "PriorityGreeter.Hello"); p != PriorityLow {
+		t.Fatalf("Expected the request priority got %d", p)
+	}
+	if p := s.priority(nil, "Other.Hello"); p != PriorityNormal {
+		t.Fatalf("Expected the default priority got %d", p)
+	}
+}
+
+func TestPriorityShedding(t *testing.T) {
+	opts := Options{Name: "foo"}
+	Limit(NewFixedLimiter(10))(&opts)
+
+	admit := func(p int) int {
+		var n int
+		for {
+			if _, err := Acquire(opts, "Foo.Bar", p); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+
+	// each priority fills up to its share of the limit
+	for _, d := range []struct {
+		priority int
+		admitted int
+	}{
+		{PriorityLow, 5},
+		{PriorityNormal, 5},
+		{PriorityHigh, 0},
+		{PriorityCritical, 0},
+	} {
+		if n := admit(d.priority); n != d.admitted {
+			t.Fatalf("Expected %d requests of priority %d to be admitted got %d", d.admitted, d.priority, n)
+		}
+	}
+
+	// requests without a priority get the whole limit
+	opts = Options{Name: "foo"}
+	Limit(NewFixedLimiter(10))(&opts)
+	if n := admit(PriorityNormal); n != 10 {
+		t.Fatalf("Expected 10 requests of normal priority to be admitted got %d", n)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// retryError is a handler error which tells the client
+// whether and when the request can be retried
+type retryError struct {
+	error
+	// don't retry the request
+	never bool
+	// time to wait before retrying
+	after time.Duration
+}
+
+func (e *retryError) Unwrap() error {
+	return e.error
+}
+
+// DoNotRetry wraps a handler error to ask the client not to retry the request
+func DoNotRetry(err error) error {
+	return &retryError{error: err, never: true}
+}
+
+// RetryAfter wraps a handler error to ask the client to wait d before retrying
+func RetryAfter(err error, d time.Duration) error {
+	return &retryError{error: err, after: d}
+}
+
+// RetryHeader returns the response headers carrying the retry hint of the error
+func RetryHeader(err error) map[string]string {
+	rerr, ok := err.(*retryError)
+	if !ok {
+		return nil
+	}
+	if rerr.never {
+		return map[string]string{"Micro-Retry": "false"}
+	}
+	return map[string]string{"Micro-Retry-After": fmt.Sprintf("%d", rerr.after.Milliseconds())}
+}
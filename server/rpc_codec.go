@@ -321,8 +321,10 @@ func (c *rpcCodec) Write(r *codec.Message, b interface{}) error {
 			return err
 		}
 	} else {
-		// set the body
-		body = c.buf.wbuf.Bytes()
+		// set the body, copied since the socket sends it after the
+		// buffer is reset for the next message
+		body = make([]byte, c.buf.wbuf.Len())
+		copy(body, c.buf.wbuf.Bytes())
 	}
 
 	// Set content type if theres content
@@ -12,7 +12,6 @@ import (
 	"fmt"
 	"io"
 	"reflect"
-	"runtime/debug"
 	"strings"
 	"sync"
 	"unicode"
@@ -71,6 +70,8 @@ type router struct {
 
 	// handler wrappers
 	hdlrWrappers []HandlerWrapper
+	// stream wrappers
+	streamWrappers []StreamWrapper
 	// subscriber wrappers
 	subWrappers []SubscriberWrapper
 
@@ -277,8 +278,14 @@ func (s *service) call(ctx context.Context, router *router, sending *sync.Mutex,
 	// client.Stream request
 	r.stream = true
 
+	// wrap the stream
+	var stream Stream = rawStream
+	for i := len(router.streamWrappers); i > 0; i-- {
+		stream = router.streamWrappers[i-1](stream)
+	}
+
 	// execute handler
-	return fn(ctx, r, rawStream)
+	return fn(ctx, r, stream)
 }
 
 func (m *methodType) prepareContext(ctx context.Context) reflect.Value {
@@ -505,15 +512,7 @@ func (router *router) Subscribe(s Subscriber) error {
 }
 
 func (router *router) ProcessMessage(ctx context.Context, msg Message) (err error) {
-	defer func() {
-		// recover any panics
-		if r := recover(); r != nil {
-			log.Errorf("panic recovered: %v", r)
-			log.Error(string(debug.Stack()))
-			err = merrors.InternalServerError("go.micro.server", "panic recovered: %v", r)
-		}
-	}()
-
+	// panics are recovered by the server
 	router.su.RLock()
 	// get the subscribers by topic
 	subs, ok := router.subscribers[msg.Topic()]
@@ -597,7 +596,8 @@ func (router *router) ProcessMessage(ctx context.Context, msg Message) (err erro
 			}
 
 			// execute the message handler
-			if err = fn(ctx, rpcMsg); err != nil {
+			hctx := NewSubscriberContext(ctx, sub.opts.Queue, handler.name)
+			if err = fn(hctx, rpcMsg); err != nil {
 				errResults = append(errResults, err.Error())
 			}
 		}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/micro/go-micro/v2/broker"
@@ -37,10 +38,16 @@ type rpcServer struct {
 	started bool
 	// used for first registration
 	registered bool
+	// marks the node as draining on stop
+	draining bool
 	// subscribe to service name
 	subscriber broker.Subscriber
 	// graceful exit
 	wg *sync.WaitGroup
+	// requests in flight
+	reqs Requests
+	// requests which ran over their timeout
+	timeouts Timeouts
 
 	rsvc *registry.Service
 }
@@ -48,10 +55,10 @@ type rpcServer struct {
 func newRpcServer(opts ...Option) Server {
 	options := newOptions(opts...)
 	router := newRpcRouter()
-	router.hdlrWrappers = options.HdlrWrappers
 	router.subWrappers = options.SubWrappers
+	router.streamWrappers = options.StreamWrappers
 
-	return &rpcServer{
+	s := &rpcServer{
 		opts:        options,
 		router:      router,
 		handlers:    make(map[string]Handler),
@@ -59,6 +66,30 @@ func newRpcServer(opts ...Option) Server {
 		exit:        make(chan chan error),
 		wg:          wait(options.Context),
 	}
+	router.hdlrWrappers = s.handlerWrappers()
+
+	return s
+}
+
+// handlerWrappers returns the handler wrappers with the
+// endpoint timeout applied closest to the handler
+func (s *rpcServer) handlerWrappers() []HandlerWrapper {
+	wrappers := make([]HandlerWrapper, 0, len(s.opts.HdlrWrappers)+1)
+	wrappers = append(wrappers, s.opts.HdlrWrappers...)
+	return append(wrappers, s.timeouts.Wrapper(s.opts.Name, s.timeout, s.opts.Tracer))
+}
+
+// timeout returns the max execution time of the endpoint
+func (s *rpcServer) timeout(endpoint string) time.Duration {
+	s.RLock()
+	defer s.RUnlock()
+
+	if h, ok := s.handlers[strings.Split(endpoint, ".")[0]]; ok {
+		if d, ok := h.Options().Timeouts[endpoint]; ok {
+			return d
+		}
+	}
+	return s.opts.HandlerTimeout
 }
 
 // HandleEvent handles inbound messages to the service directly
@@ -126,7 +157,51 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 		r = rpcRouter{m: handler}
 	}
 
-	return r.ProcessMessage(ctx, rpcMsg)
+	// shed the event if we're under pressure
+	release, err := Acquire(s.Options(), rpcMsg.topic, RequestPriority(msg.Header, DefaultEventPriority))
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	err = s.processMessage(ctx, r, rpcMsg)
+	release(time.Since(started), err)
+
+	return err
+}
+
+// serveRequest serves the request turning a panic into an error
+func (s *rpcServer) serveRequest(ctx context.Context, r Router, req *rpcRequest, rsp *rpcResponse) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = RecoverPanic(ctx, s.Options(), req.endpoint, p)
+		}
+	}()
+	return r.ServeRequest(ctx, req, rsp)
+}
+
+// processMessage processes the message turning a panic into an error
+func (s *rpcServer) processMessage(ctx context.Context, r Router, msg *rpcMessage) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = RecoverPanic(ctx, s.Options(), msg.topic, p)
+		}
+	}()
+	return r.ProcessMessage(ctx, msg)
+}
+
+// priority returns the priority of a request to the endpoint
+// falling back to the priority of its handler
+func (s *rpcServer) priority(hdr map[string]string, endpoint string) int {
+	var def int
+
+	s.RLock()
+	if h, ok := s.handlers[strings.Split(endpoint, ".")[0]]; ok {
+		def = h.Options().Priority
+	}
+	s.RUnlock()
+
+	return RequestPriority(hdr, def)
 }
 
 // ServeConn serves a single connection
@@ -231,6 +306,11 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 				pool.Release(psock)
 				continue
 			}
+			// drop window updates for streams which have ended
+			if socket.IsWindowUpdate(&msg) {
+				pool.Release(psock)
+				continue
+			}
 		}
 
 		// got an existing socket already
@@ -280,14 +360,26 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		ctx := metadata.NewContext(context.Background(), hdr)
 
 		// set the timeout from the header if we have it
+		var timeout time.Duration
 		if len(to) > 0 {
 			if n, err := strconv.ParseUint(to, 10, 64); err == nil {
-				var cancel context.CancelFunc
-				ctx, cancel = context.WithTimeout(ctx, time.Duration(n))
-				defer cancel()
+				timeout = time.Duration(n)
 			}
 		}
 
+		// rebuild the caller's deadline if it's sooner
+		if d := msg.Header["Micro-Deadline"]; len(d) > 0 {
+			if n, err := strconv.ParseInt(d, 10, 64); err == nil && (timeout == 0 || time.Duration(n) < timeout) {
+				timeout = time.Duration(n)
+			}
+		}
+
+		if timeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
 		// if there's no content type default it
 		if len(ct) == 0 {
 			msg.Header["Content-Type"] = DefaultContentType
@@ -319,8 +411,23 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			}
 		}
 
+		// flow control the stream if the client sent its window
+		var ssock transport.Socket = psock
+		if stream && s.opts.StreamWindow > 0 {
+			if n, err := strconv.Atoi(getHeader(socket.WindowHeader, msg.Header)); err == nil && n > 0 {
+				flow := socket.NewFlow(psock, s.opts.StreamWindow, s.opts.StreamSendTimeout, map[string]string{
+					"Micro-Stream": id,
+				})
+				flow.Open(n)
+				if err := flow.Announce(); err != nil {
+					log.Debugf("rpc: unable to send the stream window: %v", err)
+				}
+				ssock = flow
+			}
+		}
+
 		// create a new rpc codec based on the pseudo socket and codec
-		rcodec := newRpcCodec(&msg, psock, cf)
+		rcodec := newRpcCodec(&msg, ssock, cf)
 		// check the protocol as well
 		protocol := rcodec.String()
 
@@ -333,17 +440,21 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			codec:       rcodec,
 			header:      msg.Header,
 			body:        msg.Body,
-			socket:      psock,
+			socket:      ssock,
 			stream:      stream,
 		}
 
 		// internal response
 		response := &rpcResponse{
 			header: make(map[string]string),
-			socket: psock,
+			socket: ssock,
 			codec:  rcodec,
 		}
 
+		// take a slot from the limiters, if we can't the request is rejected
+		// lower priority requests are rejected first as the limit nears
+		release, limitErr := Acquire(s.Options(), request.endpoint, s.priority(msg.Header, request.endpoint))
+
 		// set router
 		r := Router(s.router)
 
@@ -355,8 +466,9 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			}
 
 			// execute the wrapper for it
-			for i := len(s.opts.HdlrWrappers); i > 0; i-- {
-				handler = s.opts.HdlrWrappers[i-1](handler)
+			wrappers := s.handlerWrappers()
+			for i := len(wrappers); i > 0; i-- {
+				handler = wrappers[i-1](handler)
 			}
 
 			// set the router
@@ -367,6 +479,15 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		// serve the request and process the outbound messages
 		wg.Add(2)
 
+		// the request is in flight until both are done
+		s.reqs.Add()
+		var running int32 = 2
+		finished := func() {
+			if atomic.AddInt32(&running, -1) == 0 {
+				s.reqs.Done()
+			}
+		}
+
 		// process the outbound messages from the socket
 		go func(id string, psock *socket.Socket) {
 			defer func() {
@@ -379,6 +500,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 				// release the socket
 				pool.Release(psock)
 				// signal we're done
+				finished()
 				wg.Done()
 
 				// recover any panics for outbound process
@@ -407,9 +529,14 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		// serve the request in a go routine as this may be a stream
 		go func(id string, psock *socket.Socket) {
 			defer func() {
+				// stop reading ahead for a flow controlled stream
+				if flow, ok := ssock.(*socket.Flow); ok {
+					flow.Close()
+				}
 				// release the socket
 				pool.Release(psock)
 				// signal we're done
+				finished()
 				wg.Done()
 
 				// recover any panics for call handler
@@ -420,10 +547,34 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			}()
 
 			// serve the actual request using the request router
-			if serveRequestError := r.ServeRequest(ctx, request, response); serveRequestError != nil {
+			// unless it was rejected by a limiter
+			serveRequestError := limitErr
+			if serveRequestError == nil {
+				started := time.Now()
+				// release the slot even if the handler panics
+				defer func() {
+					release(time.Since(started), serveRequestError)
+				}()
+				serveRequestError = s.serveRequest(ctx, r, request, response)
+			}
+
+			if serveRequestError != nil {
+				header := msg.Header
+
+				// tell the client whether to retry
+				if hdr := RetryHeader(serveRequestError); hdr != nil {
+					header = make(map[string]string, len(msg.Header)+len(hdr))
+					for k, v := range msg.Header {
+						header[k] = v
+					}
+					for k, v := range hdr {
+						header[k] = v
+					}
+				}
+
 				// write an error response
 				writeError := rcodec.Write(&codec.Message{
-					Header: msg.Header,
+					Header: header,
 					Error:  serveRequestError.Error(),
 					Type:   codec.Error,
 				}, nil)
@@ -468,9 +619,10 @@ func (s *rpcServer) Init(opts ...Option) error {
 	// update router if its the default
 	if s.opts.Router == nil {
 		r := newRpcRouter()
-		r.hdlrWrappers = s.opts.HdlrWrappers
+		r.hdlrWrappers = s.handlerWrappers()
 		r.serviceMap = s.router.serviceMap
 		r.subWrappers = s.opts.SubWrappers
+		r.streamWrappers = s.opts.StreamWrappers
 		s.router = r
 	}
 
@@ -516,6 +668,7 @@ func (s *rpcServer) Register() error {
 	s.RLock()
 	rsvc := s.rsvc
 	config := s.Options()
+	draining := s.draining
 	s.RUnlock()
 
 	regFunc := func(service *registry.Service) error {
@@ -584,6 +737,11 @@ func (s *rpcServer) Register() error {
 	// make copy of metadata
 	md := metadata.Copy(config.Metadata)
 
+	// let selectors know to skip the node
+	if draining {
+		md[registry.DrainingKey] = "true"
+	}
+
 	// mq-rpc(eg. nats) doesn't need the port. its addr is queue name.
 	if port != "" {
 		addr = mnet.HostPort(addr, port)
@@ -698,7 +856,8 @@ func (s *rpcServer) Register() error {
 			opts = append(opts, broker.DisableAutoAck())
 		}
 
-		sub, err := config.Broker.Subscribe(sb.Topic(), s.HandleEvent, opts...)
+		handler := RedeliveryHandler(config.Broker, sb.Topic(), sb.Options(), s.HandleEvent)
+		sub, err := config.Broker.Subscribe(sb.Topic(), handler, opts...)
 		if err != nil {
 			return err
 		}
@@ -800,6 +959,38 @@ func (s *rpcServer) Deregister() error {
 	return nil
 }
 
+// drain marks the node as draining in the registry so selectors skip
+// it then keeps serving for the grace period and until the requests in
+// flight are done, waiting no longer than the drain timeout
+func (s *rpcServer) drain() {
+	s.Lock()
+	config := s.opts
+	if config.DrainTimeout <= 0 || !s.registered {
+		s.Unlock()
+		return
+	}
+	s.draining = true
+	// don't register the cached service
+	s.rsvc = nil
+	s.Unlock()
+
+	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+		log.Infof("Server %s-%s draining for up to %v", config.Name, config.Id, config.DrainTimeout)
+	}
+
+	if err := s.Register(); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			log.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
+		}
+	}
+
+	// give watchers and selector caches time to see the node draining
+	grace := DrainGraceFor(config)
+	time.Sleep(grace)
+
+	s.reqs.Wait(config.DrainTimeout - grace)
+}
+
 func (s *rpcServer) Start() error {
 	s.RLock()
 	if s.started {
@@ -826,6 +1017,10 @@ func (s *rpcServer) Start() error {
 	s.opts.Address = ts.Addr()
 	s.Unlock()
 
+	// expose the limiters and timeouts in the debug stats
+	limits.register(config)
+	RegisterTimeouts(&s.timeouts)
+
 	bname := config.Broker.String()
 
 	// connect to the broker
@@ -934,6 +1129,9 @@ func (s *rpcServer) Start() error {
 			}
 		}
 
+		// keep serving while clients move away
+		s.drain()
+
 		s.RLock()
 		registered := s.registered
 		s.RUnlock()
@@ -996,8 +1194,12 @@ func (s *rpcServer) Stop() error {
 	err := <-ch
 	s.Lock()
 	s.started = false
+	s.draining = false
 	s.Unlock()
 
+	limits.deregister(s.Options())
+	DeregisterTimeouts(&s.timeouts)
+
 	return err
 }
 
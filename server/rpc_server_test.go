@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	tmemory "github.com/micro/go-micro/v2/transport/memory"
+	"github.com/micro/go-micro/v2/util/socket"
+)
+
+type StreamGreeter struct{}
+
+func (g *StreamGreeter) Echo(ctx context.Context, stream Stream) error {
+	for {
+		var msg map[string]string
+		if err := stream.Recv(&msg); err != nil {
+			return err
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// Flooder sends to the stream until it fails reporting how many were sent
+type Flooder struct {
+	sent chan int
+	errs chan error
+}
+
+func (f *Flooder) Flood(ctx context.Context, stream Stream) error {
+	var msg map[string]string
+	if err := stream.Recv(&msg); err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		// the stream keeps the send error
+		stream.Send(msg)
+		if err := stream.Error(); err != nil {
+			f.sent <- i
+			f.errs <- err
+			return err
+		}
+	}
+}
+
+// Stopper never reads from the stream until it's released
+type Stopper struct {
+	release chan struct{}
+}
+
+func (s *Stopper) Stop(ctx context.Context, stream Stream) error {
+	<-s.release
+	return nil
+}
+
+func TestRPCServerStreamWindow(t *testing.T) {
+	r := rmemory.NewRegistry()
+	tr := tmemory.NewTransport()
+
+	f := &Flooder{sent: make(chan int, 1), errs: make(chan error, 1)}
+
+	s := NewServer(
+		Name("foo"),
+		Registry(r),
+		Transport(tr),
+		Broker(bmemory.NewBroker()),
+		StreamWindow(4),
+		StreamSendTimeout(time.Millisecond*50),
+	)
+	if err := s.Handle(s.NewHandler(f)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	c := client.NewClient(
+		client.Registry(r),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.StreamWindow(4),
+	)
+
+	stream, err := c.Stream(context.TODO(), c.NewRequest("foo", "Flooder.Flood", map[string]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if err := stream.Send(map[string]string{"hello": "world"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server can only send as much as the client buffers
+	select {
+	case n := <-f.sent:
+		if n != 4 {
+			t.Fatalf("Expected the server to send 4 messages got %d", n)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Expected the server to be held back")
+	}
+	if err := <-f.errs; err != socket.ErrWindowTimeout {
+		t.Fatalf("Expected %v got %v", socket.ErrWindowTimeout, err)
+	}
+
+	// what was sent can still be read
+	for i := 0; i < 4; i++ {
+		var rsp map[string]string
+		if err := stream.Recv(&rsp); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRPCServerStreamNoRead(t *testing.T) {
+	r := rmemory.NewRegistry()
+	tr := tmemory.NewTransport()
+
+	stopper := &Stopper{release: make(chan struct{})}
+	defer close(stopper.release)
+
+	s := NewServer(
+		Name("foo"),
+		Registry(r),
+		Transport(tr),
+		Broker(bmemory.NewBroker()),
+		StreamWindow(4),
+	)
+	if err := s.Handle(s.NewHandler(stopper)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	c := client.NewClient(
+		client.Registry(r),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.StreamWindow(4),
+	)
+
+	stream, err := c.Stream(
+		context.TODO(),
+		c.NewRequest("foo", "Stopper.Stop", map[string]string{}),
+		client.WithStreamSendTimeout(time.Millisecond*50),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	// the client is held back once the server's window is full
+	timeout := time.After(time.Second * 5)
+	for {
+		err := stream.Send(map[string]string{"hello": "world"})
+		if err == nil {
+			select {
+			case <-timeout:
+				t.Fatal("Expected the client to be held back")
+			default:
+			}
+			continue
+		}
+		if errors.FromError(err).Detail != socket.ErrWindowTimeout.Error() {
+			t.Fatalf("Expected %v got %v", socket.ErrWindowTimeout, err)
+		}
+		break
+	}
+}
+
+func TestRPCServerStreamWrappers(t *testing.T) {
+	r := rmemory.NewRegistry()
+	tr := tmemory.NewTransport()
+
+	var serverSent, serverRecv, clientSent, clientRecv int32
+
+	serverHook := func(n *int32) StreamHook {
+		return func(_ Stream, _ interface{}, err error) {
+			if err == nil {
+				atomic.AddInt32(n, 1)
+			}
+		}
+	}
+	clientHook := func(n *int32) client.StreamHook {
+		return func(_ client.Stream, _ interface{}, err error) {
+			if err == nil {
+				atomic.AddInt32(n, 1)
+			}
+		}
+	}
+
+	s := NewServer(
+		Name("foo"),
+		Registry(r),
+		Transport(tr),
+		Broker(bmemory.NewBroker()),
+		WrapStream(HookStream(serverHook(&serverSent), serverHook(&serverRecv))),
+	)
+	if err := s.Handle(s.NewHandler(&StreamGreeter{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	c := client.NewClient(
+		client.Registry(r),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.WrapStream(client.HookStream(clientHook(&clientSent), clientHook(&clientRecv))),
+	)
+
+	stream, err := c.Stream(context.TODO(), c.NewRequest("foo", "StreamGreeter.Echo", map[string]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(map[string]string{"hello": "world"}); err != nil {
+			t.Fatal(err)
+		}
+		var rsp map[string]string
+		if err := stream.Recv(&rsp); err != nil {
+			t.Fatal(err)
+		}
+		if rsp["hello"] != "world" {
+			t.Fatalf("Unexpected response %v", rsp)
+		}
+	}
+	stream.Close()
+
+	for name, n := range map[string]*int32{
+		"server sent":     &serverSent,
+		"server received": &serverRecv,
+		"client sent":     &clientSent,
+		"client received": &clientRecv,
+	} {
+		if v := atomic.LoadInt32(n); v != 3 {
+			t.Fatalf("Expected 3 messages %s got %d", name, v)
+		}
+	}
+}
+
+// Blocker holds requests until it's released
+type Blocker struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *Blocker) Block(ctx context.Context, req *struct{}, rsp *struct{}) error {
+	close(b.started)
+	<-b.release
+	return nil
+}
+
+func TestRPCServerDrain(t *testing.T) {
+	r := rmemory.NewRegistry()
+	b := bmemory.NewBroker()
+	tr := tmemory.NewTransport()
+
+	s := NewServer(
+		Name("foo"),
+		Registry(r),
+		Broker(b),
+		Transport(tr),
+		DrainTimeout(time.Second*10),
+		DrainGrace(time.Millisecond*100),
+	)
+
+	var received int32
+	sub := func(ctx context.Context, msg *struct{}) error {
+		atomic.AddInt32(&received, 1)
+		return nil
+	}
+	if err := s.Subscribe(s.NewSubscriber("foo.events", sub)); err != nil {
+		t.Fatal(err)
+	}
+	blocker := &Blocker{started: make(chan struct{}), release: make(chan struct{})}
+	if err := s.Handle(s.NewHandler(blocker)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	node := func() *registry.Node {
+		services, err := r.GetService("foo")
+		if err != nil || len(services) == 0 || len(services[0].Nodes) == 0 {
+			return nil
+		}
+		return services[0].Nodes[0]
+	}
+
+	if n := node(); n == nil || n.Metadata[registry.DrainingKey] == "true" {
+		t.Fatalf("Expected the node to be registered and not draining got %v", n)
+	}
+
+	// a request is in flight when the server stops
+	c := client.NewClient(
+		client.Registry(r),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+	)
+	called := make(chan error, 1)
+	go func() {
+		called <- c.Call(context.TODO(), c.NewRequest("foo", "Blocker.Block", map[string]string{}), &map[string]string{})
+	}()
+	<-blocker.started
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- s.Stop()
+	}()
+
+	// marked as draining while still serving
+	for i := 0; i < 100; i++ {
+		if n := node(); n != nil && n.Metadata[registry.DrainingKey] == "true" {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := node(); n == nil || n.Metadata[registry.DrainingKey] != "true" {
+		t.Fatalf("Expected the node to be draining got %v", n)
+	}
+	if err := b.Publish("foo.events", &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json", "Micro-Topic": "foo.events"},
+		Body:   []byte("{}"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&received) != 1 {
+		t.Fatal("Expected the subscriber to receive events while draining")
+	}
+
+	// the drain is over once the request is done
+	close(blocker.release)
+	if err := <-called; err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Expected the server to stop once the requests in flight were done")
+	}
+	if n := node(); n != nil {
+		t.Fatalf("Expected the node to be deregistered got %v", n)
+	}
+}
+
+func TestRPCServerDrainGrace(t *testing.T) {
+	s := NewServer(
+		Name("foo"),
+		Registry(rmemory.NewRegistry()),
+		Broker(bmemory.NewBroker()),
+		Transport(tmemory.NewTransport()),
+		DrainTimeout(time.Second*10),
+		DrainGrace(time.Millisecond*200),
+	)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// no requests in flight but selectors still need to see the node draining
+	start := time.Now()
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < time.Millisecond*200 || d > time.Second*5 {
+		t.Fatalf("Expected the drain to last the grace period got %v", d)
+	}
+}
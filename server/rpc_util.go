@@ -2,6 +2,7 @@ package server
 
 import (
 	"sync"
+	"time"
 )
 
 // waitgroup for global management of connections
@@ -30,3 +31,65 @@ func (w *waitGroup) Wait() {
 	// only wait on local group
 	w.lg.Wait()
 }
+
+// Requests counts the requests in flight so a drain can finish as
+// soon as they're served. The zero value is ready to use.
+type Requests struct {
+	sync.Mutex
+	n int
+	// closed when the last request in flight is done
+	idle chan struct{}
+}
+
+// Add counts a request in flight
+func (r *Requests) Add() {
+	r.Lock()
+	if r.n == 0 {
+		r.idle = make(chan struct{})
+	}
+	r.n++
+	r.Unlock()
+}
+
+// Done marks a request in flight as served
+func (r *Requests) Done() {
+	r.Lock()
+	r.n--
+	if r.n == 0 {
+		close(r.idle)
+	}
+	r.Unlock()
+}
+
+// Wait blocks until there are no requests in flight or the timeout passes
+func (r *Requests) Wait(timeout time.Duration) {
+	r.Lock()
+	if r.n == 0 {
+		r.Unlock()
+		return
+	}
+	idle := r.idle
+	r.Unlock()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-idle:
+	case <-t.C:
+	}
+}
+
+// DrainGraceFor returns how long to keep serving after the node is marked
+// as draining before waiting on the requests in flight. It's capped by the
+// drain timeout.
+func DrainGraceFor(opts Options) time.Duration {
+	grace := opts.DrainGrace
+	if grace <= 0 {
+		grace = DefaultDrainGrace
+	}
+	if grace > opts.DrainTimeout {
+		grace = opts.DrainTimeout
+	}
+	return grace
+}
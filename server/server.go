@@ -147,6 +147,7 @@ var (
 	DefaultRegisterCheck           = func(context.Context) error { return nil }
 	DefaultRegisterInterval        = time.Second * 30
 	DefaultRegisterTTL             = time.Second * 90
+	DefaultDrainGrace              = time.Second * 5
 
 	// NewServer creates a new server
 	NewServer func(...Option) Server = newRpcServer
@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 
 	"github.com/micro/go-micro/v2/registry"
 )
@@ -12,6 +13,8 @@ const (
 )
 
 type handler struct {
+	// name identifies the handler of the subscriber
+	name    string
 	method  reflect.Value
 	reqType reflect.Type
 	ctxType reflect.Type
@@ -41,6 +44,7 @@ func newSubscriber(topic string, sub interface{}, opts ...SubscriberOption) Subs
 
 	if typ := reflect.TypeOf(sub); typ.Kind() == reflect.Func {
 		h := &handler{
+			name:   runtime.FuncForPC(reflect.ValueOf(sub).Pointer()).Name(),
 			method: reflect.ValueOf(sub),
 		}
 
@@ -69,6 +73,7 @@ func newSubscriber(topic string, sub interface{}, opts ...SubscriberOption) Subs
 		for m := 0; m < typ.NumMethod(); m++ {
 			method := typ.Method(m)
 			h := &handler{
+				name:   name + "." + method.Name,
 				method: method.Func,
 			}
 
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/trace"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
+)
+
+var (
+	// the timeouts of the running servers
+	timeouts = &timeoutRegistry{
+		servers: make(map[*Timeouts]bool),
+	}
+)
+
+// TimeoutStats are the requests which ran over an endpoint's timeout
+type TimeoutStats struct {
+	// Timeout is the max execution time of the endpoint
+	Timeout time.Duration
+	// Exceeded is the number of requests which ran over
+	Exceeded uint64
+	// Longest is the longest a request ran for
+	Longest time.Duration
+}
+
+// HandlerTimeout sets the max execution time of the endpoints
+// which don't set their own through EndpointTimeout
+func HandlerTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.HandlerTimeout = d
+	}
+}
+
+// EndpointTimeout sets the max execution time of an endpoint e.g Greeter.Hello.
+// The request context is cancelled once it passes and a timeout error is
+// returned straight away while the handler finishes in the background.
+func EndpointTimeout(name string, d time.Duration) HandlerOption {
+	return func(o *HandlerOptions) {
+		if o.Timeouts == nil {
+			o.Timeouts = make(map[string]time.Duration)
+		}
+		o.Timeouts[name] = d
+	}
+}
+
+// Timeouts records the requests to a server which ran over their timeout.
+// The zero value is ready to use.
+type Timeouts struct {
+	sync.RWMutex
+	endpoints map[string]*TimeoutStats
+}
+
+// Read returns the requests which ran over their timeout keyed by endpoint
+func (t *Timeouts) Read() map[string]TimeoutStats {
+	t.RLock()
+	defer t.RUnlock()
+
+	stats := make(map[string]TimeoutStats, len(t.endpoints))
+	for endpoint, s := range t.endpoints {
+		stats[endpoint] = *s
+	}
+	return stats
+}
+
+// Wrapper returns a handler wrapper which cancels the request context once
+// the timeout of the endpoint passes and returns a timeout error without
+// waiting for the handler. Streams are long lived so they aren't bound.
+// Handlers which run over are traced with the tracer, if not nil, until
+// they return.
+func (t *Timeouts) Wrapper(id string, timeout func(endpoint string) time.Duration, tracer trace.Tracer) HandlerWrapper {
+	return func(h HandlerFunc) HandlerFunc {
+		return withTimeout(id, timeout, tracer, t, h)
+	}
+}
+
+// exceeded counts a request which ran over the timeout
+func (t *Timeouts) exceeded(endpoint string, timeout time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.endpoints == nil {
+		t.endpoints = make(map[string]*TimeoutStats)
+	}
+	s, ok := t.endpoints[endpoint]
+	if !ok {
+		s = new(TimeoutStats)
+		t.endpoints[endpoint] = s
+	}
+	s.Timeout = timeout
+	s.Exceeded++
+}
+
+// took records how long a request which ran over the timeout took
+func (t *Timeouts) took(endpoint string, d time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+
+	if s, ok := t.endpoints[endpoint]; ok && d > s.Longest {
+		s.Longest = d
+	}
+}
+
+// RegisterTimeouts exposes the timeouts of a server starting up in ReadTimeouts
+func RegisterTimeouts(t *Timeouts) {
+	timeouts.Lock()
+	timeouts.servers[t] = true
+	timeouts.Unlock()
+}
+
+// DeregisterTimeouts removes the timeouts of a server which stopped
+func DeregisterTimeouts(t *Timeouts) {
+	timeouts.Lock()
+	delete(timeouts.servers, t)
+	timeouts.Unlock()
+}
+
+// ReadTimeouts returns the requests to the running servers
+// which ran over their timeout keyed by endpoint
+func ReadTimeouts() map[string]TimeoutStats {
+	timeouts.RLock()
+	defer timeouts.RUnlock()
+
+	stats := make(map[string]TimeoutStats)
+	for t := range timeouts.servers {
+		for endpoint, s := range t.Read() {
+			if v, ok := stats[endpoint]; ok {
+				s.Exceeded += v.Exceeded
+				if v.Longest > s.Longest {
+					s.Longest = v.Longest
+				}
+			}
+			stats[endpoint] = s
+		}
+	}
+	return stats
+}
+
+// timeoutRegistry tracks the timeouts of the running servers
+type timeoutRegistry struct {
+	sync.RWMutex
+	servers map[*Timeouts]bool
+}
+
+// handlerResponse returns a response for the handler to write to and a func
+// copying it to rsp. A handler which runs over its timeout then can't write
+// to the response while it's sent. Responses written by the router, rather
+// than set on a reply value, are handed over as is.
+func handlerResponse(rsp interface{}) (interface{}, func()) {
+	if _, ok := rsp.(Response); ok {
+		return rsp, func() {}
+	}
+	v := reflect.ValueOf(rsp)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() {
+		return rsp, func() {}
+	}
+	hv := reflect.New(v.Type().Elem())
+	hv.Elem().Set(v.Elem())
+	return hv.Interface(), func() {
+		v.Elem().Set(hv.Elem())
+	}
+}
+
+// withTimeout returns a handler which cancels the request context once the
+// timeout passes and returns a timeout error if the handler runs over it
+func withTimeout(id string, timeout func(endpoint string) time.Duration, tracer trace.Tracer, t *Timeouts, h HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req Request, rsp interface{}) error {
+		d := timeout(req.Endpoint())
+		// streams are long lived so only bound requests
+		if d <= 0 || req.Stream() {
+			return h(ctx, req, rsp)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, d)
+
+		type result struct {
+			err error
+			p   *Panic
+		}
+
+		started := time.Now()
+		ch := make(chan result, 1)
+		hrsp, done := handlerResponse(rsp)
+
+		go func() {
+			defer cancel()
+			defer func() {
+				// keep the stack of the handler for the crash record
+				if r := recover(); r != nil {
+					ch <- result{p: &Panic{Value: r, Stack: debug.Stack()}}
+				}
+			}()
+			ch <- result{err: h(ctx, req, hrsp)}
+		}()
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case r := <-ch:
+			// hand the panic to the server to recover
+			if r.p != nil {
+				panic(r.p)
+			}
+			done()
+			return r.err
+		case <-timer.C:
+		}
+
+		t.exceeded(req.Endpoint(), d)
+
+		// trace the handler for as long as it runs over
+		var span *trace.Span
+		if tracer != nil {
+			_, span = tracer.Start(ctx, req.Service()+"."+req.Endpoint())
+			span.Type = trace.SpanTypeRequestInbound
+			span.Started = started
+			span.Metadata["timeout"] = d.String()
+		}
+
+		// record how long the handler ran for once it returns
+		go func() {
+			r := <-ch
+			t.took(req.Endpoint(), time.Since(started))
+			if span != nil {
+				if r.err != nil {
+					span.Metadata["error"] = r.err.Error()
+				}
+				if r.p != nil {
+					span.Metadata["panic"] = fmt.Sprintf("%v", r.p.Value)
+				}
+				tracer.Finish(span)
+			}
+			if r.p != nil && logger.V(logger.ErrorLevel, log) {
+				log.Errorf("panic recovered after %s exceeded its timeout: %v", req.Endpoint(), r.p.Value)
+				log.Error(string(r.p.Stack))
+			}
+		}()
+
+		return errors.Timeout(id, "%s exceeded its timeout of %v", req.Endpoint(), d)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tmemory "github.com/micro/go-micro/v2/debug/trace/memory"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+type TimeoutGreeter struct{}
+
+func (t *TimeoutGreeter) Hello(ctx context.Context, req *struct{}, rsp *struct{}) error {
+	return nil
+}
+
+func TestTimeout(t *testing.T) {
+	s := newRpcServer(HandlerTimeout(time.Second)).(*rpcServer)
+	h := s.NewHandler(&TimeoutGreeter{}, EndpointTimeout("TimeoutGreeter.Hello", time.Millisecond*50))
+	if err := s.Handle(h); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := s.timeout("TimeoutGreeter.Hello"); d != time.Millisecond*50 {
+		t.Fatalf("Expected the endpoint timeout got %v", d)
+	}
+	if d := s.timeout("Other.Hello"); d != time.Second {
+		t.Fatalf("Expected the server timeout got %v", d)
+	}
+
+	slow := func(ctx context.Context, req Request, rsp interface{}) error {
+		// the context is cancelled when the timeout passes
+		<-ctx.Done()
+		return nil
+	}
+
+	fn := s.timeouts.Wrapper("foo", s.timeout, nil)(slow)
+	req := &rpcRequest{service: "foo", endpoint: "TimeoutGreeter.Hello"}
+
+	err := fn(context.TODO(), req, nil)
+	if e := errors.FromError(err); e.Code != 408 || e.Id != "foo" {
+		t.Fatalf("Expected a timeout error got %v", err)
+	}
+
+	stats, ok := s.timeouts.Read()["TimeoutGreeter.Hello"]
+	if !ok || stats.Exceeded != 1 || stats.Timeout != time.Millisecond*50 {
+		t.Fatalf("Unexpected timeout stats %+v", stats)
+	}
+
+	// the timeout error doesn't wait for handlers which ignore the context
+	release := make(chan struct{})
+	done := make(chan struct{})
+	stuck := func(ctx context.Context, req Request, rsp interface{}) error {
+		defer close(done)
+		<-release
+		return nil
+	}
+
+	started := time.Now()
+	err = s.timeouts.Wrapper("foo", s.timeout, nil)(stuck)(context.TODO(), req, nil)
+	if e := errors.FromError(err); e.Code != 408 || time.Since(started) > time.Second {
+		t.Fatalf("Expected a timeout error once the timeout passed got %v after %v", err, time.Since(started))
+	}
+	close(release)
+	<-done
+
+	// fast requests are untouched
+	fast := func(ctx context.Context, req Request, rsp interface{}) error {
+		return errors.BadRequest("foo", "bad")
+	}
+	if err := s.timeouts.Wrapper("foo", s.timeout, nil)(fast)(context.TODO(), req, nil); errors.FromError(err).Code != 400 {
+		t.Fatalf("Expected the handler error got %v", err)
+	}
+}
+
+func TestTimeoutResponse(t *testing.T) {
+	s := newRpcServer(HandlerTimeout(time.Millisecond * 50)).(*rpcServer)
+	tracer := tmemory.NewTracer()
+	req := &rpcRequest{service: "foo", endpoint: "TimeoutGreeter.Hello"}
+
+	// the response of a handler done in time is set
+	fast := func(ctx context.Context, req Request, rsp interface{}) error {
+		*rsp.(*string) = "fast"
+		return nil
+	}
+	var rsp string
+	if err := s.timeouts.Wrapper("foo", s.timeout, tracer)(fast)(context.TODO(), req, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp != "fast" {
+		t.Fatalf("Expected the handler response got %q", rsp)
+	}
+
+	// a handler running over doesn't write to the response being sent
+	release := make(chan struct{})
+	done := make(chan struct{})
+	stuck := func(ctx context.Context, req Request, rsp interface{}) error {
+		defer close(done)
+		<-release
+		*rsp.(*string) = "late"
+		return nil
+	}
+	rsp = ""
+	err := s.timeouts.Wrapper("foo", s.timeout, tracer)(stuck)(context.TODO(), req, &rsp)
+	if e := errors.FromError(err); e.Code != 408 {
+		t.Fatalf("Expected a timeout error got %v", err)
+	}
+	close(release)
+	<-done
+	if rsp != "" {
+		t.Fatalf("Expected the response to be untouched got %q", rsp)
+	}
+
+	// the handler which ran over is traced once it returns
+	for i := 0; ; i++ {
+		spans, err := tracer.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(spans) == 1 && spans[0].Metadata["timeout"] == "50ms" {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("Expected the timeout to be traced got %v", spans)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
@@ -25,3 +25,37 @@ type SubscriberWrapper func(SubscriberFunc) SubscriberFunc
 // is a convenient way to wrap a Stream as its in use for trace, monitoring,
 // metrics, etc.
 type StreamWrapper func(Stream) Stream
+
+// StreamHook is called with every message sent or received on a stream
+// and the error of the operation
+type StreamHook func(s Stream, msg interface{}, err error)
+
+type hookStream struct {
+	Stream
+	send StreamHook
+	recv StreamHook
+}
+
+func (h *hookStream) Send(msg interface{}) error {
+	err := h.Stream.Send(msg)
+	if h.send != nil {
+		h.send(h.Stream, msg, err)
+	}
+	return err
+}
+
+func (h *hookStream) Recv(msg interface{}) error {
+	err := h.Stream.Recv(msg)
+	if h.recv != nil {
+		h.recv(h.Stream, msg, err)
+	}
+	return err
+}
+
+// HookStream returns a StreamWrapper which calls the hooks
+// on every Send and Recv. Either hook may be nil.
+func HookStream(send, recv StreamHook) StreamWrapper {
+	return func(s Stream) Stream {
+		return &hookStream{Stream: s, send: send, recv: recv}
+	}
+}
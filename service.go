@@ -9,6 +9,7 @@ import (
 
 	"github.com/micro/go-micro/v2/auth"
 	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/client/selector"
 	"github.com/micro/go-micro/v2/config/cmd"
 	"github.com/micro/go-micro/v2/debug/service/handler"
 	"github.com/micro/go-micro/v2/debug/stats"
@@ -36,7 +37,7 @@ func newService(opts ...Option) Service {
 
 	// we pass functions to the wrappers since the values can change during initialisation
 	authFn := func() auth.Auth { return options.Server.Options().Auth }
-	cacheFn := func() *client.Cache { return options.Client.Options().Cache }
+	cacheFn := func() client.Cache { return options.Client.Options().Cache }
 
 	// wrap client to inject From-Service header on any calls
 	options.Client = wrapper.FromService(serviceName, options.Client)
@@ -113,6 +114,12 @@ func (s *service) Init(opts ...Option) {
 		// Explicitly set the table name to the service name
 		name := s.opts.Cmd.App().Name
 		s.opts.Store.Init(store.Table(name))
+
+		// Prefer nodes in the same zone and region as the server
+		md := s.opts.Server.Options().Metadata
+		if region, zone := md[selector.RegionKey], md[selector.ZoneKey]; len(region) > 0 || len(zone) > 0 {
+			s.opts.Client.Options().Selector.Init(selector.Topology(region, zone))
+		}
 	})
 }
 
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/micro/go-micro/v2/store"
@@ -30,6 +31,8 @@ func NewStore(opts ...store.Option) store.Store {
 type memoryStore struct {
 	options store.Options
 
+	// held to write several records atomically
+	sync.RWMutex
 	store *cache.Cache
 }
 
@@ -172,6 +175,9 @@ func (m *memoryStore) Read(key string, opts ...store.ReadOption) ([]*store.Recor
 
 	prefix := m.prefix(readOpts.Database, readOpts.Table)
 
+	m.RLock()
+	defer m.RUnlock()
+
 	var keys []string
 
 	// Handle Prefix / suffix
@@ -212,9 +218,33 @@ func (m *memoryStore) Write(r *store.Record, opts ...store.WriteOption) error {
 		o(&writeOpts)
 	}
 
+	m.Lock()
+	defer m.Unlock()
+
+	m.write(r, writeOpts)
+	return nil
+}
+
+// WriteAll writes the records atomically, they're all read or none are
+func (m *memoryStore) WriteAll(rs []*store.Record, opts ...store.WriteOption) error {
+	writeOpts := store.WriteOptions{}
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	for _, r := range rs {
+		m.write(r, writeOpts)
+	}
+	return nil
+}
+
+func (m *memoryStore) write(r *store.Record, writeOpts store.WriteOptions) {
 	prefix := m.prefix(writeOpts.Database, writeOpts.Table)
 
-	if len(opts) > 0 {
+	if !writeOpts.Expiry.IsZero() || writeOpts.TTL != 0 {
 		// Copy the record before applying options, or the incoming record will be mutated
 		newRecord := store.Record{}
 		newRecord.Key = r.Key
@@ -235,13 +265,11 @@ func (m *memoryStore) Write(r *store.Record, opts ...store.WriteOption) error {
 		}
 
 		m.set(prefix, &newRecord)
-		return nil
+		return
 	}
 
 	// set
 	m.set(prefix, r)
-
-	return nil
 }
 
 func (m *memoryStore) Delete(key string, opts ...store.DeleteOption) error {
@@ -251,6 +279,10 @@ func (m *memoryStore) Delete(key string, opts ...store.DeleteOption) error {
 	}
 
 	prefix := m.prefix(deleteOptions.Database, deleteOptions.Table)
+
+	m.Lock()
+	defer m.Unlock()
+
 	m.delete(prefix, key)
 	return nil
 }
@@ -267,7 +299,10 @@ func (m *memoryStore) List(opts ...store.ListOption) ([]string, error) {
 	}
 
 	prefix := m.prefix(listOptions.Database, listOptions.Table)
+
+	m.RLock()
 	keys := m.list(prefix, listOptions.Limit, listOptions.Offset)
+	m.RUnlock()
 
 	if len(listOptions.Prefix) > 0 {
 		var prefixKeys []string
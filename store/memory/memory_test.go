@@ -42,6 +42,42 @@ func TestMemoryNamespacePrefix(t *testing.T) {
 	basictest(s, t)
 }
 
+func TestMemoryWriteAll(t *testing.T) {
+	s := NewStore()
+	ts := s.(store.Transactional)
+
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			v := []byte(fmt.Sprintf("%d", i))
+			if err := ts.WriteAll([]*store.Record{
+				{Key: "all/a", Value: v},
+				{Key: "all/b", Value: v},
+			}, store.WriteTo("db", "table")); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	// the records are never seen half written
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		recs, err := s.Read("all/", store.ReadPrefix(), store.ReadFrom("db", "table"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(recs) == 2 && string(recs[0].Value) != string(recs[1].Value) {
+			t.Fatalf("Expected the records to be written together got %s and %s", recs[0].Value, recs[1].Value)
+		}
+	}
+}
+
 func basictest(s store.Store, t *testing.T) {
 	if len(os.Getenv("IN_TRAVIS_CI")) == 0 {
 		t.Logf("Testing store %s, with options %# v\n", s.String(), pretty.Formatter(s.Options()))
@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/store"
+)
+
+type Options struct {
+	// Store the records and pending messages are written to
+	Store store.Store
+	// Broker the pending messages are published through
+	Broker broker.Broker
+	// Prefix of the keys of the pending messages
+	Prefix string
+	// Interval between the runs of the relay
+	Interval time.Duration
+	// Tables the relay looks for pending messages in besides the
+	// default and the ones written to since the outbox was created.
+	// Tables written to by other processes are only relayed if listed.
+	Tables []Table
+}
+
+// Table is a database and table of the store
+type Table struct {
+	Database string
+	Table    string
+}
+
+type Option func(o *Options)
+
+// Store sets the store written to, it must implement store.Transactional
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Broker sets the broker pending messages are published through
+func Broker(b broker.Broker) Option {
+	return func(o *Options) {
+		o.Broker = b
+	}
+}
+
+// Prefix sets the key prefix of the pending messages
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// RelayFrom adds a database and table the relay looks for pending messages
+// in, e.g to publish the messages written there before a restart. Tables
+// written to by another process, or by this one before it restarted, are
+// only relayed if added.
+func RelayFrom(database, table string) Option {
+	return func(o *Options) {
+		o.Tables = append(o.Tables, Table{Database: database, Table: table})
+	}
+}
+
+// Interval sets how often the relay looks for pending messages
+func Interval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
@@ -0,0 +1,269 @@
+// Package outbox writes records and the messages announcing them in one store
+// transaction, then relays the messages to the broker
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/store"
+)
+
+var (
+	// IdempotencyKeyHeader carries the key of a message, the same on every
+	// delivery of it. It's also set as the Micro-Id of the message.
+	IdempotencyKeyHeader = "Micro-Idempotency-Key"
+
+	DefaultPrefix   = "outbox/"
+	DefaultInterval = time.Second
+
+	// PoisonPrefix is prepended to the keys of pending messages which
+	// can't be read. They're moved aside so the ones after them are sent.
+	PoisonPrefix = "poison/"
+
+	// ErrNotTransactional is returned by stores which can't write atomically
+	ErrNotTransactional = errors.New("store does not support transactions")
+)
+
+// Outbox writes records along with pending messages and relays the
+// messages to the broker. Delivery is at least once, a message is
+// published again if the relay stops before it's marked sent.
+type Outbox struct {
+	opts Options
+
+	sync.Mutex
+	running bool
+	exit    chan bool
+	done    chan bool
+
+	// the tables pending messages are written to
+	tmtx   sync.RWMutex
+	tables map[Table]bool
+}
+
+// pending is a message waiting in the store to be published
+type pending struct {
+	Id     string            `json:"id"`
+	Topic  string            `json:"topic"`
+	Header map[string]string `json:"header"`
+	Body   []byte            `json:"body"`
+}
+
+// NewOutbox returns an outbox writing to the store
+func NewOutbox(opts ...Option) *Outbox {
+	options := Options{
+		Store:    store.DefaultStore,
+		Broker:   broker.DefaultBroker,
+		Prefix:   DefaultPrefix,
+		Interval: DefaultInterval,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	tables := map[Table]bool{{}: true}
+	for _, t := range options.Tables {
+		tables[t] = true
+	}
+
+	return &Outbox{
+		opts:   options,
+		tables: tables,
+	}
+}
+
+// Options returns the options of the outbox
+func (o *Outbox) Options() Options {
+	return o.opts
+}
+
+// Write writes the record and the message to publish on the topic in one
+// transaction. The message is published by the relay once committed. It's
+// written to the database and table the options target along with the
+// record, the expiry only applies to the record.
+func (o *Outbox) Write(r *store.Record, topic string, m *broker.Message, opts ...store.WriteOption) error {
+	ts, ok := o.opts.Store.(store.Transactional)
+	if !ok {
+		return ErrNotTransactional
+	}
+
+	p := &pending{
+		Id:     uuid.New().String(),
+		Topic:  topic,
+		Header: m.Header,
+		Body:   m.Body,
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	// keys sort in the order the messages were written
+	key := fmt.Sprintf("%s%020d-%s", o.opts.Prefix, time.Now().UnixNano(), p.Id)
+
+	var options store.WriteOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// the message is kept until it's sent
+	rec := *r
+	if !options.Expiry.IsZero() {
+		rec.Expiry = time.Until(options.Expiry)
+	}
+	if options.TTL != 0 {
+		rec.Expiry = options.TTL
+	}
+
+	if err := ts.WriteAll([]*store.Record{&rec, {Key: key, Value: b}}, store.WriteTo(options.Database, options.Table)); err != nil {
+		return err
+	}
+
+	t := Table{Database: options.Database, Table: options.Table}
+
+	o.tmtx.Lock()
+	o.tables[t] = true
+	o.tmtx.Unlock()
+
+	return nil
+}
+
+// Relay publishes the pending messages in the order they were written and
+// marks them sent. It stops at the first message which fails to publish.
+// Messages which can't be read are moved under the PoisonPrefix.
+func (o *Outbox) Relay() error {
+	type record struct {
+		*store.Record
+		table Table
+	}
+
+	o.tmtx.RLock()
+	tables := make([]Table, 0, len(o.tables))
+	for t := range o.tables {
+		tables = append(tables, t)
+	}
+	o.tmtx.RUnlock()
+
+	var recs []record
+	for _, t := range tables {
+		rs, err := o.opts.Store.Read(o.opts.Prefix, store.ReadPrefix(), store.ReadFrom(t.Database, t.Table))
+		if err != nil && err != store.ErrNotFound {
+			return err
+		}
+		for _, r := range rs {
+			recs = append(recs, record{r, t})
+		}
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].Key < recs[j].Key
+	})
+
+	for _, r := range recs {
+		var p pending
+		if err := json.Unmarshal(r.Value, &p); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Outbox message %s can't be read, moving it to %s: %v", r.Key, PoisonPrefix+r.Key, err)
+			}
+			if err := o.poison(r.Record, r.table); err != nil {
+				return err
+			}
+			continue
+		}
+
+		hdr := make(map[string]string, len(p.Header)+2)
+		for k, v := range p.Header {
+			hdr[k] = v
+		}
+		hdr["Micro-Id"] = p.Id
+		hdr[IdempotencyKeyHeader] = p.Id
+
+		if err := o.opts.Broker.Publish(p.Topic, &broker.Message{
+			Header: hdr,
+			Body:   p.Body,
+		}); err != nil {
+			return err
+		}
+
+		if err := o.opts.Store.Delete(r.Key, store.DeleteFrom(r.table.Database, r.table.Table)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// poison moves a pending message which can't be read out of the outbox
+func (o *Outbox) poison(r *store.Record, t Table) error {
+	if err := o.opts.Store.Write(&store.Record{
+		Key:   PoisonPrefix + r.Key,
+		Value: r.Value,
+	}, store.WriteTo(t.Database, t.Table)); err != nil {
+		return err
+	}
+	return o.opts.Store.Delete(r.Key, store.DeleteFrom(t.Database, t.Table))
+}
+
+// Start runs the relay every interval until stopped
+func (o *Outbox) Start() error {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.running {
+		return nil
+	}
+
+	o.running = true
+	o.exit = make(chan bool)
+	o.done = make(chan bool)
+
+	go o.run(o.exit, o.done)
+
+	return nil
+}
+
+func (o *Outbox) run(exit, done chan bool) {
+	defer close(done)
+
+	t := time.NewTicker(o.opts.Interval)
+	defer t.Stop()
+
+	for {
+		if err := o.Relay(); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Outbox relay error: %v", err)
+			}
+		}
+
+		select {
+		case <-exit:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Stop stops the relay, waiting for a running pass to finish
+func (o *Outbox) Stop() error {
+	o.Lock()
+	defer o.Unlock()
+
+	if !o.running {
+		return nil
+	}
+
+	close(o.exit)
+	<-o.done
+	o.running = false
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/store"
+	smemory "github.com/micro/go-micro/v2/store/memory"
+)
+
+// failingBroker fails to publish while down
+type failingBroker struct {
+	broker.Broker
+	down bool
+}
+
+func (b *failingBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	if b.down {
+		return errors.New("broker down")
+	}
+	return b.Broker.Publish(topic, m, opts...)
+}
+
+func TestOutbox(t *testing.T) {
+	b := &failingBroker{Broker: bmemory.NewBroker(), down: true}
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	s := smemory.NewStore()
+	o := NewOutbox(Store(s), Broker(b))
+
+	for _, k := range []string{"foo", "bar"} {
+		if err := o.Write(&store.Record{Key: k, Value: []byte(k)}, "test", &broker.Message{
+			Header: map[string]string{"key": k},
+			Body:   []byte(k),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if recs, err := s.Read("foo"); err != nil || string(recs[0].Value) != "foo" {
+		t.Fatalf("Expected the record to be written got %v %v", recs, err)
+	}
+
+	var msgs []*broker.Message
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		msgs = append(msgs, e.Message())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is lost while the broker is down
+	if err := o.Relay(); err == nil {
+		t.Fatal("Expected the relay to fail")
+	}
+
+	b.down = false
+	if err := o.Relay(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(msgs) != 2 || string(msgs[0].Body) != "foo" || string(msgs[1].Body) != "bar" {
+		t.Fatalf("Expected the messages in order got %v", msgs)
+	}
+	if key := msgs[0].Header[IdempotencyKeyHeader]; len(key) == 0 || msgs[0].Header["Micro-Id"] != key {
+		t.Fatalf("Expected an idempotency key got %v", msgs[0].Header)
+	}
+
+	// sent messages aren't published again
+	if err := o.Relay(); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("Expected 2 messages got %d", len(msgs))
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	b := bmemory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	ch := make(chan broker.Event, 1)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		ch <- e
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewOutbox(Store(smemory.NewStore()), Broker(b), Interval(time.Millisecond*10))
+	if err := o.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer o.Stop()
+
+	if err := o.Write(&store.Record{Key: "foo"}, "test", &broker.Message{Body: []byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-ch:
+		if string(e.Message().Body) != "foo" {
+			t.Fatalf("Unexpected message %s", e.Message().Body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the relay to publish the message")
+	}
+}
+
+func TestOutboxTable(t *testing.T) {
+	b := &failingBroker{Broker: bmemory.NewBroker(), down: true}
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	var msgs []*broker.Message
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		msgs = append(msgs, e.Message())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := smemory.NewStore()
+	o := NewOutbox(Store(s), Broker(b))
+
+	if err := o.Write(&store.Record{Key: "foo", Value: []byte("foo")}, "test", &broker.Message{
+		Body: []byte("foo"),
+	}, store.WriteTo("db", "table"), store.WriteTTL(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Read("foo", store.ReadFrom("db", "table"))
+	if err != nil || recs[0].Expiry <= 0 {
+		t.Fatalf("Expected the record to be written with an expiry got %v %v", recs, err)
+	}
+	recs, err = s.Read(DefaultPrefix, store.ReadPrefix(), store.ReadFrom("db", "table"))
+	if err != nil || len(recs) != 1 || recs[0].Expiry != 0 {
+		t.Fatalf("Expected the message to be written without an expiry got %v %v", recs, err)
+	}
+
+	// a new outbox relays what was left in the table
+	b.down = false
+	o = NewOutbox(Store(s), Broker(b), RelayFrom("db", "table"))
+	if err := o.Relay(); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || string(msgs[0].Body) != "foo" {
+		t.Fatalf("Expected the message to be published got %v", msgs)
+	}
+	if recs, _ := s.Read(DefaultPrefix, store.ReadPrefix(), store.ReadFrom("db", "table")); len(recs) != 0 {
+		t.Fatalf("Expected the message to be marked sent got %v", recs)
+	}
+}
+
+func TestOutboxPoison(t *testing.T) {
+	b := bmemory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	var msgs []*broker.Message
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		msgs = append(msgs, e.Message())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := smemory.NewStore()
+	o := NewOutbox(Store(s), Broker(b))
+
+	// a message which can't be read ahead of one which can
+	if err := s.Write(&store.Record{Key: DefaultPrefix + "0", Value: []byte("{")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Write(&store.Record{Key: "foo"}, "test", &broker.Message{Body: []byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Relay(); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || string(msgs[0].Body) != "foo" {
+		t.Fatalf("Expected the readable message to be published got %v", msgs)
+	}
+
+	// the message is moved aside
+	if recs, _ := s.Read(DefaultPrefix, store.ReadPrefix()); len(recs) != 0 {
+		t.Fatalf("Expected no pending messages got %v", recs)
+	}
+	if recs, err := s.Read(PoisonPrefix + DefaultPrefix + "0"); err != nil || string(recs[0].Value) != "{" {
+		t.Fatalf("Expected the message to be moved got %v %v", recs, err)
+	}
+}
+
+func TestOutboxNotTransactional(t *testing.T) {
+	o := NewOutbox(Store(store.DefaultStore))
+	if err := o.Write(&store.Record{Key: "foo"}, "test", &broker.Message{}); err != ErrNotTransactional {
+		t.Fatalf("Expected %v got %v", ErrNotTransactional, err)
+	}
+}
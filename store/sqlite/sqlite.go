@@ -313,6 +313,54 @@ func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
 	return nil
 }
 
+// WriteAll writes the records in a single transaction
+func (s *sqlStore) WriteAll(rs []*store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	db, err := s.createDB(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+
+	st, err := s.prepare(db, options.Database, options.Table, "write")
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stx := tx.Stmt(st)
+	defer stx.Close()
+
+	for _, r := range rs {
+		metadata := make(Metadata)
+		for k, v := range r.Metadata {
+			metadata[k] = v
+		}
+
+		if r.Expiry != 0 {
+			_, err = stx.Exec(r.Key, r.Value, metadata, time.Now().Add(r.Expiry))
+		} else {
+			_, err = stx.Exec(r.Key, r.Value, metadata, nil)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "Couldn't insert record "+r.Key)
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (s *sqlStore) createDB(database, table string) (*sql.DB, error) {
 	database, table = s.getDB(database, table)
 
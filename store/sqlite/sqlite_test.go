@@ -192,3 +192,26 @@ func TestSqlStoreList(t *testing.T) {
 		t.Fatal("Results should have returned 0 records")
 	}
 }
+
+func TestSqlStoreWriteAll(t *testing.T) {
+	sqlStore := NewStore(
+		store.Database("testwriteall"),
+	)
+	defer cleanup("testwriteall", sqlStore)
+
+	ts := sqlStore.(store.Transactional)
+	if err := ts.WriteAll([]*store.Record{
+		{Key: "foo", Value: []byte("bar")},
+		{Key: "baz", Value: []byte("qux")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := sqlStore.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Results should have returned 2 records got %d", len(results))
+	}
+}
@@ -34,6 +34,12 @@ type Store interface {
 	String() string
 }
 
+// Transactional is implemented by stores which can write several records atomically
+type Transactional interface {
+	// WriteAll writes the records in a single transaction, either all of them are written or none are.
+	WriteAll(rs []*Record, opts ...WriteOption) error
+}
+
 // Record is an item stored or retrieved from a Store
 type Record struct {
 	// The key to store the record
@@ -54,9 +54,7 @@ type memoryTransport struct {
 }
 
 func (ms *memorySocket) Recv(m *transport.Message) error {
-	ms.RLock()
-	defer ms.RUnlock()
-
+	// the lock isn't held while blocked so Close isn't held up
 	ctx := ms.ctx
 	if ms.timeout > 0 {
 		var cancel context.CancelFunc
@@ -86,9 +84,17 @@ func (ms *memorySocket) Remote() string {
 }
 
 func (ms *memorySocket) Send(m *transport.Message) error {
-	ms.RLock()
-	defer ms.RUnlock()
+	// copy the message since senders may reuse it once Send returns
+	cm := &transport.Message{
+		Header: make(map[string]string, len(m.Header)),
+		Body:   make([]byte, len(m.Body)),
+	}
+	for k, v := range m.Header {
+		cm.Header[k] = v
+	}
+	copy(cm.Body, m.Body)
 
+	// the lock isn't held while blocked so Close isn't held up
 	ctx := ms.ctx
 	if ms.timeout > 0 {
 		var cancel context.CancelFunc
@@ -103,7 +109,7 @@ func (ms *memorySocket) Send(m *transport.Message) error {
 		return errors.New("connection closed")
 	case <-ms.lexit:
 		return errors.New("server connection closed")
-	case ms.send <- m:
+	case ms.send <- cm:
 	}
 	return nil
 }
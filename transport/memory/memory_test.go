@@ -92,3 +92,46 @@ func TestListener(t *testing.T) {
 		t.Fatal("Expected error binding to :8080 got nil")
 	}
 }
+
+func TestMemorySendCopy(t *testing.T) {
+	tr := NewTransport()
+
+	l, err := tr.Listen("127.0.0.1:8081")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	received := make(chan transport.Message, 1)
+	go func() {
+		l.Accept(func(sock transport.Socket) {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			received <- m
+		})
+	}()
+
+	c, err := tr.Dial("127.0.0.1:8081")
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	// the sender reuses its buffers once Send returns
+	m := &transport.Message{
+		Header: map[string]string{"Foo": "bar"},
+		Body:   []byte(`ping`),
+	}
+	if err := c.Send(m); err != nil {
+		t.Fatal(err)
+	}
+	copy(m.Body, `pong`)
+	m.Header["Foo"] = "baz"
+
+	rm := <-received
+	if string(rm.Body) != "ping" || rm.Header["Foo"] != "bar" {
+		t.Fatalf("Expected the sent message got %s %v", rm.Body, rm.Header)
+	}
+}
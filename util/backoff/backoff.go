@@ -14,3 +14,15 @@ func Do(attempts int) time.Duration {
 	}
 	return time.Duration(math.Pow(float64(attempts), math.E)) * time.Millisecond * 100
 }
+
+// Exponential doubles the base for each attempt. Result is limited to max.
+func Exponential(base, max time.Duration, attempts int) time.Duration {
+	if attempts < 0 || base <= 0 {
+		return 0
+	}
+	// avoid overflowing the duration
+	if attempts > 62 || base > max>>uint(attempts) {
+		return max
+	}
+	return base << uint(attempts)
+}
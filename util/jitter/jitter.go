@@ -3,15 +3,28 @@ package jitter
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
 var (
 	r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	// rand.Rand is not safe for concurrent use
+	mtx sync.Mutex
 )
 
 // Do returns a random time to jitter with max cap specified
 func Do(d time.Duration) time.Duration {
+	mtx.Lock()
 	v := r.Float64() * float64(d.Nanoseconds())
+	mtx.Unlock()
 	return time.Duration(v)
 }
+
+// Between returns a random time between min and max
+func Between(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + Do(max-min)
+}
@@ -0,0 +1,49 @@
+// Package lock provides a lock per key
+package lock
+
+import (
+	"sync"
+)
+
+// Keys hands out a lock per key. The lock of a key is dropped once
+// it's not held or waited on. The zero value is ready to use.
+type Keys struct {
+	sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is a lock shared by the holders of a key
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock locks the key waiting for it to be unlocked if held
+func (k *Keys) Lock(key string) {
+	k.Mutex.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	kl, ok := k.locks[key]
+	if !ok {
+		kl = new(keyLock)
+		k.locks[key] = kl
+	}
+	kl.refs++
+	k.Mutex.Unlock()
+
+	kl.Lock()
+}
+
+// Unlock unlocks the key
+func (k *Keys) Unlock(key string) {
+	k.Mutex.Lock()
+	kl := k.locks[key]
+	kl.refs--
+	if kl.refs == 0 {
+		delete(k.locks, key)
+	}
+	k.Mutex.Unlock()
+
+	kl.Unlock()
+}
@@ -0,0 +1,50 @@
+package lock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeys(t *testing.T) {
+	var k Keys
+
+	k.Lock("foo")
+
+	// other keys aren't held up
+	done := make(chan bool)
+	go func() {
+		k.Lock("bar")
+		k.Unlock("bar")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected another key to be locked")
+	}
+
+	var wg sync.WaitGroup
+	var held bool
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k.Lock("foo")
+			defer k.Unlock("foo")
+			if held {
+				t.Error("Expected the key to be held once")
+			}
+			held = true
+			time.Sleep(time.Millisecond)
+			held = false
+		}()
+	}
+
+	k.Unlock("foo")
+	wg.Wait()
+
+	if len(k.locks) != 0 {
+		t.Fatalf("Expected the locks to be dropped got %d", len(k.locks))
+	}
+}
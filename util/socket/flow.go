@@ -0,0 +1,287 @@
+package socket
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/transport"
+)
+
+var (
+	// WindowHeader carries the number of messages the sender of a stream
+	// request is willing to buffer. The peer enables flow control with it.
+	WindowHeader = "Micro-Window"
+	// WindowUpdateHeader marks a control message granting the peer more messages
+	WindowUpdateHeader = "Micro-Window-Update"
+
+	// DefaultWindow is the number of messages buffered for a stream
+	DefaultWindow = 64
+
+	// ErrWindowTimeout is returned by Send when the peer doesn't consume in time
+	ErrWindowTimeout = errors.New("timed out waiting for the peer to consume the stream")
+	// ErrWindowExceeded is returned by Recv when the peer sends too far past the window
+	ErrWindowExceeded = errors.New("peer exceeded the stream window")
+)
+
+// Flow is a socket with credit based flow control. It reads ahead into a
+// buffer and grants the peer more as messages are received. A peer keeping
+// to the window never has more than the window size buffered. Up to twice
+// the window is read ahead so a peer which doesn't know the window yet
+// isn't held up, past that the stream fails with ErrWindowExceeded. Send
+// blocks once the messages granted by the peer are used up.
+type Flow struct {
+	transport.Socket
+
+	// the receive window
+	window int
+	// how long Send waits for credit
+	timeout time.Duration
+	// headers set on control messages
+	header map[string]string
+
+	// serialises writes to the socket
+	wmtx sync.Mutex
+
+	sync.Mutex
+	// the peer's window is known
+	open bool
+	// messages we may send, negative until the peer's window is known
+	credit int
+	// messages received since the last window update
+	consumed int
+	// the read error
+	err error
+	// messages read ahead
+	queue []*transport.Message
+
+	ready chan bool
+	grant chan bool
+	done  chan bool
+	exit  chan bool
+	once  sync.Once
+}
+
+// NewFlow returns a flow controlled socket buffering up to window messages.
+// Send waits up to timeout for the peer to consume, forever if zero. The
+// header is set on the control messages e.g to identify the stream.
+func NewFlow(sock transport.Socket, window int, timeout time.Duration, header map[string]string) *Flow {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	f := &Flow{
+		Socket:  sock,
+		window:  window,
+		timeout: timeout,
+		header:  header,
+		ready:   make(chan bool, 1),
+		grant:   make(chan bool, 1),
+		done:    make(chan bool),
+		exit:    make(chan bool),
+	}
+
+	go f.read()
+
+	return f
+}
+
+// IsWindowUpdate returns true if the message is a flow control message
+func IsWindowUpdate(m *transport.Message) bool {
+	_, ok := m.Header[WindowUpdateHeader]
+	return ok
+}
+
+// counted returns true if the message uses up the window. Errors,
+// including the end of stream, are always let through.
+func counted(m *transport.Message) bool {
+	return len(m.Header["Micro-Error"]) == 0
+}
+
+// read takes the messages off the socket applying the window updates
+func (f *Flow) read() {
+	defer close(f.done)
+
+	for {
+		m := new(transport.Message)
+		if err := f.Socket.Recv(m); err != nil {
+			f.Lock()
+			f.err = err
+			f.Unlock()
+			return
+		}
+
+		if IsWindowUpdate(m) {
+			n, _ := strconv.Atoi(m.Header[WindowUpdateHeader])
+			f.Open(n)
+			continue
+		}
+
+		f.Lock()
+		if len(f.queue) >= 2*f.window {
+			// the peer isn't keeping to the window
+			f.err = ErrWindowExceeded
+			f.queue = nil
+			f.Unlock()
+			f.Socket.Close()
+			return
+		}
+		f.queue = append(f.queue, m)
+		f.Unlock()
+
+		// signal anyone waiting to receive
+		select {
+		case f.ready <- true:
+		default:
+		}
+	}
+}
+
+// Open lets the flow send n more messages and enables flow control.
+// It's called with the peer's window once known and on every update.
+func (f *Flow) Open(n int) {
+	f.Lock()
+	wasOpen := f.open
+	f.open = true
+	f.credit += n
+	f.Unlock()
+
+	// signal anyone waiting for credit
+	select {
+	case f.grant <- true:
+	default:
+	}
+
+	// grant the messages consumed before we knew the peer's window
+	if !wasOpen {
+		f.update(0)
+	}
+}
+
+// Announce sends our window to the peer
+func (f *Flow) Announce() error {
+	return f.write(f.window)
+}
+
+// update grants the peer the consumed messages once over half the window
+func (f *Flow) update(min int) {
+	f.Lock()
+	if !f.open || f.consumed == 0 || f.consumed < min {
+		f.Unlock()
+		return
+	}
+	n := f.consumed
+	f.consumed = 0
+	f.Unlock()
+
+	f.write(n)
+}
+
+// write sends a window update granting the peer n messages
+func (f *Flow) write(n int) error {
+	m := &transport.Message{
+		Header: make(map[string]string, len(f.header)+1),
+	}
+	for k, v := range f.header {
+		m.Header[k] = v
+	}
+	m.Header[WindowUpdateHeader] = strconv.Itoa(n)
+
+	f.wmtx.Lock()
+	defer f.wmtx.Unlock()
+	return f.Socket.Send(m)
+}
+
+// Send sends the message once the peer has granted it
+func (f *Flow) Send(m *transport.Message) error {
+	if counted(m) {
+		if err := f.wait(); err != nil {
+			return err
+		}
+	}
+
+	f.wmtx.Lock()
+	defer f.wmtx.Unlock()
+	return f.Socket.Send(m)
+}
+
+// wait blocks until the peer has granted a message to be sent
+func (f *Flow) wait() error {
+	var timeout <-chan time.Time
+	if f.timeout > 0 {
+		t := time.NewTimer(f.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	for {
+		f.Lock()
+		// until the peer's window is known we don't hold back
+		if !f.open || f.credit > 0 {
+			f.credit--
+			f.Unlock()
+			return nil
+		}
+		f.Unlock()
+
+		select {
+		case <-f.grant:
+		case <-timeout:
+			return ErrWindowTimeout
+		case <-f.done:
+			return io.EOF
+		case <-f.exit:
+			return io.EOF
+		}
+	}
+}
+
+// Recv returns the next message granting the peer more as they're consumed
+func (f *Flow) Recv(m *transport.Message) error {
+	for {
+		f.Lock()
+		if len(f.queue) > 0 {
+			msg := f.queue[0]
+			f.queue[0] = nil
+			f.queue = f.queue[1:]
+			f.Unlock()
+
+			*m = *msg
+
+			if counted(msg) {
+				f.Lock()
+				f.consumed++
+				f.Unlock()
+				f.update(f.window / 2)
+			}
+
+			return nil
+		}
+		f.Unlock()
+
+		select {
+		case <-f.ready:
+		case <-f.done:
+			// drain what was read before the error
+			f.Lock()
+			if len(f.queue) == 0 {
+				err := f.err
+				f.Unlock()
+				return err
+			}
+			f.Unlock()
+		case <-f.exit:
+			return io.EOF
+		}
+	}
+}
+
+// Close stops reading and closes the socket
+func (f *Flow) Close() error {
+	f.once.Do(func() {
+		close(f.exit)
+	})
+	return f.Socket.Close()
+}
@@ -0,0 +1,184 @@
+package socket
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/transport"
+)
+
+// pipe is one end of an in memory connection
+type pipe struct {
+	in     chan *transport.Message
+	out    chan *transport.Message
+	closed chan bool
+}
+
+func newPipe() (*pipe, *pipe) {
+	a := make(chan *transport.Message, 1024)
+	b := make(chan *transport.Message, 1024)
+	closed := make(chan bool)
+	return &pipe{in: a, out: b, closed: closed}, &pipe{in: b, out: a, closed: closed}
+}
+
+func (p *pipe) Recv(m *transport.Message) error {
+	select {
+	case msg := <-p.in:
+		*m = *msg
+		return nil
+	case <-p.closed:
+		return io.EOF
+	}
+}
+
+func (p *pipe) Send(m *transport.Message) error {
+	p.out <- m
+	return nil
+}
+
+func (p *pipe) Close() error {
+	select {
+	case <-p.closed:
+	default:
+		close(p.closed)
+	}
+	return nil
+}
+
+func (p *pipe) Local() string  { return "local" }
+func (p *pipe) Remote() string { return "remote" }
+
+func TestFlow(t *testing.T) {
+	a, b := newPipe()
+
+	sender := NewFlow(a, 4, time.Millisecond*50, nil)
+	defer sender.Close()
+	receiver := NewFlow(b, 4, 0, nil)
+	defer receiver.Close()
+
+	// the receiver tells the sender its window
+	receiver.Open(4)
+	if err := receiver.Announce(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; ; i++ {
+		sender.Lock()
+		open := sender.open
+		sender.Unlock()
+		if open {
+			break
+		}
+		if i == 100 {
+			t.Fatal("Expected the sender to learn the window")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	send := func() error {
+		return sender.Send(&transport.Message{Body: []byte("hello")})
+	}
+
+	// the window can be used up without the receiver consuming
+	for i := 0; i < 4; i++ {
+		if err := send(); err != nil {
+			t.Fatalf("Unexpected error sending %d: %v", i, err)
+		}
+	}
+	if err := send(); err != ErrWindowTimeout {
+		t.Fatalf("Expected %v got %v", ErrWindowTimeout, err)
+	}
+
+	// errors such as the end of stream are always let through
+	if err := sender.Send(&transport.Message{Header: map[string]string{"Micro-Error": "EOS"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// consuming half the window grants it back to the sender
+	for i := 0; i < 2; i++ {
+		var m transport.Message
+		if err := receiver.Recv(&m); err != nil {
+			t.Fatal(err)
+		}
+		if string(m.Body) != "hello" {
+			t.Fatalf("Unexpected message %v", m)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := send(); err != nil {
+			t.Fatalf("Unexpected error sending %d after the update: %v", i, err)
+		}
+	}
+	if err := send(); err != ErrWindowTimeout {
+		t.Fatalf("Expected %v got %v", ErrWindowTimeout, err)
+	}
+}
+
+func TestFlowNotOpen(t *testing.T) {
+	a, b := newPipe()
+
+	// without the peer's window nothing is held back
+	sender := NewFlow(a, 1, time.Millisecond*50, nil)
+	defer sender.Close()
+	defer b.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := sender.Send(&transport.Message{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// nor are window updates sent to a peer which doesn't do flow control
+	if n := len(b.in); n != 10 {
+		t.Fatalf("Expected 10 messages got %d", n)
+	}
+}
+
+func TestFlowOverrun(t *testing.T) {
+	a, b := newPipe()
+
+	receiver := NewFlow(b, 4, 0, nil)
+	defer receiver.Close()
+
+	// a peer which doesn't know the window sends past it
+	for i := 0; i < 8; i++ {
+		a.Send(&transport.Message{Body: []byte("hello")})
+	}
+
+	// the socket underneath is still read so it's never held up
+	for i := 0; len(b.in) > 0; i++ {
+		if i == 100 {
+			t.Fatalf("Expected the messages to be read ahead got %d left", len(b.in))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 8; i++ {
+		var m transport.Message
+		if err := receiver.Recv(&m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFlowExceeded(t *testing.T) {
+	a, b := newPipe()
+
+	receiver := NewFlow(b, 4, 0, nil)
+	defer receiver.Close()
+
+	// a peer sending past twice the window fails the stream
+	for i := 0; i < 9; i++ {
+		a.Send(&transport.Message{Body: []byte("hello")})
+	}
+	select {
+	case <-receiver.done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to fail")
+	}
+
+	var m transport.Message
+	if err := receiver.Recv(&m); err != ErrWindowExceeded {
+		t.Fatalf("Expected %v got %v", ErrWindowExceeded, err)
+	}
+}
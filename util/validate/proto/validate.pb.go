@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: util/validate/proto/validate.proto
+
+package validate
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	descriptor "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Rules are checked against a field before the handler is called
+type Rules struct {
+	// the field must be set to a non zero value
+	Required *bool `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
+	// strings must match the regular expression
+	Pattern *string `protobuf:"bytes,2,opt,name=pattern" json:"pattern,omitempty"`
+	// numbers must be at least min
+	Min *float64 `protobuf:"fixed64,3,opt,name=min" json:"min,omitempty"`
+	// numbers must be at most max
+	Max *float64 `protobuf:"fixed64,4,opt,name=max" json:"max,omitempty"`
+	// strings and bytes must be at least min_len long
+	MinLen *uint64 `protobuf:"varint,5,opt,name=min_len,json=minLen" json:"min_len,omitempty"`
+	// strings and bytes must be at most max_len long
+	MaxLen *uint64 `protobuf:"varint,6,opt,name=max_len,json=maxLen" json:"max_len,omitempty"`
+	// repeated fields and maps must have at least min_items
+	MinItems *uint64 `protobuf:"varint,7,opt,name=min_items,json=minItems" json:"min_items,omitempty"`
+	// repeated fields and maps must have at most max_items
+	MaxItems             *uint64  `protobuf:"varint,8,opt,name=max_items,json=maxItems" json:"max_items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Rules) Reset()         { *m = Rules{} }
+func (m *Rules) String() string { return proto.CompactTextString(m) }
+func (*Rules) ProtoMessage()    {}
+func (*Rules) Descriptor() ([]byte, []int) {
+	return fileDescriptor_293467ae28d8c87d, []int{0}
+}
+
+func (m *Rules) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Rules.Unmarshal(m, b)
+}
+func (m *Rules) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Rules.Marshal(b, m, deterministic)
+}
+func (m *Rules) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Rules.Merge(m, src)
+}
+func (m *Rules) XXX_Size() int {
+	return xxx_messageInfo_Rules.Size(m)
+}
+func (m *Rules) XXX_DiscardUnknown() {
+	xxx_messageInfo_Rules.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Rules proto.InternalMessageInfo
+
+func (m *Rules) GetRequired() bool {
+	if m != nil && m.Required != nil {
+		return *m.Required
+	}
+	return false
+}
+
+func (m *Rules) GetPattern() string {
+	if m != nil && m.Pattern != nil {
+		return *m.Pattern
+	}
+	return ""
+}
+
+func (m *Rules) GetMin() float64 {
+	if m != nil && m.Min != nil {
+		return *m.Min
+	}
+	return 0
+}
+
+func (m *Rules) GetMax() float64 {
+	if m != nil && m.Max != nil {
+		return *m.Max
+	}
+	return 0
+}
+
+func (m *Rules) GetMinLen() uint64 {
+	if m != nil && m.MinLen != nil {
+		return *m.MinLen
+	}
+	return 0
+}
+
+func (m *Rules) GetMaxLen() uint64 {
+	if m != nil && m.MaxLen != nil {
+		return *m.MaxLen
+	}
+	return 0
+}
+
+func (m *Rules) GetMinItems() uint64 {
+	if m != nil && m.MinItems != nil {
+		return *m.MinItems
+	}
+	return 0
+}
+
+func (m *Rules) GetMaxItems() uint64 {
+	if m != nil && m.MaxItems != nil {
+		return *m.MaxItems
+	}
+	return 0
+}
+
+var E_Rules = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*Rules)(nil),
+	Field:         52000,
+	Name:          "go.micro.validate.rules",
+	Tag:           "bytes,52000,opt,name=rules",
+	Filename:      "util/validate/proto/validate.proto",
+}
+
+func init() {
+	proto.RegisterType((*Rules)(nil), "go.micro.validate.Rules")
+	proto.RegisterExtension(E_Rules)
+}
+
+func init() {
+	proto.RegisterFile("util/validate/proto/validate.proto", fileDescriptor_293467ae28d8c87d)
+}
+
+var fileDescriptor_293467ae28d8c87d = []byte{
+	// 291 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0x3f, 0x4f, 0xf3, 0x30,
+	0x10, 0x87, 0xe5, 0xb7, 0x4d, 0x93, 0xfa, 0x5d, 0x20, 0x0b, 0x56, 0x11, 0x52, 0xd4, 0x29, 0x0b,
+	0xb6, 0xd4, 0x0d, 0xba, 0x31, 0x20, 0x21, 0x21, 0x55, 0xf2, 0xc8, 0x82, 0xdc, 0xc4, 0x84, 0x93,
+	0xfc, 0x27, 0x38, 0x4e, 0xe5, 0x4f, 0xc2, 0xcc, 0x17, 0xe2, 0x3b, 0xa1, 0x38, 0x49, 0x17, 0xd8,
+	0xee, 0xb9, 0xe7, 0x2e, 0xca, 0xef, 0x8c, 0xb7, 0xbd, 0x07, 0xc5, 0x4e, 0x42, 0x41, 0x2d, 0xbc,
+	0x64, 0xad, 0xb3, 0xde, 0x9e, 0x91, 0x46, 0xcc, 0x2f, 0x1b, 0x4b, 0x35, 0x54, 0xce, 0xd2, 0x59,
+	0x6c, 0x8a, 0xc6, 0xda, 0x46, 0x4d, 0xf3, 0xc7, 0xfe, 0x8d, 0xd5, 0xb2, 0xab, 0x1c, 0xb4, 0xde,
+	0xba, 0x71, 0x69, 0xfb, 0x8d, 0x70, 0xc2, 0x7b, 0x25, 0xbb, 0x7c, 0x83, 0x33, 0x27, 0x3f, 0x7a,
+	0x70, 0xb2, 0x26, 0xa8, 0x40, 0x65, 0xc6, 0xcf, 0x9c, 0x13, 0x9c, 0xb6, 0xc2, 0x7b, 0xe9, 0x0c,
+	0xf9, 0x57, 0xa0, 0x72, 0xcd, 0x67, 0xcc, 0x2f, 0xf0, 0x42, 0x83, 0x21, 0x8b, 0x02, 0x95, 0x88,
+	0x0f, 0x65, 0xec, 0x88, 0x40, 0x96, 0x53, 0x47, 0x84, 0xfc, 0x0a, 0xa7, 0x1a, 0xcc, 0xab, 0x92,
+	0x86, 0x24, 0x05, 0x2a, 0x97, 0x7c, 0xa5, 0xc1, 0x3c, 0x4b, 0x13, 0x85, 0x08, 0x51, 0xac, 0x26,
+	0x21, 0xc2, 0x20, 0xae, 0xf1, 0x7a, 0xd8, 0x00, 0x2f, 0x75, 0x47, 0xd2, 0xa8, 0x32, 0x0d, 0xe6,
+	0x69, 0xe0, 0x28, 0x45, 0x98, 0x64, 0x36, 0x49, 0x11, 0xa2, 0xbc, 0x3f, 0xe0, 0xc4, 0xc5, 0x38,
+	0x37, 0x74, 0xcc, 0x4e, 0xe7, 0xec, 0xf4, 0x11, 0xa4, 0xaa, 0x0f, 0xad, 0x07, 0x6b, 0x3a, 0xf2,
+	0xf5, 0x39, 0xfc, 0xf2, 0xff, 0x1d, 0xa1, 0xbf, 0xae, 0x46, 0xe3, 0x3d, 0xf8, 0xf8, 0x9d, 0x87,
+	0xfd, 0xcb, 0x5d, 0x03, 0xfe, 0xbd, 0x3f, 0xd2, 0xca, 0x6a, 0x16, 0x27, 0x59, 0x63, 0x6f, 0xc7,
+	0xe2, 0xb4, 0x63, 0x7f, 0x3c, 0xcc, 0x7e, 0xc6, 0x9f, 0x01, 0x00, 0x94, 0xbb, 0x6f, 0x45, 0xb7,
+	0x01, 0x00, 0x00,
+}
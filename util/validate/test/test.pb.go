@@ -0,0 +1,172 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: util/validate/test/test.proto
+
+package test
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	_ "github.com/micro/go-micro/v2/util/validate/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Request struct {
+	Name                 string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email                string     `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Age                  int32      `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
+	Tags                 []string   `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	Address              *Address   `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"`
+	Others               []*Address `protobuf:"bytes,6,rep,name=others,proto3" json:"others,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Request) Reset()         { *m = Request{} }
+func (m *Request) String() string { return proto.CompactTextString(m) }
+func (*Request) ProtoMessage()    {}
+func (*Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6ac5858346d87379, []int{0}
+}
+
+func (m *Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Request.Unmarshal(m, b)
+}
+func (m *Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Request.Marshal(b, m, deterministic)
+}
+func (m *Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Request.Merge(m, src)
+}
+func (m *Request) XXX_Size() int {
+	return xxx_messageInfo_Request.Size(m)
+}
+func (m *Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_Request.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Request proto.InternalMessageInfo
+
+func (m *Request) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Request) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *Request) GetAge() int32 {
+	if m != nil {
+		return m.Age
+	}
+	return 0
+}
+
+func (m *Request) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Request) GetAddress() *Address {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Request) GetOthers() []*Address {
+	if m != nil {
+		return m.Others
+	}
+	return nil
+}
+
+type Address struct {
+	City                 string   `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Address) Reset()         { *m = Address{} }
+func (m *Address) String() string { return proto.CompactTextString(m) }
+func (*Address) ProtoMessage()    {}
+func (*Address) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6ac5858346d87379, []int{1}
+}
+
+func (m *Address) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Address.Unmarshal(m, b)
+}
+func (m *Address) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Address.Marshal(b, m, deterministic)
+}
+func (m *Address) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Address.Merge(m, src)
+}
+func (m *Address) XXX_Size() int {
+	return xxx_messageInfo_Address.Size(m)
+}
+func (m *Address) XXX_DiscardUnknown() {
+	xxx_messageInfo_Address.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Address proto.InternalMessageInfo
+
+func (m *Address) GetCity() string {
+	if m != nil {
+		return m.City
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Request)(nil), "test.Request")
+	proto.RegisterType((*Address)(nil), "test.Address")
+}
+
+func init() {
+	proto.RegisterFile("util/validate/test/test.proto", fileDescriptor_6ac5858346d87379)
+}
+
+var fileDescriptor_6ac5858346d87379 = []byte{
+	// 292 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0xd1, 0x4a, 0xc3, 0x30,
+	0x14, 0x86, 0xcd, 0xda, 0x75, 0x5d, 0x64, 0x08, 0x11, 0x24, 0x1d, 0x0a, 0xb5, 0x38, 0xa8, 0x88,
+	0x8d, 0x4c, 0xf1, 0xc6, 0x9b, 0xda, 0x47, 0xc8, 0xa5, 0xe2, 0x20, 0x6d, 0x43, 0x17, 0x68, 0x8d,
+	0x36, 0xe9, 0xc0, 0xdb, 0x3e, 0x52, 0x9f, 0xc0, 0x47, 0xf2, 0x11, 0xa4, 0x69, 0x27, 0xc8, 0x72,
+	0xf1, 0x73, 0xf2, 0x9d, 0x8f, 0x84, 0x93, 0xc0, 0x8b, 0x46, 0x8b, 0x92, 0xec, 0x58, 0x29, 0x72,
+	0xa6, 0x39, 0xd1, 0x5c, 0x69, 0x13, 0xd1, 0x47, 0x2d, 0xb5, 0x44, 0x76, 0x5f, 0x2f, 0x83, 0xff,
+	0x92, 0x69, 0xfd, 0x6d, 0x07, 0x33, 0xf8, 0x01, 0x70, 0x46, 0xf9, 0x67, 0xc3, 0x95, 0x46, 0xe7,
+	0xd0, 0x7e, 0x67, 0x15, 0xc7, 0xc0, 0x07, 0xe1, 0x3c, 0x71, 0xdb, 0xce, 0xb3, 0x5d, 0x70, 0xe7,
+	0x52, 0x43, 0xd1, 0x35, 0x9c, 0xf2, 0x8a, 0x89, 0x12, 0x4f, 0x4c, 0xfb, 0xb4, 0xed, 0xbc, 0x13,
+	0xb4, 0xd8, 0xbc, 0x6e, 0xe2, 0xb7, 0x9b, 0xd8, 0xe4, 0x15, 0x1d, 0x0c, 0x14, 0x42, 0x8b, 0x15,
+	0x1c, 0x5b, 0x3e, 0x08, 0xa7, 0xc9, 0x59, 0xdb, 0x79, 0xc8, 0x3b, 0x1a, 0xd7, 0xa5, 0xc9, 0xef,
+	0x34, 0xa6, 0xbd, 0x82, 0x96, 0xd0, 0xd6, 0xac, 0x50, 0xd8, 0xf6, 0xad, 0x70, 0x9e, 0x38, 0x6d,
+	0xe7, 0x4d, 0xe2, 0x09, 0x35, 0x0c, 0x11, 0x38, 0x63, 0x79, 0x5e, 0x73, 0xa5, 0xf0, 0xd4, 0x07,
+	0xe1, 0xf1, 0x7a, 0x11, 0x99, 0x11, 0x9f, 0x07, 0x38, 0xd8, 0x2e, 0xa0, 0x7b, 0x0b, 0xad, 0xa0,
+	0x23, 0xf5, 0x96, 0xd7, 0x0a, 0x3b, 0xbe, 0x75, 0xe0, 0xd3, 0xb1, 0x19, 0xac, 0xe0, 0x6c, 0x44,
+	0xfd, 0xf5, 0x99, 0xd0, 0x5f, 0xe3, 0xc4, 0xfb, 0x03, 0x0d, 0x4b, 0x1e, 0x5f, 0x1e, 0x0a, 0xa1,
+	0xb7, 0x4d, 0x1a, 0x65, 0xb2, 0x22, 0x95, 0xc8, 0x6a, 0x49, 0x0a, 0x79, 0x3b, 0x14, 0xbb, 0x35,
+	0x39, 0xfc, 0x81, 0xa7, 0x3e, 0x52, 0xc7, 0x3c, 0xec, 0xfd, 0xef, 0x00, 0xd3, 0xbb, 0xc8, 0x33,
+	0xa3, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,230 @@
+// Package validate checks requests against the rules declared as proto field
+// options. The rules are read at runtime through proto reflection so there's
+// no protoc-gen-micro extension and no validation code is generated.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/v2/errors"
+	pb "github.com/micro/go-micro/v2/util/validate/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	// compiled patterns by expression
+	patterns = sync.Map{}
+)
+
+// Violation is a field which broke a validation rule
+type Violation struct {
+	// Field is the path of the field e.g address.city or tags[1]
+	Field string `json:"field"`
+	// Rule is the name of the rule e.g required
+	Rule string `json:"rule"`
+	// Description of the violation
+	Description string `json:"description"`
+}
+
+// detail is the error detail of a failed validation
+type detail struct {
+	Violations []Violation `json:"violations"`
+}
+
+// validator is implemented by messages with their own validation
+type validator interface {
+	Validate() error
+}
+
+// Validate checks the request against the rules of its fields. It returns
+// a bad request error listing the violations if the request is invalid.
+func Validate(id string, req interface{}) error {
+	var violations []Violation
+
+	if m, ok := req.(proto.Message); ok {
+		violations = check(proto.MessageReflect(m), "")
+	}
+
+	// run any hand written validation
+	if v, ok := req.(validator); ok {
+		if err := v.Validate(); err != nil {
+			violations = append(violations, Violation{
+				Rule:        "validate",
+				Description: err.Error(),
+			})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	b, _ := json.Marshal(detail{Violations: violations})
+	return errors.BadRequest(id, string(b))
+}
+
+// Violations returns the field violations of an error returned by Validate
+func Violations(err error) []Violation {
+	e := errors.FromError(err)
+	if e.Code != 400 {
+		return nil
+	}
+	var d detail
+	if json.Unmarshal([]byte(e.Detail), &d) != nil {
+		return nil
+	}
+	return d.Violations
+}
+
+// check validates the fields of the message and those of nested messages
+func check(m protoreflect.Message, prefix string) []Violation {
+	var violations []Violation
+
+	fields := m.Descriptor().Fields()
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+
+		if rules := fieldRules(fd); rules != nil {
+			violations = append(violations, checkField(m, fd, path, rules)...)
+		}
+
+		// walk into nested messages
+		if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+			continue
+		}
+		if fd.IsMap() || !m.Has(fd) {
+			continue
+		}
+		if fd.IsList() {
+			list := m.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				violations = append(violations, check(list.Get(j).Message(), fmt.Sprintf("%s[%d].", path, j))...)
+			}
+			continue
+		}
+		violations = append(violations, check(m.Get(fd).Message(), path+".")...)
+	}
+
+	return violations
+}
+
+// fieldRules returns the validation rules declared on the field
+func fieldRules(fd protoreflect.FieldDescriptor) *pb.Rules {
+	opts, ok := fd.Options().(proto.Message)
+	if !ok || opts == nil || !proto.HasExtension(opts, pb.E_Rules) {
+		return nil
+	}
+	ext, err := proto.GetExtension(opts, pb.E_Rules)
+	if err != nil {
+		return nil
+	}
+	rules, _ := ext.(*pb.Rules)
+	return rules
+}
+
+func checkField(m protoreflect.Message, fd protoreflect.FieldDescriptor, path string, rules *pb.Rules) []Violation {
+	var violations []Violation
+
+	violate := func(rule, format string, a ...interface{}) {
+		violations = append(violations, Violation{
+			Field:       path,
+			Rule:        rule,
+			Description: fmt.Sprintf(format, a...),
+		})
+	}
+
+	if rules.GetRequired() && !m.Has(fd) {
+		violate("required", "%s is required", path)
+		return violations
+	}
+
+	// repeated fields and maps
+	if fd.IsList() || fd.IsMap() {
+		var n uint64
+		if fd.IsList() {
+			n = uint64(m.Get(fd).List().Len())
+		} else {
+			n = uint64(m.Get(fd).Map().Len())
+		}
+		if rules.MinItems != nil && n < rules.GetMinItems() {
+			violate("min_items", "%s must have at least %d items", path, rules.GetMinItems())
+		}
+		if rules.MaxItems != nil && n > rules.GetMaxItems() {
+			violate("max_items", "%s must have at most %d items", path, rules.GetMaxItems())
+		}
+		return violations
+	}
+
+	// proto2 values are only checked when set, proto3 ones always are
+	if !m.Has(fd) && (fd.Syntax() != protoreflect.Proto3 || fd.Kind() == protoreflect.MessageKind) {
+		return violations
+	}
+
+	v := m.Get(fd)
+
+	switch fd.Kind() {
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		var s string
+		if fd.Kind() == protoreflect.StringKind {
+			s = v.String()
+		} else {
+			s = string(v.Bytes())
+		}
+		n := uint64(len(s))
+		if fd.Kind() == protoreflect.StringKind {
+			n = uint64(len([]rune(s)))
+		}
+		if rules.MinLen != nil && n < rules.GetMinLen() {
+			violate("min_len", "%s must be at least %d long", path, rules.GetMinLen())
+		}
+		if rules.MaxLen != nil && n > rules.GetMaxLen() {
+			violate("max_len", "%s must be at most %d long", path, rules.GetMaxLen())
+		}
+		if rules.Pattern != nil && fd.Kind() == protoreflect.StringKind {
+			re, err := compile(rules.GetPattern())
+			if err != nil {
+				violate("pattern", "%s has an invalid pattern: %v", path, err)
+			} else if !re.MatchString(s) {
+				violate("pattern", "%s must match %s", path, rules.GetPattern())
+			}
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		checkRange(float64(v.Int()), rules, violate, path)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		checkRange(float64(v.Uint()), rules, violate, path)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		checkRange(v.Float(), rules, violate, path)
+	}
+
+	return violations
+}
+
+func checkRange(n float64, rules *pb.Rules, violate func(string, string, ...interface{}), path string) {
+	if rules.Min != nil && n < rules.GetMin() {
+		violate("min", "%s must be at least %v", path, rules.GetMin())
+	}
+	if rules.Max != nil && n > rules.GetMax() {
+		violate("max", "%s must be at most %v", path, rules.GetMax())
+	}
+}
+
+// compile returns the compiled pattern caching it for reuse
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
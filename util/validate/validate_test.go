@@ -0,0 +1,90 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	merrors "github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/util/validate/test"
+)
+
+type checked struct {
+	City string
+}
+
+func (c *checked) Validate() error {
+	if c.City == "nowhere" {
+		return errors.New("nowhere is not a city")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *test.Request {
+		return &test.Request{
+			Name:    "john",
+			Email:   "john@example.com",
+			Age:     30,
+			Tags:    []string{"a"},
+			Address: &test.Address{City: "london"},
+		}
+	}
+
+	if err := Validate("foo", valid()); err != nil {
+		t.Fatalf("Expected a valid request got %v", err)
+	}
+
+	testData := []struct {
+		name   string
+		modify func(r *test.Request)
+		fields []string
+	}{
+		{"Required", func(r *test.Request) { r.Name = "" }, []string{"name"}},
+		{"MaxLen", func(r *test.Request) { r.Name = "jonathanx" }, []string{"name"}},
+		{"Pattern", func(r *test.Request) { r.Email = "john" }, []string{"email"}},
+		{"Max", func(r *test.Request) { r.Age = 200 }, []string{"age"}},
+		{"Min", func(r *test.Request) { r.Age = -1 }, []string{"age"}},
+		{"MaxItems", func(r *test.Request) { r.Tags = []string{"a", "b", "c"} }, []string{"tags"}},
+		{"RequiredMessage", func(r *test.Request) { r.Address = nil }, []string{"address"}},
+		{"Nested", func(r *test.Request) { r.Address.City = "" }, []string{"address.city"}},
+		{"Repeated", func(r *test.Request) {
+			r.Others = []*test.Address{{City: "paris"}, {}}
+		}, []string{"others[1].city"}},
+		{"Many", func(r *test.Request) {
+			r.Name = ""
+			r.Age = 151
+		}, []string{"name", "age"}},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			r := valid()
+			d.modify(r)
+
+			err := Validate("foo", r)
+			if err == nil {
+				t.Fatal("Expected the request to be invalid")
+			}
+			if e := merrors.FromError(err); e.Code != 400 || e.Id != "foo" {
+				t.Fatalf("Expected a bad request error got %v", err)
+			}
+
+			violations := Violations(err)
+			if len(violations) != len(d.fields) {
+				t.Fatalf("Expected %d violations got %+v", len(d.fields), violations)
+			}
+			for i, v := range violations {
+				if v.Field != d.fields[i] {
+					t.Fatalf("Expected a violation of %s got %+v", d.fields[i], v)
+				}
+			}
+		})
+	}
+
+	t.Run("Validator", func(t *testing.T) {
+		err := Validate("foo", &checked{City: "nowhere"})
+		if v := Violations(err); len(v) != 1 || v[0].Rule != "validate" {
+			t.Fatalf("Expected the validate method to be called got %v", err)
+		}
+	})
+}
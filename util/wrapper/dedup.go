@@ -0,0 +1,72 @@
+package wrapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/server"
+	"github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/util/lock"
+)
+
+var (
+	// DedupPrefix is the key prefix of the processed message ids
+	DedupPrefix = "dedup/"
+)
+
+// DedupSubscriber wraps a subscriber to skip messages already processed within
+// the window. Messages are identified by the header, Micro-Id if blank, and
+// handled as usual without one. Each subscriber handler of a topic, told
+// apart by its queue and endpoint, handles a message once. The ids of the
+// messages handled without an error are written to the store with the window
+// as their ttl, failing to write one is logged rather than failing the
+// handled message. Concurrent deliveries of an id wait for the first to finish.
+func DedupSubscriber(s store.Store, header string, window time.Duration) server.SubscriberWrapper {
+	if len(header) == 0 {
+		header = "Micro-Id"
+	}
+
+	locks := new(lock.Keys)
+
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			id := msg.Header()[header]
+			if len(id) == 0 {
+				return fn(ctx, msg)
+			}
+
+			// each handler of the topic handles the message once
+			key := DedupPrefix + msg.Topic() + "/"
+			if queue, endpoint, ok := server.SubscriberFromContext(ctx); ok {
+				key += queue + "/" + endpoint + "/"
+			}
+			key += id
+
+			locks.Lock(key)
+			defer locks.Unlock(key)
+
+			// skip the duplicate
+			if recs, err := s.Read(key); err == nil && len(recs) > 0 {
+				return nil
+			} else if err != nil && err != store.ErrNotFound {
+				return err
+			}
+
+			if err := fn(ctx, msg); err != nil {
+				return err
+			}
+
+			// the message was handled, failing it would have it handled again
+			if err := s.Write(&store.Record{
+				Key:    key,
+				Value:  []byte(time.Now().Format(time.RFC3339)),
+				Expiry: window,
+			}); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Failed to record message %s from %s as handled: %v", id, msg.Topic(), err)
+			}
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package wrapper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/metadata"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/server"
+	"github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/store/memory"
+	tmemory "github.com/micro/go-micro/v2/transport/memory"
+)
+
+type testMessage struct {
+	server.Message
+	header map[string]string
+}
+
+func (m *testMessage) Topic() string             { return "test" }
+func (m *testMessage) Header() map[string]string { return m.header }
+
+// failingStore fails to write
+type failingStore struct {
+	store.Store
+}
+
+func (s *failingStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	return errors.New("store down")
+}
+
+func TestDedupSubscriber(t *testing.T) {
+	var calls int32
+	fail := true
+
+	fn := DedupSubscriber(memory.NewStore(), "", time.Millisecond*100)(func(ctx context.Context, msg server.Message) error {
+		atomic.AddInt32(&calls, 1)
+		if fail {
+			return errors.New("failed")
+		}
+		// concurrent deliveries are held until the first is done
+		time.Sleep(time.Millisecond * 10)
+		return nil
+	})
+
+	msg := &testMessage{header: map[string]string{"Micro-Id": "1"}}
+
+	// failed messages are handled again
+	if err := fn(context.TODO(), msg); err == nil {
+		t.Fatal("Expected the handler error")
+	}
+	fail = false
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(context.TODO(), msg); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c := atomic.LoadInt32(&calls); c != 2 {
+		t.Fatalf("Expected 2 calls got %d", c)
+	}
+
+	// messages without an id aren't deduplicated
+	fn(context.TODO(), &testMessage{})
+	fn(context.TODO(), &testMessage{})
+	if c := atomic.LoadInt32(&calls); c != 4 {
+		t.Fatalf("Expected 4 calls got %d", c)
+	}
+
+	// the id is handled again once the window passed
+	time.Sleep(time.Millisecond * 150)
+	if err := fn(context.TODO(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if c := atomic.LoadInt32(&calls); c != 5 {
+		t.Fatalf("Expected 5 calls got %d", c)
+	}
+}
+
+func TestDedupSubscriberWriteError(t *testing.T) {
+	var calls int32
+	fn := DedupSubscriber(&failingStore{memory.NewStore()}, "", time.Minute)(func(ctx context.Context, msg server.Message) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	// the handled message isn't failed if its id can't be written
+	msg := &testMessage{header: map[string]string{"Micro-Id": "1"}}
+	if err := fn(context.TODO(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if c := atomic.LoadInt32(&calls); c != 1 {
+		t.Fatalf("Expected 1 call got %d", c)
+	}
+}
+
+// Counter counts the messages passed to each of its methods
+type Counter struct {
+	first, second int32
+}
+
+func (c *Counter) First(ctx context.Context, msg *map[string]string) error {
+	atomic.AddInt32(&c.first, 1)
+	return nil
+}
+
+func (c *Counter) Second(ctx context.Context, msg *map[string]string) error {
+	atomic.AddInt32(&c.second, 1)
+	return nil
+}
+
+func TestDedupSubscriberHandlers(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Transport(tmemory.NewTransport()),
+		server.WrapSubscriber(DedupSubscriber(memory.NewStore(), "X-Id", time.Minute)),
+	)
+
+	// a subscriber with two methods and another subscriber on the topic
+	c := new(Counter)
+	var other int32
+	if err := srv.Subscribe(srv.NewSubscriber("test", c)); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Subscribe(srv.NewSubscriber("test", func(ctx context.Context, msg *map[string]string) error {
+		atomic.AddInt32(&other, 1)
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(client.Registry(reg), client.Broker(brk), client.ContentType("application/json"))
+	ctx := metadata.NewContext(context.TODO(), map[string]string{"X-Id": "1"})
+	for i := 0; i < 2; i++ {
+		if err := cli.Publish(ctx, cli.NewMessage("test", map[string]string{"foo": "bar"})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// every handler gets the message once
+	for i := 0; ; i++ {
+		if atomic.LoadInt32(&c.first) == 1 && atomic.LoadInt32(&c.second) == 1 && atomic.LoadInt32(&other) == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("Expected each handler to be called once got %d %d %d", c.first, c.second, other)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
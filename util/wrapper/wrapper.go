@@ -13,6 +13,7 @@ import (
 	"github.com/micro/go-micro/v2/logger"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/server"
+	"github.com/micro/go-micro/v2/util/validate"
 )
 
 type fromServiceWrapper struct {
@@ -72,6 +73,24 @@ func HandlerStats(stats stats.Stats) server.HandlerWrapper {
 	}
 }
 
+// ValidateHandler wraps a server handler to validate requests against the
+// rules declared on their fields. Invalid requests are rejected with a bad
+// request error listing the violations.
+func ValidateHandler() server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			// the messages of a stream are read by the handler
+			if req.Stream() {
+				return h(ctx, req, rsp)
+			}
+			if err := validate.Validate(req.Service(), req.Body()); err != nil {
+				return err
+			}
+			return h(ctx, req, rsp)
+		}
+	}
+}
+
 type traceWrapper struct {
 	client.Client
 
@@ -249,7 +268,8 @@ func AuthHandler(fn func() auth.Auth) server.HandlerWrapper {
 }
 
 type cacheWrapper struct {
-	cacheFn func() *client.Cache
+	cacheFn func() client.Cache
+	group   *client.Group
 	client.Client
 }
 
@@ -268,8 +288,13 @@ func (c *cacheWrapper) Call(ctx context.Context, req client.Request, rsp interfa
 		return c.Client.Call(ctx, req, rsp, opts...)
 	}
 
-	// if the cache expiry is not set, execute the call without the cache
+	// fall back to the expiry of the cache rules
 	if options.CacheExpiry == 0 {
+		options.CacheExpiry = cache.Options().Expiry(req)
+	}
+
+	// if the cache expiry is not set and calls aren't coalesced, execute the call without the cache
+	if options.CacheExpiry == 0 && !options.Coalesce {
 		return c.Client.Call(ctx, req, rsp, opts...)
 	}
 
@@ -279,25 +304,72 @@ func (c *cacheWrapper) Call(ctx context.Context, req client.Request, rsp interfa
 	}
 
 	// check to see if there is a response cached, if there is assign it
-	if r, ok := cache.Get(ctx, &req); ok {
-		val := reflect.ValueOf(rsp).Elem()
-		val.Set(reflect.ValueOf(r).Elem())
+	if options.CacheExpiry > 0 {
+		r := reflect.New(reflect.TypeOf(rsp).Elem()).Interface()
+		if stale, ok := cache.Get(ctx, &req, r); ok && (!stale || options.CacheStale > 0) {
+			val := reflect.ValueOf(rsp).Elem()
+			val.Set(reflect.ValueOf(r).Elem())
+
+			// refresh the expired response in the background
+			if stale {
+				go c.revalidate(ctx, cache, req, rsp, options, opts)
+			}
+			return nil
+		}
+	}
+
+	if !options.Coalesce {
+		// don't cache the result if there was an error
+		if err := c.Client.Call(ctx, req, rsp, opts...); err != nil {
+			return err
+		}
+
+		// set the result in the cache
+		if options.CacheExpiry > 0 {
+			cache.Set(ctx, &req, rsp, options.CacheExpiry, options.CacheStale)
+		}
 		return nil
 	}
 
-	// don't cache the result if there was an error
-	if err := c.Client.Call(ctx, req, rsp, opts...); err != nil {
+	// share the call with any identical requests in flight
+	r, _, err := c.group.Do(ctx, &req, func(ctx context.Context) (interface{}, error) {
+		return c.call(ctx, cache, req, rsp, options, opts)
+	})
+	if err != nil {
 		return err
 	}
 
-	// set the result in the cache
-	cache.Set(ctx, &req, rsp, options.CacheExpiry)
+	val := reflect.ValueOf(rsp).Elem()
+	val.Set(reflect.ValueOf(r).Elem())
 	return nil
 }
 
+// call makes the request with a new response and caches the result
+func (c *cacheWrapper) call(ctx context.Context, cache client.Cache, req client.Request, rsp interface{}, options client.CallOptions, opts []client.CallOption) (interface{}, error) {
+	r := reflect.New(reflect.TypeOf(rsp).Elem()).Interface()
+	if err := c.Client.Call(ctx, req, r, opts...); err != nil {
+		return nil, err
+	}
+	if options.CacheExpiry > 0 {
+		cache.Set(ctx, &req, r, options.CacheExpiry, options.CacheStale)
+	}
+	return r, nil
+}
+
+// revalidate refreshes a stale response. Concurrent refreshes of the
+// same request are coalesced and the caller's cancellation is ignored.
+func (c *cacheWrapper) revalidate(ctx context.Context, cache client.Cache, req client.Request, rsp interface{}, options client.CallOptions, opts []client.CallOption) {
+	md, _ := metadata.FromContext(ctx)
+	ctx = metadata.NewContext(context.Background(), md)
+
+	c.group.Do(ctx, &req, func(ctx context.Context) (interface{}, error) {
+		return c.call(ctx, cache, req, rsp, options, opts)
+	})
+}
+
 // CacheClient wraps requests with the cache wrapper
-func CacheClient(cacheFn func() *client.Cache, c client.Client) client.Client {
-	return &cacheWrapper{cacheFn, c}
+func CacheClient(cacheFn func() client.Cache, c client.Client) client.Client {
+	return &cacheWrapper{cacheFn, new(client.Group), c}
 }
 
 type staticClient struct {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/server"
+	"github.com/micro/go-micro/v2/util/validate/test"
 )
 
 func TestWrapper(t *testing.T) {
@@ -97,6 +100,45 @@ func (r testRequest) Endpoint() string {
 	return r.endpoint
 }
 
+type bodyRequest struct {
+	testRequest
+	body interface{}
+}
+
+func (r bodyRequest) Body() interface{} {
+	return r.body
+}
+
+func (r bodyRequest) Stream() bool {
+	return false
+}
+
+func TestValidateHandler(t *testing.T) {
+	var called bool
+	h := func(ctx context.Context, req server.Request, rsp interface{}) error {
+		called = true
+		return nil
+	}
+	handler := ValidateHandler()(h)
+
+	req := bodyRequest{testRequest{service: "foo"}, &test.Request{Name: "john", Email: "john@example.com"}}
+	err := handler(context.TODO(), req, nil)
+	if e := errors.FromError(err); e.Code != 400 || e.Id != "foo" {
+		t.Fatalf("Expected a bad request error got %v", err)
+	}
+	if called {
+		t.Fatal("Expected the handler not to be called")
+	}
+
+	req.body.(*test.Request).Address = &test.Address{City: "london"}
+	if err := handler(context.TODO(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("Expected the handler to be called")
+	}
+}
+
 func TestAuthHandler(t *testing.T) {
 	h := func(ctx context.Context, req server.Request, rsp interface{}) error {
 		return nil
@@ -392,7 +434,7 @@ func TestCacheWrapper(t *testing.T) {
 	t.Run("NilCache", func(t *testing.T) {
 		cli := new(testClient)
 
-		w := CacheClient(func() *client.Cache {
+		w := CacheClient(func() client.Cache {
 			return nil
 		}, cli)
 
@@ -410,7 +452,7 @@ func TestCacheWrapper(t *testing.T) {
 		cli := new(testClient)
 		cache := client.NewCache()
 
-		w := CacheClient(func() *client.Cache {
+		w := CacheClient(func() client.Cache {
 			return cache
 		}, cli)
 
@@ -429,7 +471,7 @@ func TestCacheWrapper(t *testing.T) {
 		cli := &testClient{callRsp: &testRsp{value: val}}
 		cache := client.NewCache()
 
-		w := CacheClient(func() *client.Cache {
+		w := CacheClient(func() client.Cache {
 			return cache
 		}, cli)
 
@@ -459,4 +501,113 @@ func TestCacheWrapper(t *testing.T) {
 			t.Errorf("Expected the client to be called 1 time, was actually called %v time(s)", cli.callCount)
 		}
 	})
+	t.Run("Coalesce", func(t *testing.T) {
+		cli := &slowClient{callRsp: &testRsp{value: "foo"}, delay: time.Millisecond * 50}
+		cache := client.NewCache()
+
+		w := CacheClient(func() client.Cache {
+			return cache
+		}, cli)
+
+		// concurrent identical requests should share a single call
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rsp := &testRsp{}
+				if err := w.Call(context.TODO(), req, rsp, client.WithCoalesce()); err != nil {
+					t.Errorf("Expected nil error, got %v", err)
+				}
+				if rsp.value != "foo" {
+					t.Errorf("Expected foo to be assigned to the value, got %v", rsp.value)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if n := atomic.LoadInt32(&cli.callCount); n != 1 {
+			t.Errorf("Expected the client to be called 1 time, was actually called %v time(s)", n)
+		}
+	})
+
+	t.Run("StaleWhileRevalidate", func(t *testing.T) {
+		cli := &slowClient{callRsp: &testRsp{value: "foo"}}
+		cache := &setCache{Cache: client.NewCache(), set: make(chan struct{}, 2)}
+
+		w := CacheClient(func() client.Cache {
+			return cache
+		}, cli)
+
+		opts := []client.CallOption{client.WithCache(time.Millisecond * 10), client.WithStaleCache(time.Minute)}
+
+		rsp := &testRsp{}
+		w.Call(context.TODO(), req, rsp, opts...)
+		<-cache.set
+		time.Sleep(time.Millisecond * 20)
+
+		// the expired response is served and refreshed in the background
+		cli.setRsp(&testRsp{value: "bar"})
+		rsp = &testRsp{}
+		w.Call(context.TODO(), req, rsp, opts...)
+		if rsp.value != "foo" {
+			t.Errorf("Expected the stale value foo, got %v", rsp.value)
+		}
+
+		// wait for the refresh to be cached
+		select {
+		case <-cache.set:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the stale response to be refreshed")
+		}
+
+		rsp = &testRsp{}
+		w.Call(context.TODO(), req, rsp, opts...)
+		if rsp.value != "bar" {
+			t.Errorf("Expected the refreshed value bar, got %v", rsp.value)
+		}
+
+		if n := atomic.LoadInt32(&cli.callCount); n != 2 {
+			t.Errorf("Expected the client to be called 2 times, was actually called %v time(s)", n)
+		}
+	})
+}
+
+// setCache signals each time a response is cached
+type setCache struct {
+	client.Cache
+	set chan struct{}
+}
+
+func (c *setCache) Set(ctx context.Context, req *client.Request, rsp interface{}, expiry, stale time.Duration) error {
+	err := c.Cache.Set(ctx, req, rsp, expiry, stale)
+	c.set <- struct{}{}
+	return err
+}
+
+type slowClient struct {
+	callCount int32
+	delay     time.Duration
+
+	sync.Mutex
+	callRsp interface{}
+	client.Client
+}
+
+func (c *slowClient) setRsp(rsp interface{}) {
+	c.Lock()
+	c.callRsp = rsp
+	c.Unlock()
+}
+
+func (c *slowClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	atomic.AddInt32(&c.callCount, 1)
+	time.Sleep(c.delay)
+
+	c.Lock()
+	defer c.Unlock()
+
+	val := reflect.ValueOf(rsp).Elem()
+	val.Set(reflect.ValueOf(c.callRsp).Elem())
+	return nil
 }